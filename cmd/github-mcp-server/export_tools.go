@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var exportToolsCmd = &cobra.Command{
+	Use:   "export-tools",
+	Short: "Export the tool catalog as JSON",
+	Long:  `Export every tool the server can provide as JSON: its name, toolset, title, description after translation overrides, read-only annotation, whether it is in the default toolsets, and its input schema. No token is needed.`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return exportTools(viper.GetString("export-tools-output"))
+	},
+}
+
+func init() {
+	exportToolsCmd.Flags().StringP("output", "o", "", "Path to write the catalog to (defaults to standard output)")
+	_ = viper.BindPFlag("export-tools-output", exportToolsCmd.Flags().Lookup("output"))
+
+	rootCmd.AddCommand(exportToolsCmd)
+}
+
+// toolCatalog is the document written by export-tools.
+type toolCatalog struct {
+	Version string               `json:"version"`
+	Tools   []github.CatalogTool `json:"tools"`
+}
+
+func exportTools(outputPath string) error {
+	t, _ := translations.TranslationHelperWithOverrides(viper.GetStringMapString("descriptions"))
+
+	data, err := json.MarshalIndent(toolCatalog{
+		Version: version,
+		Tools:   github.ToolCatalog(t),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool catalog: %w", err)
+	}
+	data = append(data, '\n')
+
+	if outputPath == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(outputPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write tool catalog: %w", err)
+	}
+	return nil
+}
@@ -7,8 +7,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/github/github-mcp-server/internal/config"
 	"github.com/github/github-mcp-server/internal/ghmcp"
 	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/netconfig"
+	"github.com/github/github-mcp-server/pkg/repopolicy"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -25,6 +28,9 @@ var (
 		Short:   "GitHub MCP Server",
 		Long:    `A GitHub MCP server that handles various tools and resources.`,
 		Version: fmt.Sprintf("Version: %s\nCommit: %s\nBuild Date: %s", version, commit, date),
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return loadConfigFile()
+		},
 	}
 
 	stdioCmd = &cobra.Command{
@@ -32,52 +38,383 @@ var (
 		Short: "Start stdio server",
 		Long:  `Start a server that communicates via standard input/output streams using JSON-RPC messages.`,
 		RunE: func(_ *cobra.Command, _ []string) error {
+			// Without a token or App, the server falls back to the token stored by the login command
 			token := viper.GetString("personal_access_token")
-			if token == "" {
-				return errors.New("GITHUB_PERSONAL_ACCESS_TOKEN not set")
-			}
+			appID := viper.GetString("app-id")
 
-			// If you're wondering why we're not using viper.GetStringSlice("toolsets"),
-			// it's because viper doesn't handle comma-separated values correctly for env
-			// vars when using GetStringSlice.
-			// https://github.com/spf13/viper/issues/380
-			var enabledToolsets []string
-			if err := viper.UnmarshalKey("toolsets", &enabledToolsets); err != nil {
-				return fmt.Errorf("failed to unmarshal toolsets: %w", err)
+			// The auth method of the config file picks the credentials, ignoring any others that are set
+			switch method := viper.GetString("auth-method"); method {
+			case "":
+				if token != "" && appID != "" {
+					return errors.New("GITHUB_PERSONAL_ACCESS_TOKEN and a GitHub App ID cannot both be set")
+				}
+			case config.AuthMethodToken:
+				if token == "" {
+					return errors.New("auth method \"token\" requires GITHUB_PERSONAL_ACCESS_TOKEN to be set")
+				}
+				appID = ""
+			case config.AuthMethodApp:
+				token = ""
+			case config.AuthMethodLogin:
+				token, appID = "", ""
+			default:
+				return fmt.Errorf("unknown auth method %q", method)
 			}
 
-			// Parse tools (similar to toolsets)
-			var enabledTools []string
-			if err := viper.UnmarshalKey("tools", &enabledTools); err != nil {
-				return fmt.Errorf("failed to unmarshal tools: %w", err)
+			enabledToolsets, enabledTools, err := enabledToolsetsAndTools()
+			if err != nil {
+				return err
 			}
-
-			// If neither toolset config nor tools config is passed we enable the default toolset
-			if len(enabledToolsets) == 0 && len(enabledTools) == 0 {
-				enabledToolsets = []string{github.ToolsetMetadataDefault.ID}
+			excludedTools, err := excludedTools()
+			if err != nil {
+				return err
+			}
+			allowedRepos, deniedRepos, err := repoPatterns()
+			if err != nil {
+				return err
+			}
+			var confirmTools []string
+			if err := viper.UnmarshalKey("confirm-tools", &confirmTools); err != nil {
+				return fmt.Errorf("failed to unmarshal tools to confirm: %w", err)
 			}
 
 			ttl := viper.GetDuration("repo-access-cache-ttl")
 			stdioServerConfig := ghmcp.StdioServerConfig{
-				Version:              version,
-				Host:                 viper.GetString("host"),
-				Token:                token,
-				EnabledToolsets:      enabledToolsets,
-				EnabledTools:         enabledTools,
-				DynamicToolsets:      viper.GetBool("dynamic_toolsets"),
-				ReadOnly:             viper.GetBool("read-only"),
-				ExportTranslations:   viper.GetBool("export-translations"),
-				EnableCommandLogging: viper.GetBool("enable-command-logging"),
-				LogFilePath:          viper.GetString("log-file"),
-				ContentWindowSize:    viper.GetInt("content-window-size"),
-				LockdownMode:         viper.GetBool("lockdown-mode"),
-				RepoAccessCacheTTL:   &ttl,
+				Version:               version,
+				Host:                  viper.GetString("host"),
+				Token:                 token,
+				AppID:                 appID,
+				AppPrivateKeyFile:     viper.GetString("app-private-key-file"),
+				AppInstallationID:     viper.GetInt64("app-installation-id"),
+				AppInstallationOwner:  viper.GetString("app-installation-owner"),
+				CredentialsFile:       viper.GetString("credentials-file"),
+				EnabledToolsets:       enabledToolsets,
+				EnabledTools:          enabledTools,
+				ExcludedTools:         excludedTools,
+				DynamicToolsets:       viper.GetBool("dynamic_toolsets"),
+				ReadOnly:              viper.GetBool("read-only"),
+				DisableScopeFiltering: viper.GetBool("disable-scope-filtering"),
+				ExportTranslations:    viper.GetBool("export-translations"),
+				TranslationOverrides:  viper.GetStringMapString("descriptions"),
+				EnableCommandLogging:  viper.GetBool("enable-command-logging"),
+				LogFilePath:           viper.GetString("log-file"),
+				LogFormat:             viper.GetString("log-format"),
+				LogLevel:              viper.GetString("log-level"),
+				ContentWindowSize:     viper.GetInt("content-window-size"),
+				LockdownMode:          viper.GetBool("lockdown-mode"),
+				RepoAccessCacheTTL:    &ttl,
+				ResponseCacheSize:     viper.GetInt64("response-cache-size") << 20,
+				ResponseCacheDir:      viper.GetString("response-cache-dir"),
+				RetryMutations:        viper.GetBool("retry-mutations"),
+				OTLPEndpoint:          viper.GetString("otlp-endpoint"),
+				MetricsAddress:        viper.GetString("metrics-address"),
+				Endpoints:             apiEndpoints(),
+				Network:               networkConfig(),
+				RecordDir:             viper.GetString("record"),
+				ReplayDir:             viper.GetString("replay"),
+				AuditLogPath:          viper.GetString("audit-log"),
+				AuditLogMaxSize:       viper.GetInt64("audit-log-max-size") << 20,
+				AuditLogMaxBackups:    viper.GetInt("audit-log-max-backups"),
+				ErrorMeta:             viper.GetBool("error-meta"),
+				AllowedRepos:          allowedRepos,
+				DeniedRepos:           deniedRepos,
+				ConfirmTools:          confirmTools,
+				DryRun:                viper.GetBool("dry-run"),
 			}
 			return ghmcp.RunStdioServer(stdioServerConfig)
 		},
 	}
+
+	httpCmd = &cobra.Command{
+		Use:   "http",
+		Short: "Start Streamable HTTP server",
+		Long:  `Start a server that communicates via the MCP Streamable HTTP transport. Each request must carry a GitHub token in its Authorization header, which is used for the GitHub API calls made on its behalf.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			enabledToolsets, enabledTools, err := enabledToolsetsAndTools()
+			if err != nil {
+				return err
+			}
+			excludedTools, err := excludedTools()
+			if err != nil {
+				return err
+			}
+			allowedRepos, deniedRepos, err := repoPatterns()
+			if err != nil {
+				return err
+			}
+			var confirmTools []string
+			if err := viper.UnmarshalKey("confirm-tools", &confirmTools); err != nil {
+				return fmt.Errorf("failed to unmarshal tools to confirm: %w", err)
+			}
+
+			ttl := viper.GetDuration("repo-access-cache-ttl")
+			httpServerConfig := ghmcp.HTTPServerConfig{
+				Version:               version,
+				Host:                  viper.GetString("host"),
+				ListenAddress:         viper.GetString("listen-address"),
+				EnabledToolsets:       enabledToolsets,
+				EnabledTools:          enabledTools,
+				ExcludedTools:         excludedTools,
+				DynamicToolsets:       viper.GetBool("dynamic_toolsets"),
+				ReadOnly:              viper.GetBool("read-only"),
+				DisableScopeFiltering: viper.GetBool("disable-scope-filtering"),
+				ExportTranslations:    viper.GetBool("export-translations"),
+				TranslationOverrides:  viper.GetStringMapString("descriptions"),
+				LogFilePath:           viper.GetString("log-file"),
+				LogFormat:             viper.GetString("log-format"),
+				LogLevel:              viper.GetString("log-level"),
+				ContentWindowSize:     viper.GetInt("content-window-size"),
+				LockdownMode:          viper.GetBool("lockdown-mode"),
+				RepoAccessCacheTTL:    &ttl,
+				ResponseCacheSize:     viper.GetInt64("response-cache-size") << 20,
+				ResponseCacheDir:      viper.GetString("response-cache-dir"),
+				RetryMutations:        viper.GetBool("retry-mutations"),
+				OTLPEndpoint:          viper.GetString("otlp-endpoint"),
+				MetricsAddress:        viper.GetString("metrics-address"),
+				Endpoints:             apiEndpoints(),
+				Network:               networkConfig(),
+				RecordDir:             viper.GetString("record"),
+				ReplayDir:             viper.GetString("replay"),
+				AuditLogPath:          viper.GetString("audit-log"),
+				AuditLogMaxSize:       viper.GetInt64("audit-log-max-size") << 20,
+				AuditLogMaxBackups:    viper.GetInt("audit-log-max-backups"),
+				ErrorMeta:             viper.GetBool("error-meta"),
+				AllowedRepos:          allowedRepos,
+				DeniedRepos:           deniedRepos,
+				ConfirmTools:          confirmTools,
+				DryRun:                viper.GetBool("dry-run"),
+			}
+			return ghmcp.RunHTTPServer(httpServerConfig)
+		},
+	}
+
+	loginCmd = &cobra.Command{
+		Use:   "login",
+		Short: "Log in to GitHub",
+		Long:  `Log in to the GitHub host with the OAuth device flow and store the token, which the stdio server uses when GITHUB_PERSONAL_ACCESS_TOKEN is not set.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			clientID := viper.GetString("oauth-client-id")
+			if clientID == "" {
+				return errors.New("GITHUB_OAUTH_CLIENT_ID not set")
+			}
+
+			var scopes []string
+			if err := viper.UnmarshalKey("scopes", &scopes); err != nil {
+				return fmt.Errorf("failed to unmarshal scopes: %w", err)
+			}
+
+			return ghmcp.RunLogin(ghmcp.LoginConfig{
+				Version:         version,
+				Host:            viper.GetString("host"),
+				OAuthClientID:   clientID,
+				Scopes:          scopes,
+				CredentialsFile: viper.GetString("credentials-file"),
+				Endpoints:       apiEndpoints(),
+				Network:         networkConfig(),
+			})
+		},
+	}
+
+	logoutCmd = &cobra.Command{
+		Use:   "logout",
+		Short: "Log out of GitHub",
+		Long:  `Remove the token stored by the login command for the GitHub host.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return ghmcp.RunLogout(credentialsConfig())
+		},
+	}
+
+	statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show the login status",
+		Long:  `Show which user the token stored by the login command belongs to, and check that it is still valid.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return ghmcp.RunStatus(credentialsConfig())
+		},
+	}
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the configuration",
+		Long:  `Check the token against the GitHub host and the configured toolsets and tools, and report the token's scopes, SSO authorizations and rate limit budget along with the resolved API URLs. Exits with a non-zero status if any check fails.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			enabledToolsets, enabledTools, err := enabledToolsetsAndTools()
+			if err != nil {
+				return err
+			}
+
+			token := viper.GetString("personal_access_token")
+			if viper.GetString("auth-method") == config.AuthMethodLogin {
+				token = ""
+			}
+
+			// The report already tells what failed, so don't repeat the usage after it
+			cmd.SilenceUsage = true
+			return ghmcp.RunDoctor(ghmcp.DoctorConfig{
+				Version:         version,
+				Host:            viper.GetString("host"),
+				Token:           token,
+				CredentialsFile: viper.GetString("credentials-file"),
+				EnabledToolsets: enabledToolsets,
+				EnabledTools:    enabledTools,
+				DynamicToolsets: viper.GetBool("dynamic_toolsets"),
+				ReadOnly:        viper.GetBool("read-only"),
+				Endpoints:       apiEndpoints(),
+				Network:         networkConfig(),
+			})
+		},
+	}
 )
 
+func credentialsConfig() ghmcp.CredentialsConfig {
+	return ghmcp.CredentialsConfig{
+		Version:         version,
+		Host:            viper.GetString("host"),
+		CredentialsFile: viper.GetString("credentials-file"),
+		Endpoints:       apiEndpoints(),
+		Network:         networkConfig(),
+	}
+}
+
+func apiEndpoints() ghmcp.APIEndpoints {
+	return ghmcp.APIEndpoints{
+		RESTURL:    viper.GetString("rest-url"),
+		GraphQLURL: viper.GetString("graphql-url"),
+		UploadURL:  viper.GetString("upload-url"),
+		RawURL:     viper.GetString("raw-url"),
+	}
+}
+
+func networkConfig() netconfig.Config {
+	return netconfig.Config{
+		CACertFile:     viper.GetString("ca-cert"),
+		ProxyURL:       viper.GetString("proxy"),
+		NoProxy:        viper.GetString("no-proxy"),
+		ClientCertFile: viper.GetString("client-cert"),
+		ClientKeyFile:  viper.GetString("client-key"),
+	}
+}
+
+// enabledToolsetsAndTools reads the configured toolsets and tools, falling back to the
+// default toolset when neither is given.
+func enabledToolsetsAndTools() ([]string, []string, error) {
+	// If you're wondering why we're not using viper.GetStringSlice("toolsets"),
+	// it's because viper doesn't handle comma-separated values correctly for env
+	// vars when using GetStringSlice.
+	// https://github.com/spf13/viper/issues/380
+	var enabledToolsets []string
+	if err := viper.UnmarshalKey("toolsets", &enabledToolsets); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal toolsets: %w", err)
+	}
+
+	// Parse tools (similar to toolsets)
+	var enabledTools []string
+	if err := viper.UnmarshalKey("tools", &enabledTools); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal tools: %w", err)
+	}
+
+	// If neither toolset config nor tools config is passed we enable the default toolset
+	if len(enabledToolsets) == 0 && len(enabledTools) == 0 {
+		enabledToolsets = []string{github.ToolsetMetadataDefault.ID}
+	}
+
+	return enabledToolsets, enabledTools, nil
+}
+
+// excludedTools reads the tools that are never registered.
+func excludedTools() ([]string, error) {
+	var excluded []string
+	if err := viper.UnmarshalKey("exclude-tools", &excluded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal excluded tools: %w", err)
+	}
+	return excluded, nil
+}
+
+// repoPatterns reads the owner/repo patterns of the repository policy.
+func repoPatterns() ([]string, []string, error) {
+	var allow, deny []string
+	if err := viper.UnmarshalKey("allow-repos", &allow); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal allowed repositories: %w", err)
+	}
+	if err := viper.UnmarshalKey("deny-repos", &deny); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal denied repositories: %w", err)
+	}
+	return allow, deny, nil
+}
+
+// loadConfigFile reads the config file given with --config, if any. Its settings rank below flags
+// and environment variables, so that they can still be overridden for a single run.
+func loadConfigFile() error {
+	path := viper.GetString("config")
+	if path == "" {
+		return nil
+	}
+	f, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	return viper.MergeConfigMap(configFileSettings(f))
+}
+
+// configFileSettings maps the config file to the viper keys of the corresponding flags. Fields that
+// are not set are left out, so that they keep the flag defaults.
+func configFileSettings(f *config.File) map[string]any {
+	settings := map[string]any{}
+	setString := func(key string, value *string) {
+		if value != nil {
+			settings[key] = *value
+		}
+	}
+	setBool := func(key string, value *bool) {
+		if value != nil {
+			settings[key] = *value
+		}
+	}
+	setList := func(key string, value []string) {
+		if value != nil {
+			settings[key] = value
+		}
+	}
+
+	setString("host", f.Host)
+	setList("toolsets", f.Toolsets)
+	setList("tools", f.Tools)
+	setList("exclude-tools", f.ExcludeTools)
+	setList("confirm-tools", f.ConfirmTools)
+	if r := f.Repositories; r != nil {
+		setList("allow-repos", r.Allow)
+		setList("deny-repos", r.Deny)
+	}
+	setBool("dynamic_toolsets", f.DynamicToolsets)
+	setBool("read-only", f.ReadOnly)
+	setBool("dry-run", f.DryRun)
+	setBool("lockdown-mode", f.LockdownMode)
+	if f.ContentWindowSize != nil {
+		settings["content-window-size"] = *f.ContentWindowSize
+	}
+	if f.Descriptions != nil {
+		settings["descriptions"] = f.Descriptions
+	}
+	if f.Auth != nil {
+		settings["auth-method"] = f.Auth.Method
+		setString("credentials-file", f.Auth.CredentialsFile)
+		if app := f.Auth.App; app != nil {
+			settings["app-id"] = app.ID
+			settings["app-private-key-file"] = app.PrivateKeyFile
+			if app.InstallationID != nil {
+				settings["app-installation-id"] = *app.InstallationID
+			}
+			setString("app-installation-owner", app.InstallationOwner)
+		}
+	}
+	if l := f.Logging; l != nil {
+		setString("log-file", l.File)
+		setString("log-format", l.Format)
+		setString("log-level", l.Level)
+		setBool("enable-command-logging", l.CommandLogging)
+	}
+	return settings
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.SetGlobalNormalizationFunc(wordSepNormalizeFunc)
@@ -87,31 +424,116 @@ func init() {
 	// Add global flags that will be shared by all commands
 	rootCmd.PersistentFlags().StringSlice("toolsets", nil, github.GenerateToolsetsHelp())
 	rootCmd.PersistentFlags().StringSlice("tools", nil, "Comma-separated list of specific tools to enable")
+	rootCmd.PersistentFlags().StringSlice("confirm-tools", nil, "Comma-separated list of tools whose calls the user must approve, by name, glob pattern or tool:method (e.g. label_write:delete)")
+	rootCmd.PersistentFlags().StringSlice("exclude-tools", nil, "Comma-separated list of tools never to register, by name or glob pattern (e.g. delete_*)")
 	rootCmd.PersistentFlags().Bool("dynamic-toolsets", false, "Enable dynamic toolsets")
+	rootCmd.PersistentFlags().StringSlice("allow-repos", nil, "Comma-separated owner/repo glob patterns of the only repositories tools can access, each optionally ending with "+repopolicy.ReadOnlySuffix)
+	rootCmd.PersistentFlags().StringSlice("deny-repos", nil, "Comma-separated owner/repo glob patterns of repositories tools can't access")
 	rootCmd.PersistentFlags().Bool("read-only", false, "Restrict the server to read-only operations")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Make every call to a write tool return the GitHub API operations it would perform, without changing anything")
+	rootCmd.PersistentFlags().Bool("disable-scope-filtering", false, "Keep tools that the token lacks the scopes or permissions for")
 	rootCmd.PersistentFlags().String("log-file", "", "Path to log file")
+	rootCmd.PersistentFlags().String("log-format", "text", "Format of the logs: text or json")
+	rootCmd.PersistentFlags().String("log-level", "", "Minimum level of the logs: debug, info, warn or error (default debug in the log file, info on stderr)")
 	rootCmd.PersistentFlags().Bool("enable-command-logging", false, "When enabled, the server will log all command requests and responses to the log file")
 	rootCmd.PersistentFlags().Bool("export-translations", false, "Save translations to a JSON file")
 	rootCmd.PersistentFlags().String("gh-host", "", "Specify the GitHub hostname (for GitHub Enterprise etc.)")
 	rootCmd.PersistentFlags().Int("content-window-size", 5000, "Specify the content window size")
 	rootCmd.PersistentFlags().Bool("lockdown-mode", false, "Enable lockdown mode")
 	rootCmd.PersistentFlags().Duration("repo-access-cache-ttl", 5*time.Minute, "Override the repo access cache TTL (e.g. 1m, 0s to disable)")
+	rootCmd.PersistentFlags().Int64("response-cache-size", 64, "Memory for caching GitHub API responses in MiB (0 to disable)")
+	rootCmd.PersistentFlags().String("response-cache-dir", "", "Directory to also cache GitHub API responses in, kept across restarts")
+	rootCmd.PersistentFlags().Bool("retry-mutations", false, "Also retry requests that change data after transient GitHub API failures")
+	rootCmd.PersistentFlags().String("otlp-endpoint", "", "URL of an OpenTelemetry collector to export traces to over OTLP/HTTP (e.g. http://localhost:4318)")
+	rootCmd.PersistentFlags().String("metrics-address", "", "Address to serve Prometheus metrics on at /metrics (e.g. localhost:9090)")
+	rootCmd.PersistentFlags().String("rest-url", "", "Base URL of the REST API, overriding the one derived from --gh-host")
+	rootCmd.PersistentFlags().String("graphql-url", "", "URL of the GraphQL API, overriding the one derived from --gh-host")
+	rootCmd.PersistentFlags().String("upload-url", "", "Base URL for uploads, overriding the one derived from --gh-host")
+	rootCmd.PersistentFlags().String("raw-url", "", "Base URL of raw file contents, overriding the one derived from --gh-host")
+	rootCmd.PersistentFlags().String("ca-cert", "", "Path to a PEM file of CA certificates to trust in addition to the system's")
+	rootCmd.PersistentFlags().String("proxy", "", "URL of the proxy to connect to GitHub through (defaults to HTTPS_PROXY)")
+	rootCmd.PersistentFlags().String("no-proxy", "", "Comma-separated hosts to connect to directly when --proxy is set")
+	rootCmd.PersistentFlags().String("client-cert", "", "Path to a PEM client certificate for servers and proxies that require one")
+	rootCmd.PersistentFlags().String("client-key", "", "Path to the PEM private key of the client certificate")
+	rootCmd.PersistentFlags().String("record", "", "Directory to record the GitHub API traffic to, with credentials scrubbed")
+	rootCmd.PersistentFlags().String("replay", "", "Directory of recorded GitHub API traffic to answer requests from, instead of GitHub")
+	rootCmd.PersistentFlags().String("audit-log", "", "Path to a file to record every call to a write tool in, as JSON lines")
+	rootCmd.PersistentFlags().Int64("audit-log-max-size", 100, "Size in MiB at which the audit log is rotated (0 never rotates it)")
+	rootCmd.PersistentFlags().Int("audit-log-max-backups", 5, "Number of rotated audit logs to keep")
+	rootCmd.PersistentFlags().Bool("error-meta", false, "Attach the details of GitHub API errors to the _meta of tool results")
+	rootCmd.PersistentFlags().String("credentials-file", "", "Path to the credential file used by login (defaults to the user config directory)")
+	rootCmd.PersistentFlags().String("config", "", "Path to a YAML or JSON config file; flags and environment variables override its settings")
 
 	// Bind flag to viper
 	_ = viper.BindPFlag("toolsets", rootCmd.PersistentFlags().Lookup("toolsets"))
 	_ = viper.BindPFlag("tools", rootCmd.PersistentFlags().Lookup("tools"))
+	_ = viper.BindPFlag("exclude-tools", rootCmd.PersistentFlags().Lookup("exclude-tools"))
+	_ = viper.BindPFlag("confirm-tools", rootCmd.PersistentFlags().Lookup("confirm-tools"))
 	_ = viper.BindPFlag("dynamic_toolsets", rootCmd.PersistentFlags().Lookup("dynamic-toolsets"))
+	_ = viper.BindPFlag("allow-repos", rootCmd.PersistentFlags().Lookup("allow-repos"))
+	_ = viper.BindPFlag("deny-repos", rootCmd.PersistentFlags().Lookup("deny-repos"))
 	_ = viper.BindPFlag("read-only", rootCmd.PersistentFlags().Lookup("read-only"))
+	_ = viper.BindPFlag("dry-run", rootCmd.PersistentFlags().Lookup("dry-run"))
+	_ = viper.BindPFlag("disable-scope-filtering", rootCmd.PersistentFlags().Lookup("disable-scope-filtering"))
 	_ = viper.BindPFlag("log-file", rootCmd.PersistentFlags().Lookup("log-file"))
+	_ = viper.BindPFlag("log-format", rootCmd.PersistentFlags().Lookup("log-format"))
+	_ = viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
 	_ = viper.BindPFlag("enable-command-logging", rootCmd.PersistentFlags().Lookup("enable-command-logging"))
 	_ = viper.BindPFlag("export-translations", rootCmd.PersistentFlags().Lookup("export-translations"))
 	_ = viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("gh-host"))
 	_ = viper.BindPFlag("content-window-size", rootCmd.PersistentFlags().Lookup("content-window-size"))
 	_ = viper.BindPFlag("lockdown-mode", rootCmd.PersistentFlags().Lookup("lockdown-mode"))
 	_ = viper.BindPFlag("repo-access-cache-ttl", rootCmd.PersistentFlags().Lookup("repo-access-cache-ttl"))
+	_ = viper.BindPFlag("response-cache-size", rootCmd.PersistentFlags().Lookup("response-cache-size"))
+	_ = viper.BindPFlag("response-cache-dir", rootCmd.PersistentFlags().Lookup("response-cache-dir"))
+	_ = viper.BindPFlag("retry-mutations", rootCmd.PersistentFlags().Lookup("retry-mutations"))
+	_ = viper.BindPFlag("otlp-endpoint", rootCmd.PersistentFlags().Lookup("otlp-endpoint"))
+	_ = viper.BindPFlag("metrics-address", rootCmd.PersistentFlags().Lookup("metrics-address"))
+	_ = viper.BindPFlag("rest-url", rootCmd.PersistentFlags().Lookup("rest-url"))
+	_ = viper.BindPFlag("graphql-url", rootCmd.PersistentFlags().Lookup("graphql-url"))
+	_ = viper.BindPFlag("upload-url", rootCmd.PersistentFlags().Lookup("upload-url"))
+	_ = viper.BindPFlag("raw-url", rootCmd.PersistentFlags().Lookup("raw-url"))
+	_ = viper.BindPFlag("ca-cert", rootCmd.PersistentFlags().Lookup("ca-cert"))
+	_ = viper.BindPFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
+	_ = viper.BindPFlag("no-proxy", rootCmd.PersistentFlags().Lookup("no-proxy"))
+	_ = viper.BindPFlag("client-cert", rootCmd.PersistentFlags().Lookup("client-cert"))
+	_ = viper.BindPFlag("client-key", rootCmd.PersistentFlags().Lookup("client-key"))
+	_ = viper.BindPFlag("record", rootCmd.PersistentFlags().Lookup("record"))
+	_ = viper.BindPFlag("replay", rootCmd.PersistentFlags().Lookup("replay"))
+	_ = viper.BindPFlag("audit-log", rootCmd.PersistentFlags().Lookup("audit-log"))
+	_ = viper.BindPFlag("audit-log-max-size", rootCmd.PersistentFlags().Lookup("audit-log-max-size"))
+	_ = viper.BindPFlag("audit-log-max-backups", rootCmd.PersistentFlags().Lookup("audit-log-max-backups"))
+	_ = viper.BindPFlag("error-meta", rootCmd.PersistentFlags().Lookup("error-meta"))
+	_ = viper.BindPFlag("credentials-file", rootCmd.PersistentFlags().Lookup("credentials-file"))
+	_ = viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
+
+	// Add stdio-specific flags
+	stdioCmd.Flags().String("app-id", "", "Authenticate as a GitHub App with this ID instead of a personal access token")
+	stdioCmd.Flags().String("app-private-key-file", "", "Path to the PEM encoded private key of the GitHub App")
+	stdioCmd.Flags().Int64("app-installation-id", 0, "ID of the GitHub App installation to authenticate as")
+	stdioCmd.Flags().String("app-installation-owner", "", "User or organization whose GitHub App installation to use when no installation ID is given")
+	_ = viper.BindPFlag("app-id", stdioCmd.Flags().Lookup("app-id"))
+	_ = viper.BindPFlag("app-private-key-file", stdioCmd.Flags().Lookup("app-private-key-file"))
+	_ = viper.BindPFlag("app-installation-id", stdioCmd.Flags().Lookup("app-installation-id"))
+	_ = viper.BindPFlag("app-installation-owner", stdioCmd.Flags().Lookup("app-installation-owner"))
+
+	// Add http-specific flags
+	httpCmd.Flags().String("listen-address", ":8082", "Address for the HTTP server to listen on")
+	_ = viper.BindPFlag("listen-address", httpCmd.Flags().Lookup("listen-address"))
+
+	// Add login-specific flags
+	loginCmd.Flags().String("oauth-client-id", "", "Client ID of the OAuth App to log in with (device flow must be enabled)")
+	loginCmd.Flags().StringSlice("scopes", []string{"repo", "read:org", "read:packages"}, "OAuth scopes to request")
+	_ = viper.BindPFlag("oauth-client-id", loginCmd.Flags().Lookup("oauth-client-id"))
+	_ = viper.BindPFlag("scopes", loginCmd.Flags().Lookup("scopes"))
 
 	// Add subcommands
 	rootCmd.AddCommand(stdioCmd)
+	rootCmd.AddCommand(httpCmd)
+	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(doctorCmd)
 }
 
 func initConfig() {
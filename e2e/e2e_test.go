@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
@@ -16,6 +17,7 @@ import (
 	"time"
 
 	"github.com/github/github-mcp-server/internal/ghmcp"
+	"github.com/github/github-mcp-server/internal/githubfake"
 	"github.com/github/github-mcp-server/pkg/github"
 	"github.com/github/github-mcp-server/pkg/translations"
 	gogithub "github.com/google/go-github/v79/github"
@@ -33,11 +35,29 @@ var (
 
 	buildOnce  sync.Once
 	buildError error
+
+	startFakeOnce sync.Once
+	fake          *githubfake.Server
 )
 
+// getFake starts a fake GitHub for all tests to share when GITHUB_MCP_SERVER_E2E_FAKE is set, and
+// returns nil otherwise
+func getFake() *githubfake.Server {
+	startFakeOnce.Do(func() {
+		if os.Getenv("GITHUB_MCP_SERVER_E2E_FAKE") != "" {
+			fake = githubfake.New()
+		}
+	})
+	return fake
+}
+
 // getE2EToken ensures the environment variable is checked only once and returns the token
 func getE2EToken(t *testing.T) string {
 	getTokenOnce.Do(func() {
+		if fake := getFake(); fake != nil {
+			token = fake.Token()
+			return
+		}
 		token = os.Getenv("GITHUB_MCP_SERVER_E2E_TOKEN")
 		if token == "" {
 			t.Fatalf("GITHUB_MCP_SERVER_E2E_TOKEN environment variable is not set")
@@ -49,11 +69,20 @@ func getE2EToken(t *testing.T) string {
 // getE2EHost ensures the environment variable is checked only once and returns the host
 func getE2EHost() string {
 	getHostOnce.Do(func() {
+		if fake := getFake(); fake != nil {
+			host = fake.URL()
+			return
+		}
 		host = os.Getenv("GITHUB_MCP_SERVER_E2E_HOST")
 	})
 	return host
 }
 
+// supportsCopilot reports whether the host supports Copilot, as github.com and the fake do
+func supportsCopilot() bool {
+	return getFake() != nil || getE2EHost() == "" || getE2EHost() == "https://github.com"
+}
+
 func getRESTClient(t *testing.T) *gogithub.Client {
 	// Get token and ensure Docker image is built
 	token := getE2EToken(t)
@@ -124,8 +153,10 @@ func setupMCPClient(t *testing.T, options ...clientOption) *mcp.ClientSession {
 
 	// By default, we run the tests including the Docker image, but with DEBUG
 	// enabled, we run the server in-process, allowing for easier debugging.
+	// Against the fake, the server always runs in-process, as the container
+	// can't reach it.
 	var session *mcp.ClientSession
-	if os.Getenv("GITHUB_MCP_SERVER_E2E_DEBUG") == "" {
+	if os.Getenv("GITHUB_MCP_SERVER_E2E_DEBUG") == "" && getFake() == nil {
 		ensureDockerImageBuilt(t)
 
 		// Prepare Docker arguments
@@ -181,11 +212,23 @@ func setupMCPClient(t *testing.T, options ...clientOption) *mcp.ClientSession {
 			enabledToolsets = github.GetDefaultToolsetIDs()
 		}
 
+		var endpoints ghmcp.APIEndpoints
+		if fake := getFake(); fake != nil {
+			endpoints = ghmcp.APIEndpoints{
+				RESTURL:    fake.RESTURL(),
+				GraphQLURL: fake.GraphQLURL(),
+				UploadURL:  fake.UploadURL(),
+				RawURL:     fake.RawURL(),
+			}
+		}
+
 		ghServer, err := ghmcp.NewMCPServer(ghmcp.MCPServerConfig{
 			Token:           token,
 			EnabledToolsets: enabledToolsets,
 			Host:            getE2EHost(),
+			Endpoints:       endpoints,
 			Translator:      translations.NullTranslationHelper,
+			Logger:          slog.New(slog.DiscardHandler),
 		})
 		require.NoError(t, err, "expected to construct MCP server successfully")
 
@@ -802,7 +845,7 @@ func TestDirectoryDeletion(t *testing.T) {
 func TestRequestCopilotReview(t *testing.T) {
 	t.Parallel()
 
-	if getE2EHost() != "" && getE2EHost() != "https://github.com" {
+	if !supportsCopilot() {
 		t.Skip("Skipping test because the host does not support copilot reviews")
 	}
 
@@ -848,7 +891,7 @@ func TestRequestCopilotReview(t *testing.T) {
 	// Cleanup the repository after the test
 	t.Cleanup(func() {
 		// MCP Server doesn't support deletions, but we can use the GitHub Client
-		ghClient := gogithub.NewClient(nil).WithAuthToken(getE2EToken(t))
+		ghClient := getRESTClient(t)
 		t.Logf("Deleting repository %s/%s...", currentOwner, repoName)
 		_, err := ghClient.Repositories.Delete(context.Background(), currentOwner, repoName)
 		require.NoError(t, err, "expected to delete repository successfully")
@@ -934,7 +977,7 @@ func TestRequestCopilotReview(t *testing.T) {
 
 	// Finally, get requested reviews and see copilot is in there
 	// MCP Server doesn't support requesting reviews yet, but we can use the GitHub Client
-	ghClient := gogithub.NewClient(nil).WithAuthToken(getE2EToken(t))
+	ghClient := getRESTClient(t)
 	t.Logf("Getting reviews for pull request in %s/%s...", currentOwner, repoName)
 	reviewRequests, _, err := ghClient.PullRequests.ListReviewers(context.Background(), currentOwner, repoName, 1, nil)
 	require.NoError(t, err, "expected to get review requests successfully")
@@ -948,7 +991,7 @@ func TestRequestCopilotReview(t *testing.T) {
 func TestAssignCopilotToIssue(t *testing.T) {
 	t.Parallel()
 
-	if getE2EHost() != "" && getE2EHost() != "https://github.com" {
+	if !supportsCopilot() {
 		t.Skip("Skipping test because the host does not support copilot being assigned to issues")
 	}
 
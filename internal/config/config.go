@@ -0,0 +1,274 @@
+// Package config reads the server configuration file given with --config. The file is YAML, or JSON,
+// which YAML parsers also read. It is versioned, so that the format can change without silently
+// changing the meaning of existing files, and strict: unknown keys and invalid values are errors.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/confirmation"
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/repopolicy"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"go.yaml.in/yaml/v3"
+)
+
+// Version is the version of the file format this server reads.
+const Version = 1
+
+// Auth methods
+const (
+	// AuthMethodToken authenticates with GITHUB_PERSONAL_ACCESS_TOKEN
+	AuthMethodToken = "token"
+	// AuthMethodApp authenticates as a GitHub App installation
+	AuthMethodApp = "app"
+	// AuthMethodLogin authenticates with the token stored by the login command
+	AuthMethodLogin = "login"
+)
+
+// File is the configuration file. Fields that are not set keep the value of the corresponding flag or
+// environment variable, which also win over the fields that are set.
+type File struct {
+	// Version of the file format, which must be Version
+	Version int `yaml:"version"`
+
+	// Host is the GitHub host (e.g. https://ghes.example.com)
+	Host *string `yaml:"host"`
+
+	// Auth selects how the stdio server authenticates
+	Auth *Auth `yaml:"auth"`
+
+	// Toolsets to enable
+	Toolsets []string `yaml:"toolsets"`
+
+	// Tools to enable in addition to the toolsets
+	Tools []string `yaml:"tools"`
+
+	// ExcludeTools are names and glob patterns (e.g. delete_*) of tools that are never registered
+	ExcludeTools []string `yaml:"exclude_tools"`
+
+	// ConfirmTools are names, glob patterns and tool:method patterns (e.g. label_write:delete) of the tools
+	// whose calls the user must approve
+	ConfirmTools []string `yaml:"confirm_tools"`
+
+	// Repositories is the policy of the repositories tools can access
+	Repositories *Repositories `yaml:"repositories"`
+
+	// DynamicToolsets enables dynamic toolset discovery
+	DynamicToolsets *bool `yaml:"dynamic_toolsets"`
+
+	// ReadOnly restricts the server to read-only tools
+	ReadOnly *bool `yaml:"read_only"`
+
+	// DryRun makes every call to a write tool a dry run
+	DryRun *bool `yaml:"dry_run"`
+
+	// LockdownMode enables lockdown mode
+	LockdownMode *bool `yaml:"lockdown_mode"`
+
+	// ContentWindowSize is the content window size
+	ContentWindowSize *int `yaml:"content_window_size"`
+
+	// Logging configures the logs
+	Logging *Logging `yaml:"logging"`
+
+	// Descriptions override tool descriptions and other texts by translation key (e.g. TOOL_GET_ME_DESCRIPTION)
+	Descriptions map[string]string `yaml:"descriptions"`
+}
+
+type Auth struct {
+	// Method is one of AuthMethodToken, AuthMethodApp and AuthMethodLogin
+	Method string `yaml:"method"`
+
+	// App is the GitHub App to authenticate as, for AuthMethodApp
+	App *App `yaml:"app"`
+
+	// CredentialsFile is the credential store of the login command, for AuthMethodLogin
+	CredentialsFile *string `yaml:"credentials_file"`
+}
+
+type App struct {
+	// ID of the GitHub App
+	ID string `yaml:"id"`
+
+	// PrivateKeyFile is the path to the PEM encoded private key of the GitHub App
+	PrivateKeyFile string `yaml:"private_key_file"`
+
+	// InstallationID is the installation to authenticate as
+	InstallationID *int64 `yaml:"installation_id"`
+
+	// InstallationOwner is the account whose installation is used when InstallationID is not set
+	InstallationOwner *string `yaml:"installation_owner"`
+}
+
+type Repositories struct {
+	// Allow are the owner/repo glob patterns of the only repositories tools can access, each optionally
+	// ending with :read-only
+	Allow []string `yaml:"allow"`
+
+	// Deny are the owner/repo glob patterns of repositories tools can't access
+	Deny []string `yaml:"deny"`
+}
+
+type Logging struct {
+	// File is the path of the log file (stderr if not set)
+	File *string `yaml:"file"`
+
+	// Format is "text" or "json"
+	Format *string `yaml:"format"`
+
+	// Level is "debug", "info", "warn" or "error"
+	Level *string `yaml:"level"`
+
+	// CommandLogging logs all requests and responses
+	CommandLogging *bool `yaml:"command_logging"`
+}
+
+// Load reads and validates the configuration file at path.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- the path is given by the user on purpose
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse parses and validates a configuration file.
+func Parse(data []byte) (*File, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var f File
+	if err := decoder.Decode(&f); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("the file is empty")
+		}
+		return nil, decodeError(err)
+	}
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// unknownFieldPattern matches the errors of the YAML decoder about unknown keys, which name Go types.
+var unknownFieldPattern = regexp.MustCompile(`field (\S+) not found in type \S+`)
+
+// decodeError rewords the errors of the YAML decoder in terms of the file.
+func decodeError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+	messages := make([]string, 0, len(typeErr.Errors))
+	for _, message := range typeErr.Errors {
+		message = unknownFieldPattern.ReplaceAllString(message, `unknown key "$1"`)
+		message = strings.ReplaceAll(message, "!!", "")
+		messages = append(messages, message)
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+func (f *File) validate() error {
+	switch f.Version {
+	case 0:
+		return fmt.Errorf("version is required, set it to %d", Version)
+	case Version:
+	default:
+		return fmt.Errorf("unsupported version %d, this server reads version %d", f.Version, Version)
+	}
+
+	if _, invalid := github.CleanToolsets(f.Toolsets); len(invalid) > 0 {
+		return fmt.Errorf("toolsets: unknown toolsets %s", strings.Join(invalid, ", "))
+	}
+	if invalid := unknownTools(f.Tools); len(invalid) > 0 {
+		return fmt.Errorf("tools: unknown tools %s", strings.Join(invalid, ", "))
+	}
+	if err := toolsets.ValidateToolPatterns(f.ExcludeTools); err != nil {
+		return fmt.Errorf("exclude_tools: %w", err)
+	}
+	if _, err := confirmation.New(f.ConfirmTools); err != nil {
+		return fmt.Errorf("confirm_tools: %w", err)
+	}
+	if f.Repositories != nil {
+		if _, err := repopolicy.New(f.Repositories.Allow, f.Repositories.Deny); err != nil {
+			return fmt.Errorf("repositories: %w", err)
+		}
+	}
+	if f.ContentWindowSize != nil && *f.ContentWindowSize <= 0 {
+		return errors.New("content_window_size: must be positive")
+	}
+	if f.Auth != nil {
+		if err := f.Auth.validate(); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if f.Logging != nil {
+		if err := f.Logging.validate(); err != nil {
+			return fmt.Errorf("logging: %w", err)
+		}
+	}
+	return nil
+}
+
+// unknownTools returns the names that are not those of a tool in the catalog.
+func unknownTools(names []string) []string {
+	names = github.CleanTools(names)
+	if len(names) == 0 {
+		return nil
+	}
+	known := map[string]bool{}
+	for _, tool := range github.ToolCatalog(translations.NullTranslationHelper) {
+		known[tool.Name] = true
+	}
+	var unknown []string
+	for _, name := range names {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+func (a *Auth) validate() error {
+	switch a.Method {
+	case AuthMethodToken, AuthMethodLogin:
+		if a.App != nil {
+			return fmt.Errorf("app: only allowed with method %q", AuthMethodApp)
+		}
+	case AuthMethodApp:
+		if a.App == nil || a.App.ID == "" || a.App.PrivateKeyFile == "" {
+			return errors.New("app: id and private_key_file are required with method \"app\"")
+		}
+	case "":
+		return errors.New("method is required")
+	default:
+		return fmt.Errorf("method: must be %q, %q or %q, not %q", AuthMethodToken, AuthMethodApp, AuthMethodLogin, a.Method)
+	}
+	if a.CredentialsFile != nil && a.Method != AuthMethodLogin {
+		return fmt.Errorf("credentials_file: only allowed with method %q", AuthMethodLogin)
+	}
+	return nil
+}
+
+func (l *Logging) validate() error {
+	if l.Format != nil && !slices.Contains([]string{"text", "json"}, *l.Format) {
+		return fmt.Errorf("format: must be \"text\" or \"json\", not %q", *l.Format)
+	}
+	if l.Level != nil && !slices.Contains([]string{"debug", "info", "warn", "error"}, *l.Level) {
+		return fmt.Errorf("level: must be \"debug\", \"info\", \"warn\" or \"error\", not %q", *l.Level)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse(t *testing.T) {
+	f, err := Parse([]byte(`
+version: 1
+host: https://ghes.example.com
+auth:
+  method: app
+  app:
+    id: "12345"
+    private_key_file: /etc/github-mcp-server/app.pem
+    installation_id: 42
+toolsets: [repos, issues]
+tools: [get_me]
+exclude_tools: [delete_file, "*_workflow_run*"]
+confirm_tools: [merge_pull_request, "label_write:delete"]
+repositories:
+  allow: ["my-org/*", "my-org/prod:read-only"]
+  deny: [my-org/secret]
+read_only: true
+dry_run: true
+content_window_size: 8000
+logging:
+  format: json
+  level: warn
+descriptions:
+  TOOL_GET_ME_DESCRIPTION: Get the current user
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://ghes.example.com", *f.Host)
+	assert.Equal(t, AuthMethodApp, f.Auth.Method)
+	assert.Equal(t, "12345", f.Auth.App.ID)
+	assert.Equal(t, int64(42), *f.Auth.App.InstallationID)
+	assert.Nil(t, f.Auth.App.InstallationOwner)
+	assert.Equal(t, []string{"repos", "issues"}, f.Toolsets)
+	assert.Equal(t, []string{"get_me"}, f.Tools)
+	assert.Equal(t, []string{"delete_file", "*_workflow_run*"}, f.ExcludeTools)
+	assert.Equal(t, []string{"merge_pull_request", "label_write:delete"}, f.ConfirmTools)
+	assert.Equal(t, []string{"my-org/*", "my-org/prod:read-only"}, f.Repositories.Allow)
+	assert.Equal(t, []string{"my-org/secret"}, f.Repositories.Deny)
+	assert.True(t, *f.ReadOnly)
+	assert.True(t, *f.DryRun)
+	assert.Nil(t, f.LockdownMode, "fields that are not set stay nil")
+	assert.Equal(t, 8000, *f.ContentWindowSize)
+	assert.Equal(t, "json", *f.Logging.Format)
+	assert.Nil(t, f.Logging.File)
+	assert.Equal(t, map[string]string{"TOOL_GET_ME_DESCRIPTION": "Get the current user"}, f.Descriptions)
+}
+
+func Test_ParseJSON(t *testing.T) {
+	f, err := Parse([]byte(`{"version": 1, "toolsets": ["all"], "lockdown_mode": false, "auth": {"method": "login"}}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"all"}, f.Toolsets)
+	assert.False(t, *f.LockdownMode)
+	assert.Equal(t, AuthMethodLogin, f.Auth.Method)
+}
+
+func Test_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{name: "empty file", data: "", expectedError: "the file is empty"},
+		{name: "missing version", data: "read_only: true", expectedError: "version is required, set it to 1"},
+		{name: "unsupported version", data: "version: 2", expectedError: "unsupported version 2, this server reads version 1"},
+		{name: "unknown key", data: "version: 1\nreadonly: true", expectedError: `line 2: unknown key "readonly"`},
+		{name: "unknown nested key", data: "version: 1\nlogging:\n  formt: json", expectedError: `line 3: unknown key "formt"`},
+		{name: "unknown key in JSON", data: `{"version": 1, "tool_sets": []}`, expectedError: `line 1: unknown key "tool_sets"`},
+		{name: "wrong type", data: "version: 1\nread_only: maybe", expectedError: "line 2: cannot unmarshal str `maybe` into bool"},
+		{name: "unknown toolset", data: "version: 1\ntoolsets: [repos, repo]", expectedError: "toolsets: unknown toolsets repo"},
+		{name: "unknown tool", data: "version: 1\ntools: [get_me, get_issue, create_issues]", expectedError: "tools: unknown tools get_issue, create_issues"},
+		{name: "exclude tools pattern", data: "version: 1\nexclude_tools: ['delete_[']", expectedError: `exclude_tools: invalid tool pattern "delete_[": syntax error in pattern`},
+		{name: "confirmation pattern", data: "version: 1\nconfirm_tools: [':delete']", expectedError: `confirm_tools: invalid confirmation pattern ":delete": a tool is required`},
+		{name: "repository pattern", data: "version: 1\nrepositories: {deny: [my-org]}", expectedError: `repositories: invalid repository pattern "my-org": must be owner/repo`},
+		{name: "content window size", data: "version: 1\ncontent_window_size: 0", expectedError: "content_window_size: must be positive"},
+		{name: "missing auth method", data: "version: 1\nauth: {}", expectedError: "auth: method is required"},
+		{name: "unknown auth method", data: "version: 1\nauth: {method: oauth}", expectedError: `auth: method: must be "token", "app" or "login", not "oauth"`},
+		{name: "app without key", data: "version: 1\nauth: {method: app, app: {id: '1'}}", expectedError: `auth: app: id and private_key_file are required with method "app"`},
+		{name: "app with token method", data: "version: 1\nauth: {method: token, app: {id: '1'}}", expectedError: `auth: app: only allowed with method "app"`},
+		{name: "credentials file with token method", data: "version: 1\nauth: {method: token, credentials_file: creds.json}", expectedError: `auth: credentials_file: only allowed with method "login"`},
+		{name: "log format", data: "version: 1\nlogging: {format: xml}", expectedError: `logging: format: must be "text" or "json", not "xml"`},
+		{name: "log level", data: "version: 1\nlogging: {level: trace}", expectedError: `logging: level: must be "debug", "info", "warn" or "error", not "trace"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.data))
+			require.Error(t, err)
+			assert.Equal(t, tc.expectedError, err.Error())
+		})
+	}
+}
+
+func Test_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\nfoo: bar\n"), 0600))
+
+	_, err := Load(path)
+	assert.EqualError(t, err, "invalid config file "+path+`: line 2: unknown key "foo"`)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read config file")
+}
@@ -0,0 +1,447 @@
+package ghmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/netconfig"
+	"github.com/github/github-mcp-server/pkg/raw"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	gogithub "github.com/google/go-github/v79/github"
+	"github.com/shurcooL/githubv4"
+)
+
+type DoctorConfig struct {
+	// Version of the server
+	Version string
+
+	// GitHub Host to check (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// GitHub Token to check, falling back to the token stored by RunLogin
+	Token string
+
+	// CredentialsFile is the path of the credential store (empty for the default location)
+	CredentialsFile string
+
+	// EnabledToolsets is a list of toolsets to check
+	EnabledToolsets []string
+
+	// EnabledTools is a list of specific tools to check
+	EnabledTools []string
+
+	// DynamicToolsets tells whether dynamic toolsets are enabled
+	DynamicToolsets bool
+
+	// ReadOnly tells whether the server is restricted to read-only operations
+	ReadOnly bool
+
+	// Endpoints override the API URLs derived from Host
+	Endpoints APIEndpoints
+
+	// Network configures the connections to GitHub: CA certificates, proxy and client certificate
+	Network netconfig.Config
+}
+
+// RunDoctor checks the configuration of the server and the token against the GitHub host, and prints a
+// report to stdout. It fails if any check fails; warnings don't fail it.
+func RunDoctor(cfg DoctorConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if failed := runDoctor(ctx, cfg, os.Stdout); failed > 0 {
+		return fmt.Errorf("%d doctor checks failed", failed)
+	}
+	return nil
+}
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+)
+
+// check is the outcome of one doctor check: a one line summary, with details indented below it.
+type check struct {
+	name    string
+	status  checkStatus
+	summary string
+	details []string
+}
+
+// doctor runs the checks, in order, and keeps the state later checks depend on.
+type doctor struct {
+	cfg       DoctorConfig
+	transport http.RoundTripper
+	apiHost   apiHost
+	token     string
+	client    *gogithub.Client
+	// scopes are the OAuth scopes of a classic token, or nil if the token doesn't report them
+	scopes []string
+	checks []check
+}
+
+func (d *doctor) add(name string, status checkStatus, summary string, details ...string) {
+	d.checks = append(d.checks, check{name: name, status: status, summary: summary, details: details})
+}
+
+// runDoctor writes the report to w and returns the number of failed checks. Checks that need a working
+// host or token are skipped when those fail, as they would only repeat the failure.
+func runDoctor(ctx context.Context, cfg DoctorConfig, w io.Writer) int {
+	d := &doctor{cfg: cfg}
+	if d.checkNetwork() && d.checkHost() && d.checkToken(ctx) {
+		d.checkScopes()
+		d.checkSSO(ctx)
+		d.checkRateLimit(ctx)
+		d.checkGraphQL(ctx)
+	}
+	d.checkToolsets()
+	d.checkTools()
+
+	counts := map[checkStatus]int{}
+	for _, c := range d.checks {
+		counts[c.status]++
+		_, _ = fmt.Fprintf(w, "[%s] %s: %s\n", c.status, c.name, c.summary)
+		for _, detail := range c.details {
+			_, _ = fmt.Fprintf(w, "       %s\n", detail)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed\n", counts[checkPass], counts[checkWarn], counts[checkFail])
+	return counts[checkFail]
+}
+
+func (d *doctor) checkNetwork() bool {
+	transport, err := d.cfg.Network.Transport()
+	if err != nil {
+		d.add("Network", checkFail, err.Error())
+		return false
+	}
+	d.transport = transport
+	return true
+}
+
+func (d *doctor) checkHost() bool {
+	apiHost, err := parseAPIHost(d.cfg.Host, d.cfg.Endpoints, d.transport)
+	if err != nil {
+		d.add("Host", checkFail, err.Error())
+		return false
+	}
+	d.apiHost = apiHost
+
+	isolation := "not probed (not a GitHub Enterprise Server host, or the upload and raw URLs are given)"
+	if apiHost.subdomainIsolation != nil {
+		isolation = "disabled"
+		if *apiHost.subdomainIsolation {
+			isolation = "enabled"
+		}
+	}
+	d.add("Host", checkPass, apiHost.webURL.Host,
+		"REST URL:            "+apiHost.baseRESTURL.String(),
+		"GraphQL URL:         "+apiHost.graphqlURL.String(),
+		"Raw URL:             "+apiHost.rawURL.String(),
+		"Upload URL:          "+apiHost.uploadURL.String(),
+		"Subdomain isolation: "+isolation)
+	return true
+}
+
+func (d *doctor) checkToken(ctx context.Context) bool {
+	source := "GITHUB_PERSONAL_ACCESS_TOKEN"
+	d.token = d.cfg.Token
+	if d.token == "" {
+		token, err := storedToken(d.cfg.CredentialsFile, d.apiHost)
+		if err != nil {
+			d.add("Token", checkFail, err.Error())
+			return false
+		}
+		source = "credentials stored by login"
+		d.token = token
+	}
+
+	user, resp, err := getAuthenticatedUser(ctx, d.cfg.Version, d.apiHost, d.transport, d.token)
+	if err != nil {
+		d.add("Token", checkFail, err.Error(), hint(resp, err)...)
+		return false
+	}
+
+	d.client = gogithub.NewClient(&http.Client{Transport: d.transport}).WithAuthToken(d.token)
+	d.client.UserAgent = fmt.Sprintf("github-mcp-server/%s", d.cfg.Version)
+	d.client.BaseURL = d.apiHost.baseRESTURL
+
+	if values := resp.Header.Values("X-OAuth-Scopes"); len(values) > 0 {
+		d.scopes = github.ParseOAuthScopes(values[0])
+		if d.scopes == nil {
+			d.scopes = []string{}
+		}
+	}
+
+	d.add("Token", checkPass, "authenticated as "+user.GetLogin(),
+		"Source: "+source,
+		"Type:   "+tokenType(d.token))
+	return true
+}
+
+// tokenType describes a token by its prefix. See
+// https://github.blog/engineering/platform-security/behind-githubs-new-authentication-token-formats/
+func tokenType(token string) string {
+	types := []struct {
+		prefix      string
+		description string
+	}{
+		{prefix: "ghp_", description: "classic personal access token"},
+		{prefix: "github_pat_", description: "fine-grained personal access token"},
+		{prefix: "gho_", description: "OAuth app token"},
+		{prefix: "ghu_", description: "GitHub App user access token"},
+		{prefix: "ghs_", description: "GitHub App installation token"},
+	}
+	for _, t := range types {
+		if strings.HasPrefix(token, t.prefix) {
+			return t.description
+		}
+	}
+	return "unknown (no known token prefix)"
+}
+
+// checkScopes reports the scopes of a classic token, and warns about the enabled tools it lacks the scopes
+// for, which the server hides.
+func (d *doctor) checkScopes() {
+	if d.scopes == nil {
+		d.add("Scopes", checkPass, "the token does not report OAuth scopes, its permissions are checked by GitHub on each request")
+		return
+	}
+
+	granted := "none"
+	if len(d.scopes) > 0 {
+		granted = strings.Join(d.scopes, ", ")
+	}
+	unusable := github.UnusableWithScopes(d.scopes)
+	var details []string
+	for _, tool := range d.enabledTools() {
+		if ok, reason := unusable(tool); ok {
+			details = append(details, fmt.Sprintf("%s: %s", tool.Tool.Name, reason))
+		}
+	}
+	if len(details) > 0 {
+		d.add("Scopes", checkWarn, fmt.Sprintf("%s; %d enabled tools need scopes the token lacks", granted, len(details)), details...)
+		return
+	}
+	d.add("Scopes", checkPass, granted)
+}
+
+// checkSSO lists the organizations of the user, which GitHub answers with partial results and an
+// X-GitHub-SSO header when the token isn't authorized for some of them.
+func (d *doctor) checkSSO(ctx context.Context) {
+	orgs, resp, err := d.client.Organizations.List(ctx, "", &gogithub.ListOptions{PerPage: 100})
+	if err != nil {
+		d.add("SSO", checkWarn, fmt.Sprintf("failed to list organizations: %v", err), hint(resp, err)...)
+		return
+	}
+
+	sso := resp.Header.Get("X-GitHub-SSO")
+	switch {
+	case strings.HasPrefix(sso, "partial-results"):
+		var ids []string
+		for _, field := range strings.Split(sso, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(field), "organizations="); ok {
+				ids = strings.Split(value, ",")
+			}
+		}
+		d.add("SSO", checkWarn, fmt.Sprintf("the token is not authorized for SAML single sign-on in %d organizations", len(ids)),
+			"Organization IDs: "+strings.Join(ids, ", "),
+			"Authorize the token for them in the token settings, or their resources will not be found.")
+	case strings.HasPrefix(sso, "required"):
+		d.add("SSO", checkWarn, "the token is not authorized for SAML single sign-on", hint(resp, nil)...)
+	default:
+		logins := make([]string, 0, len(orgs))
+		for _, org := range orgs {
+			logins = append(logins, org.GetLogin())
+		}
+		sort.Strings(logins)
+		summary := "no organizations"
+		if len(logins) > 0 {
+			summary = "authorized for " + strings.Join(logins, ", ")
+		}
+		d.add("SSO", checkPass, summary)
+	}
+}
+
+func (d *doctor) checkRateLimit(ctx context.Context) {
+	limits, resp, err := d.client.RateLimit.Get(ctx)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			d.add("Rate limit", checkPass, "rate limiting is disabled on the host")
+			return
+		}
+		d.add("Rate limit", checkWarn, err.Error(), hint(resp, err)...)
+		return
+	}
+
+	status := checkPass
+	var details []string
+	for _, resource := range []struct {
+		name string
+		rate *gogithub.Rate
+	}{
+		{name: "core", rate: limits.GetCore()},
+		{name: "graphql", rate: limits.GetGraphQL()},
+		{name: "search", rate: limits.GetSearch()},
+	} {
+		if resource.rate == nil {
+			continue
+		}
+		r := resource.rate
+		details = append(details, fmt.Sprintf("%-8s %d of %d remaining, resets at %s",
+			resource.name+":", r.Remaining, r.Limit, r.Reset.Local().Format("15:04")))
+		switch {
+		case r.Remaining == 0:
+			status = checkFail
+		case r.Remaining*10 < r.Limit && status == checkPass:
+			status = checkWarn
+		}
+	}
+
+	summary := map[checkStatus]string{
+		checkPass: "budget available",
+		checkWarn: "less than 10% of a budget remaining",
+		checkFail: "a budget is exhausted",
+	}[status]
+	d.add("Rate limit", status, summary, details...)
+}
+
+func (d *doctor) checkGraphQL(ctx context.Context) {
+	httpClient := &http.Client{Transport: &userAgentTransport{
+		transport: &bearerAuthTransport{transport: d.transport, token: d.token},
+		agent:     fmt.Sprintf("github-mcp-server/%s", d.cfg.Version),
+	}}
+	client := githubv4.NewEnterpriseClient(d.apiHost.graphqlURL.String(), httpClient)
+
+	var query struct {
+		Viewer struct {
+			Login githubv4.String
+		}
+	}
+	if err := client.Query(ctx, &query, nil); err != nil {
+		d.add("GraphQL", checkFail, fmt.Sprintf("failed to query the GraphQL API: %v", err))
+		return
+	}
+	d.add("GraphQL", checkPass, "queried as "+string(query.Viewer.Login))
+}
+
+func (d *doctor) checkToolsets() {
+	enabled, invalid := resolveToolsets(d.cfg.EnabledToolsets, d.cfg.DynamicToolsets)
+	if len(invalid) > 0 {
+		d.add("Toolsets", checkFail, "unknown toolsets: "+strings.Join(invalid, ", "),
+			"Valid toolsets: "+strings.Join(validToolsets(), ", "))
+		return
+	}
+
+	summary := strings.Join(enabled, ", ")
+	if len(enabled) == 0 {
+		summary = "none"
+	}
+	if d.cfg.DynamicToolsets {
+		summary += " (more can be enabled dynamically)"
+	}
+	d.add("Toolsets", checkPass, summary)
+}
+
+func (d *doctor) checkTools() {
+	names := github.CleanTools(d.cfg.EnabledTools)
+	if len(names) == 0 {
+		return
+	}
+
+	tsg := doctorToolsetGroup(d.cfg.ReadOnly)
+	var unknown, skipped []string
+	for _, name := range names {
+		tool, _, err := tsg.FindToolByName(name)
+		switch {
+		case err != nil:
+			unknown = append(unknown, name)
+		case d.cfg.ReadOnly && !tool.Tool.Annotations.ReadOnlyHint:
+			skipped = append(skipped, name)
+		}
+	}
+
+	switch {
+	case len(unknown) > 0:
+		d.add("Tools", checkFail, "unknown tools: "+strings.Join(unknown, ", "))
+	case len(skipped) > 0:
+		d.add("Tools", checkWarn, "write tools skipped in read-only mode: "+strings.Join(skipped, ", "))
+	default:
+		d.add("Tools", checkPass, strings.Join(names, ", "))
+	}
+}
+
+// enabledTools returns the tools the configured toolsets and tools enable, before any are hidden.
+func (d *doctor) enabledTools() []toolsets.ServerTool {
+	tsg := doctorToolsetGroup(d.cfg.ReadOnly)
+	enabled, _ := resolveToolsets(d.cfg.EnabledToolsets, d.cfg.DynamicToolsets)
+	_ = tsg.EnableToolsets(enabled, nil)
+
+	seen := map[string]bool{}
+	var tools []toolsets.ServerTool
+	for _, ts := range tsg.Toolsets {
+		for _, tool := range ts.GetActiveTools() {
+			seen[tool.Tool.Name] = true
+			tools = append(tools, tool)
+		}
+	}
+	for _, name := range github.CleanTools(d.cfg.EnabledTools) {
+		tool, _, err := tsg.FindToolByName(name)
+		if err != nil || seen[name] || (d.cfg.ReadOnly && !tool.Tool.Annotations.ReadOnlyHint) {
+			continue
+		}
+		seen[name] = true
+		tools = append(tools, *tool)
+	}
+	sort.Slice(tools, func(a, b int) bool {
+		return tools[a].Tool.Name < tools[b].Tool.Name
+	})
+	return tools
+}
+
+// doctorToolsetGroup returns the toolsets for looking up tools. Their handlers are never called, so the
+// clients are never created.
+func doctorToolsetGroup(readOnly bool) *toolsets.ToolsetGroup {
+	errNoClient := errors.New("doctor does not call tools")
+	return github.DefaultToolsetGroup(
+		readOnly,
+		func(context.Context) (*gogithub.Client, error) { return nil, errNoClient },
+		func(context.Context) (*githubv4.Client, error) { return nil, errNoClient },
+		func(context.Context) (*raw.Client, error) { return nil, errNoClient },
+		translations.NullTranslationHelper,
+		0,
+		github.FeatureFlags{},
+		nil,
+	)
+}
+
+func validToolsets() []string {
+	var ids []string
+	for id := range github.GetValidToolsetIDs() {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// hint returns the remediation hint for a GitHub API error, if there is one, as report details.
+func hint(resp *gogithub.Response, err error) []string {
+	if h := ghErrors.ClassifyAPIError(resp, err).Hint; h != "" {
+		return []string{h}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package ghmcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDoctorTestServer serves the endpoints doctor calls, as a GitHub Enterprise Server host would.
+func newDoctorTestServer(t *testing.T, scopes, sso string, remaining int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ghp_valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+		if scopes != "" {
+			w.Header().Set("X-OAuth-Scopes", scopes)
+		}
+		_, _ = w.Write([]byte(`{"login":"octocat"}`))
+	})
+	mux.HandleFunc("GET /api/v3/user/orgs", func(w http.ResponseWriter, _ *http.Request) {
+		if sso != "" {
+			w.Header().Set("X-GitHub-SSO", sso)
+		}
+		_, _ = w.Write([]byte(`[{"login":"octo-org"}]`))
+	})
+	mux.HandleFunc("GET /api/v3/rate_limit", func(w http.ResponseWriter, _ *http.Request) {
+		reset := time.Now().Add(time.Hour).Unix()
+		_, _ = fmt.Fprintf(w, `{"resources":{"core":{"limit":5000,"remaining":%d,"reset":%d},"graphql":{"limit":5000,"remaining":5000,"reset":%d}}}`,
+			remaining, reset, reset)
+	})
+	mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"login":"octocat"}}}`))
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func doctorTestConfig(ts *httptest.Server) DoctorConfig {
+	return DoctorConfig{
+		Host:  ts.URL,
+		Token: "ghp_valid",
+		// Giving the upload and raw URLs skips the subdomain isolation probe
+		Endpoints: APIEndpoints{
+			UploadURL: ts.URL + "/api/uploads/",
+			RawURL:    ts.URL + "/raw/",
+		},
+		EnabledToolsets: []string{"default"},
+		CredentialsFile: "/nonexistent/credentials.json",
+	}
+}
+
+func Test_RunDoctor(t *testing.T) {
+	tests := []struct {
+		name           string
+		scopes         string
+		sso            string
+		remaining      int
+		configure      func(cfg *DoctorConfig)
+		expectedFailed int
+		expectedLines  []string
+	}{
+		{
+			name:      "healthy configuration",
+			scopes:    "repo, read:org, notifications",
+			remaining: 4000,
+			expectedLines: []string{
+				"[PASS] Host: 127.0.0.1",
+				"REST URL:            http://127.0.0.1",
+				"[PASS] Token: authenticated as octocat",
+				"Type:   classic personal access token",
+				"[PASS] Scopes: repo, read:org, notifications",
+				"[PASS] SSO: authorized for octo-org",
+				"[PASS] Rate limit: budget available",
+				"core:    4000 of 5000 remaining",
+				"[PASS] GraphQL: queried as octocat",
+				"[PASS] Toolsets: context, repos, issues, pull_requests, users",
+				"0 warnings, 0 failed",
+			},
+		},
+		{
+			name:      "missing scopes, SSO and a low budget are warnings",
+			scopes:    "repo",
+			sso:       "partial-results; organizations=21955855,20582480",
+			remaining: 100,
+			configure: func(cfg *DoctorConfig) {
+				cfg.EnabledToolsets = []string{"gists"}
+			},
+			expectedLines: []string{
+				"[WARN] Scopes: repo; 2 enabled tools need scopes the token lacks",
+				"create_gist: token lacks the gist scope",
+				"update_gist: token lacks the gist scope",
+				"[WARN] SSO: the token is not authorized for SAML single sign-on in 2 organizations",
+				"Organization IDs: 21955855, 20582480",
+				"[WARN] Rate limit: less than 10% of a budget remaining",
+				"3 warnings, 0 failed",
+			},
+		},
+		{
+			name:           "exhausted budget fails",
+			remaining:      0,
+			expectedFailed: 1,
+			expectedLines: []string{
+				"[PASS] Scopes: the token does not report OAuth scopes",
+				"[FAIL] Rate limit: a budget is exhausted",
+			},
+		},
+		{
+			name:           "invalid token skips the API checks",
+			configure:      func(cfg *DoctorConfig) { cfg.Token = "ghp_invalid" },
+			expectedFailed: 1,
+			expectedLines: []string{
+				"[FAIL] Token: failed to get authenticated user",
+				"The token is invalid, expired or revoked.",
+				"[PASS] Toolsets:",
+				"2 passed, 0 warnings, 1 failed",
+			},
+		},
+		{
+			name:      "unknown toolsets and tools fail",
+			remaining: 5000,
+			configure: func(cfg *DoctorConfig) {
+				cfg.EnabledToolsets = []string{"repos", "repo"}
+				cfg.EnabledTools = []string{"get_me", "get_you"}
+			},
+			expectedFailed: 2,
+			expectedLines: []string{
+				"[FAIL] Toolsets: unknown toolsets: repo",
+				"[FAIL] Tools: unknown tools: get_you",
+			},
+		},
+		{
+			name:      "write tools in read-only mode are a warning",
+			remaining: 5000,
+			configure: func(cfg *DoctorConfig) {
+				cfg.ReadOnly = true
+				cfg.EnabledTools = []string{"get_me", "issue_write"}
+			},
+			expectedLines: []string{
+				"[WARN] Tools: write tools skipped in read-only mode: issue_write",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newDoctorTestServer(t, tc.scopes, tc.sso, tc.remaining)
+			cfg := doctorTestConfig(ts)
+			if tc.configure != nil {
+				tc.configure(&cfg)
+			}
+
+			var out bytes.Buffer
+			failed := runDoctor(context.Background(), cfg, &out)
+
+			assert.Equal(t, tc.expectedFailed, failed, out.String())
+			for _, line := range tc.expectedLines {
+				assert.Contains(t, out.String(), line)
+			}
+		})
+	}
+}
+
+func Test_RunDoctorWithoutToken(t *testing.T) {
+	ts := newDoctorTestServer(t, "", "", 5000)
+	cfg := doctorTestConfig(ts)
+	cfg.Token = ""
+	cfg.CredentialsFile = t.TempDir() + "/credentials.json"
+
+	var out bytes.Buffer
+	require.Equal(t, 1, runDoctor(context.Background(), cfg, &out))
+	assert.Contains(t, out.String(), "[FAIL] Token: GITHUB_PERSONAL_ACCESS_TOKEN not set and not logged in to 127.0.0.1")
+}
+
+func Test_TokenType(t *testing.T) {
+	assert.Equal(t, "classic personal access token", tokenType("ghp_abc"))
+	assert.Equal(t, "fine-grained personal access token", tokenType("github_pat_abc"))
+	assert.Equal(t, "GitHub App installation token", tokenType("ghs_abc"))
+	assert.Equal(t, "unknown (no known token prefix)", tokenType("0123456789abcdef"))
+}
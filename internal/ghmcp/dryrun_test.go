@@ -0,0 +1,81 @@
+package ghmcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/github/github-mcp-server/internal/githubfake"
+	"github.com/github/github-mcp-server/pkg/dryrun"
+	"github.com/github/github-mcp-server/pkg/translations"
+	gogithub "github.com/google/go-github/v79/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DryRunPushFiles(t *testing.T) {
+	fake := githubfake.New()
+	defer fake.Close()
+	fake.AddRepository(fake.Login(), "hello", map[string]string{"README.md": "Hello"})
+
+	ctx := context.Background()
+	ghServer, err := NewMCPServer(MCPServerConfig{
+		Token:           fake.Token(),
+		Host:            fake.URL(),
+		Endpoints:       APIEndpoints{RESTURL: fake.RESTURL(), GraphQLURL: fake.GraphQLURL(), UploadURL: fake.UploadURL(), RawURL: fake.RawURL()},
+		EnabledToolsets: []string{"repos"},
+		Translator:      translations.NullTranslationHelper,
+		Logger:          slog.New(slog.DiscardHandler),
+	})
+	require.NoError(t, err)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := ghServer.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer serverSession.Close()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	restClient, err := gogithub.NewClient(nil).WithAuthToken(fake.Token()).WithEnterpriseURLs(fake.URL(), fake.URL())
+	require.NoError(t, err)
+	before, _, err := restClient.Git.GetRef(ctx, fake.Login(), "hello", "refs/heads/main")
+	require.NoError(t, err)
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "push_files",
+		Arguments: map[string]any{
+			"owner":   fake.Login(),
+			"repo":    "hello",
+			"branch":  "main",
+			"message": "Update README",
+			"files":   []any{map[string]any{"path": "README.md", "content": "Hello, world"}},
+			"dry_run": true,
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content)
+
+	var dryRun dryrun.Result
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &dryRun))
+	assert.True(t, dryRun.DryRun)
+
+	// The tree is based on the resolved commit, and the commit and the branch update refer to the
+	// operations they depend on
+	var methods []string
+	for _, op := range dryRun.Operations {
+		methods = append(methods, op.Method+" "+op.URL)
+	}
+	base := fake.RESTURL() + "repos/" + fake.Login() + "/hello/git/"
+	assert.Equal(t, []string{"POST " + base + "trees", "POST " + base + "commits", "PATCH " + base + "refs/heads/main"}, methods)
+	assert.Contains(t, string(dryRun.Operations[0].Body), "Hello, world")
+	assert.Contains(t, string(dryRun.Operations[1].Body), `"tree":"(sha of operation 1)"`)
+	assert.Contains(t, string(dryRun.Operations[1].Body), before.GetObject().GetSHA())
+	assert.Contains(t, string(dryRun.Operations[2].Body), `"sha":"(sha of operation 2)"`)
+
+	after, _, err := restClient.Git.GetRef(ctx, fake.Login(), "hello", "refs/heads/main")
+	require.NoError(t, err)
+	assert.Equal(t, before.GetObject().GetSHA(), after.GetObject().GetSHA(), "the branch must not change")
+}
@@ -0,0 +1,28 @@
+package ghmcp
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+)
+
+// excludeTools makes sure that the tools matching the excluded names and glob patterns are never
+// registered, whether through their toolset, by name or dynamically.
+func excludeTools(tsg *toolsets.ToolsetGroup, excluded []string, logger *slog.Logger) error {
+	removed, err := tsg.ExcludeTools(github.CleanTools(excluded))
+	if err != nil {
+		return err
+	}
+
+	excludedNames := make([]string, 0, len(removed))
+	for name := range removed {
+		excludedNames = append(excludedNames, name)
+	}
+	sort.Strings(excludedNames)
+	for _, name := range excludedNames {
+		logger.Info("tool excluded", "tool", name, "reason", removed[name])
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package ghmcp
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExcludeTools(t *testing.T) {
+	tsg := doctorToolsetGroup(false)
+	require.NoError(t, tsg.EnableToolsets([]string{"gists", "context"}, nil))
+
+	require.NoError(t, excludeTools(tsg, []string{"create_*", " get_me ", "unknown_tool"}, slog.New(slog.DiscardHandler)))
+
+	var active []string
+	for _, name := range []string{"gists", "context"} {
+		for _, tool := range tsg.Toolsets[name].GetActiveTools() {
+			active = append(active, tool.Tool.Name)
+		}
+	}
+	assert.NotContains(t, active, "create_gist")
+	assert.NotContains(t, active, "get_me")
+	assert.Contains(t, active, "update_gist")
+
+	_, _, err := tsg.FindToolByName("create_gist")
+	var notFound *toolsets.ToolDoesNotExistError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func Test_ExcludeToolsInvalidPattern(t *testing.T) {
+	err := excludeTools(doctorToolsetGroup(false), []string{"delete_["}, slog.New(slog.DiscardHandler))
+	assert.EqualError(t, err, `invalid tool pattern "delete_[": syntax error in pattern`)
+}
@@ -0,0 +1,430 @@
+package ghmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/netconfig"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type HTTPServerConfig struct {
+	// Version of the server
+	Version string
+
+	// GitHub Host to target for API requests (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// ListenAddress is the address the HTTP server listens on (e.g. ":8082")
+	ListenAddress string
+
+	// EnabledToolsets is a list of toolsets to enable
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
+	EnabledToolsets []string
+
+	// EnabledTools is a list of specific tools to enable (additive to toolsets)
+	// When specified, these tools are registered in addition to any specified toolset tools
+	EnabledTools []string
+
+	// ExcludedTools are names and glob patterns (e.g. delete_*) of tools that are never registered, whether
+	// through their toolset, by name or dynamically
+	ExcludedTools []string
+
+	// Whether to enable dynamic toolsets
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#dynamic-tool-discovery
+	DynamicToolsets bool
+
+	// ReadOnly indicates if we should only register read-only tools
+	ReadOnly bool
+
+	// DisableScopeFiltering keeps tools that the token of a session lacks the scopes for
+	DisableScopeFiltering bool
+
+	// ExportTranslations indicates if we should export translations
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#i18n--overriding-descriptions
+	ExportTranslations bool
+
+	// TranslationOverrides override tool descriptions and other texts by translation key
+	TranslationOverrides map[string]string
+
+	// Path to the log file if not stderr
+	LogFilePath string
+
+	// LogFormat is the format of the logs, "text" or "json"
+	LogFormat string
+
+	// LogLevel is the minimum level of the logs (empty for debug in the log file and info on stderr)
+	LogLevel string
+
+	// Content window size
+	ContentWindowSize int
+
+	// LockdownMode indicates if we should enable lockdown mode
+	LockdownMode bool
+
+	// RepoAccessCacheTTL overrides the default TTL for repository access cache entries.
+	RepoAccessCacheTTL *time.Duration
+
+	// ResponseCacheSize is the memory used for caching GitHub API responses, in bytes (0 disables the memory cache)
+	ResponseCacheSize int64
+
+	// ResponseCacheDir is a directory to also cache GitHub API responses in, kept across restarts (empty for none)
+	ResponseCacheDir string
+
+	// RetryMutations also retries requests that may change data after a transient failure
+	RetryMutations bool
+
+	// OTLPEndpoint is the URL of an OpenTelemetry collector to export traces to over OTLP/HTTP (empty disables tracing)
+	OTLPEndpoint string
+
+	// MetricsAddress is the address to serve Prometheus metrics on at /metrics (empty disables metrics)
+	MetricsAddress string
+
+	// Endpoints override the API URLs derived from Host
+	Endpoints APIEndpoints
+
+	// Network configures the connections to GitHub: CA certificates, proxy and client certificate
+	Network netconfig.Config
+
+	// RecordDir is a directory to record the GitHub API traffic to, as cassettes (empty disables recording)
+	RecordDir string
+
+	// ReplayDir is a directory of cassettes to answer GitHub API requests from instead of GitHub (empty for none)
+	ReplayDir string
+
+	// AuditLogPath is the file to record every call to a write tool in, as JSON lines (empty disables auditing)
+	AuditLogPath string
+
+	// AuditLogMaxSize is the size in bytes at which the audit log is rotated (0 never rotates it)
+	AuditLogMaxSize int64
+
+	// AuditLogMaxBackups is the number of rotated audit logs to keep
+	AuditLogMaxBackups int
+
+	// ErrorMeta attaches the GitHub API errors of a tool call to the _meta of its result
+	ErrorMeta bool
+
+	// AllowedRepos are the owner/repo glob patterns of the repositories tools can access, optionally
+	// read-only (empty allows all repositories that are not denied)
+	AllowedRepos []string
+
+	// DeniedRepos are the owner/repo glob patterns of the repositories tools can't access
+	DeniedRepos []string
+
+	// ConfirmTools are the names, glob patterns and tool:method patterns of the tools whose calls the user
+	// must approve
+	ConfirmTools []string
+
+	// DryRun makes every call to a write tool a dry run
+	DryRun bool
+}
+
+// RunHTTPServer serves the MCP Streamable HTTP transport. Unlike the stdio server there is no
+// static token: every request must carry its own GitHub token in the Authorization header, and
+// GitHub API calls made while handling that request are authenticated with it. Each session may
+// narrow the configured tools through its URL path and X-MCP-* headers, see resolveSessionConfig.
+func RunHTTPServer(cfg HTTPServerConfig) error {
+	// Create app context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	t, dumpTranslations := translations.TranslationHelperWithOverrides(cfg.TranslationOverrides)
+
+	logger, err := newLogger(cfg.LogFilePath, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	logger.Info("starting server", "version", cfg.Version, "host", cfg.Host, "address", cfg.ListenAddress, "dynamicToolsets", cfg.DynamicToolsets, "readOnly", cfg.ReadOnly, "lockdownEnabled", cfg.LockdownMode, "dryRun", cfg.DryRun)
+
+	transport, err := newGitHubTransport(cfg.Network, cfg.RecordDir, cfg.ReplayDir, logger)
+	if err != nil {
+		return err
+	}
+
+	apiHost, err := parseAPIHost(cfg.Host, cfg.Endpoints, transport)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+
+	tracerProvider, shutdownTracing, err := newTracerProvider(ctx, cfg.OTLPEndpoint, cfg.Version)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+
+	serverMetrics, err := startMetricsServer(ctx, cfg.MetricsAddress, logger)
+	if err != nil {
+		return err
+	}
+
+	auditLog, err := openAuditLog(cfg.AuditLogPath, cfg.AuditLogMaxSize, cfg.AuditLogMaxBackups, logger)
+	if err != nil {
+		return err
+	}
+	if auditLog != nil {
+		defer func() { _ = auditLog.Close() }()
+	}
+
+	repoPolicy, err := newRepoPolicy(cfg.AllowedRepos, cfg.DeniedRepos, logger)
+	if err != nil {
+		return err
+	}
+	confirmationPolicy, err := newConfirmationPolicy(cfg.ConfirmTools, logger)
+	if err != nil {
+		return err
+	}
+
+	serverConfig := MCPServerConfig{
+		Version:               cfg.Version,
+		Host:                  cfg.Host,
+		Endpoints:             cfg.Endpoints,
+		EnabledToolsets:       cfg.EnabledToolsets,
+		EnabledTools:          cfg.EnabledTools,
+		ExcludedTools:         cfg.ExcludedTools,
+		DynamicToolsets:       cfg.DynamicToolsets,
+		ReadOnly:              cfg.ReadOnly,
+		DisableScopeFiltering: cfg.DisableScopeFiltering,
+		Translator:            t,
+		ContentWindowSize:     cfg.ContentWindowSize,
+		LockdownMode:          cfg.LockdownMode,
+		Logger:                logger,
+		RepoAccessTTL:         cfg.RepoAccessCacheTTL,
+		ResponseCache:         newResponseCache(cfg.ResponseCacheSize, cfg.ResponseCacheDir),
+		RetryMutations:        cfg.RetryMutations,
+		TracerProvider:        tracerProvider,
+		Metrics:               serverMetrics,
+		Transport:             transport,
+		AuditLog:              auditLog,
+		RepoPolicy:            repoPolicy,
+		Confirmation:          confirmationPolicy,
+		DryRun:                cfg.DryRun,
+		ErrorMeta:             cfg.ErrorMeta,
+	}
+
+	// Build a server for the default configuration up front so that configuration errors
+	// surface at startup rather than on the first session. There is no token to filter tools by yet.
+	validationConfig := serverConfig
+	validationConfig.DisableScopeFiltering = true
+	if _, err := newMCPServer(ctx, validationConfig, apiHost); err != nil {
+		return fmt.Errorf("failed to create MCP server: %w", err)
+	}
+
+	if cfg.ExportTranslations {
+		// Once server is initialized, all translations are loaded
+		dumpTranslations()
+	}
+
+	httpServer := &http.Server{
+		Addr:              cfg.ListenAddress,
+		Handler:           requireBearerToken(newSessionHandler(serverConfig, apiHost, logger)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Start listening for requests
+	errC := make(chan error, 1)
+	go func() {
+		errC <- httpServer.ListenAndServe()
+	}()
+
+	// Output github-mcp-server string
+	_, _ = fmt.Fprintf(os.Stderr, "GitHub MCP Server running on http://%s\n", cfg.ListenAddress)
+
+	// Wait for shutdown signal
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down server", "signal", "context done")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("error shutting down server: %w", err)
+		}
+	case err := <-errC:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("error running server", "error", err)
+			return fmt.Errorf("error running server: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sessionConfig is the tool selection of a single HTTP session.
+type sessionConfig struct {
+	EnabledToolsets []string
+	EnabledTools    []string
+	ReadOnly        bool
+	LockdownMode    bool
+}
+
+var errUnknownRoute = errors.New("unknown route")
+
+// resolveSessionConfig applies the URL path and X-MCP-* headers of the request that opens a session
+// on top of the server-wide defaults. The supported paths are:
+//
+//   - / and /readonly
+//   - /x/{toolset} and /x/{toolset}/readonly
+//
+// A toolset in the path takes precedence over the X-MCP-Toolsets header. If the request selects any
+// toolsets or tools, that selection replaces the default one. Read-only and lockdown mode can only be
+// turned on by a request, never off.
+func resolveSessionConfig(r *http.Request, defaults sessionConfig) (sessionConfig, error) {
+	sc := defaults
+
+	var pathToolset string
+	var pathReadOnly bool
+	segments := strings.FieldsFunc(r.URL.Path, func(c rune) bool { return c == '/' })
+	if len(segments) > 0 && segments[len(segments)-1] == "readonly" {
+		pathReadOnly = true
+		segments = segments[:len(segments)-1]
+	}
+	switch {
+	case len(segments) == 0:
+	case len(segments) == 2 && segments[0] == "x":
+		pathToolset = segments[1]
+		if !github.GetValidToolsetIDs()[pathToolset] {
+			return sessionConfig{}, fmt.Errorf("%w: toolset %s does not exist", errUnknownRoute, pathToolset)
+		}
+	default:
+		return sessionConfig{}, fmt.Errorf("%w: %s", errUnknownRoute, r.URL.Path)
+	}
+
+	headerToolsets := splitHeaderList(r.Header.Get("X-MCP-Toolsets"))
+	headerTools := splitHeaderList(r.Header.Get("X-MCP-Tools"))
+
+	if pathToolset != "" || len(headerToolsets) > 0 || len(headerTools) > 0 {
+		sc.EnabledToolsets = headerToolsets
+		if pathToolset != "" {
+			sc.EnabledToolsets = []string{pathToolset}
+		}
+		sc.EnabledTools = headerTools
+	}
+
+	sc.ReadOnly = defaults.ReadOnly || pathReadOnly || parseHeaderBool(r.Header.Get("X-MCP-Readonly"))
+	sc.LockdownMode = defaults.LockdownMode || parseHeaderBool(r.Header.Get("X-MCP-Lockdown"))
+
+	return sc, nil
+}
+
+// splitHeaderList splits a comma-separated header value, dropping whitespace and empty entries.
+func splitHeaderList(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// parseHeaderBool interprets a boolean header. Empty values and "false", "f", "no", "n", "0" or "off"
+// (ignoring whitespace and case) are false, everything else is true.
+func parseHeaderBool(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "f", "no", "n", "0", "off":
+		return false
+	default:
+		return true
+	}
+}
+
+type sessionServerContextKey struct{}
+
+// newSessionHandler returns the MCP Streamable HTTP handler. Each new session gets its own server,
+// built from the base configuration and the session config of the request that opened it.
+func newSessionHandler(base MCPServerConfig, apiHost apiHost, logger *slog.Logger) http.Handler {
+	defaults := sessionConfig{
+		EnabledToolsets: base.EnabledToolsets,
+		EnabledTools:    base.EnabledTools,
+		ReadOnly:        base.ReadOnly,
+		LockdownMode:    base.LockdownMode,
+	}
+
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+		server, _ := r.Context().Value(sessionServerContextKey{}).(*mcp.Server)
+		return server
+	}, &mcp.StreamableHTTPOptions{Logger: logger})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests belonging to an existing session are routed to that session's server by the MCP handler
+		if r.Method != http.MethodPost || r.Header.Get("Mcp-Session-Id") != "" {
+			mcpHandler.ServeHTTP(w, r)
+			return
+		}
+
+		sc, err := resolveSessionConfig(r, defaults)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, errUnknownRoute) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		cfg := base
+		cfg.EnabledToolsets = sc.EnabledToolsets
+		cfg.EnabledTools = sc.EnabledTools
+		cfg.ReadOnly = sc.ReadOnly
+		cfg.LockdownMode = sc.LockdownMode
+
+		// Tools are filtered by the token of the request opening the session
+		token, _ := parseAuthorizationHeader(r.Header.Get("Authorization"))
+		server, err := newMCPServer(contextWithToken(r.Context(), token), cfg, apiHost)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to create MCP server: %v", err), http.StatusBadRequest)
+			return
+		}
+		server.AddReceivingMiddleware(addRequestTokenToContext)
+
+		mcpHandler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionServerContextKey{}, server)))
+	})
+}
+
+// parseAuthorizationHeader extracts the token from an Authorization header value.
+// Both the "Bearer" and the GitHub-style "token" schemes are accepted.
+func parseAuthorizationHeader(value string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(value), " ")
+	if !found {
+		return "", false
+	}
+	if !strings.EqualFold(scheme, "bearer") && !strings.EqualFold(scheme, "token") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// requireBearerToken rejects HTTP requests that don't carry a GitHub token before they reach the MCP handler.
+func requireBearerToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := parseAuthorizationHeader(r.Header.Get("Authorization")); !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="github-mcp-server"`)
+			http.Error(w, "missing or malformed Authorization header", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// addRequestTokenToContext copies the token from the Authorization header of the HTTP request
+// carrying an MCP message onto the context, so the GitHub clients use it for that message only.
+func addRequestTokenToContext(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+			if token, ok := parseAuthorizationHeader(extra.Header.Get("Authorization")); ok {
+				ctx = contextWithToken(ctx, token)
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
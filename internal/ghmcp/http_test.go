@@ -0,0 +1,231 @@
+package ghmcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedToken string
+		expectedOK    bool
+	}{
+		{name: "bearer scheme", value: "Bearer ghp_abc", expectedToken: "ghp_abc", expectedOK: true},
+		{name: "token scheme", value: "token ghp_abc", expectedToken: "ghp_abc", expectedOK: true},
+		{name: "scheme is case insensitive", value: "bearer ghp_abc", expectedToken: "ghp_abc", expectedOK: true},
+		{name: "empty", value: "", expectedOK: false},
+		{name: "missing token", value: "Bearer ", expectedOK: false},
+		{name: "unsupported scheme", value: "Basic dXNlcjpwYXNz", expectedOK: false},
+		{name: "no scheme", value: "ghp_abc", expectedOK: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			token, ok := parseAuthorizationHeader(tc.value)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedToken, token)
+		})
+	}
+}
+
+func Test_RequireBearerToken(t *testing.T) {
+	handler := requireBearerToken(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("rejects requests without a token", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+	})
+
+	t.Run("passes requests with a token", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer ghp_abc")
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func Test_BearerAuthTransport_PrefersContextToken(t *testing.T) {
+	var gotAuth string
+	ts := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &bearerAuthTransport{transport: http.DefaultTransport, token: "static"}}
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "Bearer static", gotAuth)
+
+	req, err = http.NewRequestWithContext(contextWithToken(context.Background(), "per-request"), http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "Bearer per-request", gotAuth)
+}
+
+func Test_ResolveSessionConfig(t *testing.T) {
+	defaults := sessionConfig{
+		EnabledToolsets: []string{"default"},
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		headers     map[string]string
+		defaults    sessionConfig
+		expected    sessionConfig
+		expectedErr error
+	}{
+		{
+			name:     "root uses defaults",
+			path:     "/",
+			expected: defaults,
+		},
+		{
+			name:     "readonly suffix on root",
+			path:     "/readonly",
+			expected: sessionConfig{EnabledToolsets: []string{"default"}, ReadOnly: true},
+		},
+		{
+			name:     "single toolset",
+			path:     "/x/issues",
+			expected: sessionConfig{EnabledToolsets: []string{"issues"}},
+		},
+		{
+			name:     "single toolset read-only",
+			path:     "/x/issues/readonly/",
+			expected: sessionConfig{EnabledToolsets: []string{"issues"}, ReadOnly: true},
+		},
+		{
+			name:     "all toolsets",
+			path:     "/x/all",
+			expected: sessionConfig{EnabledToolsets: []string{"all"}},
+		},
+		{
+			name:     "headers select toolsets and tools",
+			path:     "/",
+			headers:  map[string]string{"X-MCP-Toolsets": " repos, issues ,", "X-MCP-Tools": "get_me"},
+			expected: sessionConfig{EnabledToolsets: []string{"repos", "issues"}, EnabledTools: []string{"get_me"}},
+		},
+		{
+			name:     "tools header alone replaces default toolsets",
+			path:     "/",
+			headers:  map[string]string{"X-MCP-Tools": "get_me"},
+			expected: sessionConfig{EnabledTools: []string{"get_me"}},
+		},
+		{
+			name:     "path toolset wins over header",
+			path:     "/x/actions",
+			headers:  map[string]string{"X-MCP-Toolsets": "repos"},
+			expected: sessionConfig{EnabledToolsets: []string{"actions"}},
+		},
+		{
+			name:     "boolean headers",
+			path:     "/",
+			headers:  map[string]string{"X-MCP-Readonly": "TRUE", "X-MCP-Lockdown": "1"},
+			expected: sessionConfig{EnabledToolsets: []string{"default"}, ReadOnly: true, LockdownMode: true},
+		},
+		{
+			name:     "headers cannot turn off server-wide read-only",
+			path:     "/",
+			headers:  map[string]string{"X-MCP-Readonly": "off"},
+			defaults: sessionConfig{EnabledToolsets: []string{"default"}, ReadOnly: true},
+			expected: sessionConfig{EnabledToolsets: []string{"default"}, ReadOnly: true},
+		},
+		{
+			name:        "unknown toolset in path",
+			path:        "/x/unknown",
+			expectedErr: errUnknownRoute,
+		},
+		{
+			name:        "unknown path",
+			path:        "/foo/bar",
+			expectedErr: errUnknownRoute,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			d := defaults
+			if tc.defaults.EnabledToolsets != nil {
+				d = tc.defaults
+			}
+
+			sc, err := resolveSessionConfig(req, d)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, sc)
+		})
+	}
+}
+
+func Test_SessionHandler(t *testing.T) {
+	apiHost, err := newDotcomHost()
+	require.NoError(t, err)
+
+	handler := newSessionHandler(MCPServerConfig{
+		Version:               "test",
+		EnabledToolsets:       []string{"default"},
+		DisableScopeFiltering: true,
+		Translator:            translations.NullTranslationHelper,
+		Logger:                slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, apiHost, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	newRequest := func(path string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(initialize))
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("opens a session", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest("/x/issues/readonly"))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Mcp-Session-Id"))
+	})
+
+	t.Run("unknown route is not found", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest("/x/unknown"))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("unknown tool is a bad request", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := newRequest("/")
+		req.Header.Set("X-MCP-Tools", "not_a_tool")
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "not_a_tool")
+	})
+}
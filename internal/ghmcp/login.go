@@ -0,0 +1,222 @@
+package ghmcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/auth"
+	"github.com/github/github-mcp-server/pkg/netconfig"
+	gogithub "github.com/google/go-github/v79/github"
+)
+
+type LoginConfig struct {
+	// Version of the server
+	Version string
+
+	// GitHub Host to log in to (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// OAuthClientID is the client ID of the OAuth App used for the device flow
+	OAuthClientID string
+
+	// Scopes are the OAuth scopes to request
+	Scopes []string
+
+	// CredentialsFile is the path of the credential store (empty for the default location)
+	CredentialsFile string
+
+	// Endpoints override the API URLs derived from Host
+	Endpoints APIEndpoints
+
+	// Network configures the connections to GitHub: CA certificates, proxy and client certificate
+	Network netconfig.Config
+}
+
+// RunLogin authorizes the server with the OAuth device flow and stores the resulting token,
+// which the stdio server then uses when no token is configured.
+func RunLogin(cfg LoginConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	transport, err := cfg.Network.Transport()
+	if err != nil {
+		return fmt.Errorf("failed to configure connections to GitHub: %w", err)
+	}
+	apiHost, err := parseAPIHost(cfg.Host, cfg.Endpoints, transport)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+	store, err := newCredentialStore(cfg.CredentialsFile)
+	if err != nil {
+		return err
+	}
+
+	flow := &auth.DeviceFlow{
+		BaseURL:    apiHost.webURL,
+		ClientID:   cfg.OAuthClientID,
+		Scopes:     cfg.Scopes,
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	code, err := flow.RequestCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "First copy your one-time code: %s\n", code.UserCode)
+	_, _ = fmt.Fprintf(os.Stderr, "Then open %s in your browser and enter the code.\n", code.VerificationURI)
+	_, _ = fmt.Fprintln(os.Stderr, "Waiting for authorization...")
+
+	token, err := flow.PollToken(ctx, code)
+	if err != nil {
+		return fmt.Errorf("failed to log in: %w", err)
+	}
+
+	user, _, err := getAuthenticatedUser(ctx, cfg.Version, apiHost, transport, token.Token)
+	if err != nil {
+		return err
+	}
+
+	err = store.Set(apiHost.webURL.Host, auth.Credential{
+		Token:     token.Token,
+		User:      user.GetLogin(),
+		Scopes:    token.Scopes,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Logged in to %s as %s\n", apiHost.webURL.Host, user.GetLogin())
+	return nil
+}
+
+type CredentialsConfig struct {
+	// Version of the server
+	Version string
+
+	// GitHub Host the credentials are for (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// CredentialsFile is the path of the credential store (empty for the default location)
+	CredentialsFile string
+
+	// Endpoints override the API URLs derived from Host
+	Endpoints APIEndpoints
+
+	// Network configures the connections to GitHub: CA certificates, proxy and client certificate
+	Network netconfig.Config
+}
+
+// RunLogout removes the stored token for the host. The token itself stays valid until it is
+// revoked in the GitHub settings of the OAuth App.
+func RunLogout(cfg CredentialsConfig) error {
+	transport, err := cfg.Network.Transport()
+	if err != nil {
+		return fmt.Errorf("failed to configure connections to GitHub: %w", err)
+	}
+	apiHost, err := parseAPIHost(cfg.Host, cfg.Endpoints, transport)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+	store, err := newCredentialStore(cfg.CredentialsFile)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := store.Delete(apiHost.webURL.Host)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		_, _ = fmt.Fprintf(os.Stdout, "Not logged in to %s\n", apiHost.webURL.Host)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "Logged out of %s\n", apiHost.webURL.Host)
+	return nil
+}
+
+// RunStatus reports whether a token is stored for the host and checks that it is still valid.
+func RunStatus(cfg CredentialsConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	transport, err := cfg.Network.Transport()
+	if err != nil {
+		return fmt.Errorf("failed to configure connections to GitHub: %w", err)
+	}
+	apiHost, err := parseAPIHost(cfg.Host, cfg.Endpoints, transport)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+	store, err := newCredentialStore(cfg.CredentialsFile)
+	if err != nil {
+		return err
+	}
+
+	host := apiHost.webURL.Host
+	cred, err := store.Get(host)
+	if err != nil {
+		return err
+	}
+	if cred == nil {
+		return fmt.Errorf("not logged in to %s, run `github-mcp-server login` to log in", host)
+	}
+
+	user, resp, err := getAuthenticatedUser(ctx, cfg.Version, apiHost, transport, cred.Token)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("the token stored for %s is no longer valid, run `github-mcp-server login` to log in again", host)
+		}
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "Logged in to %s as %s\n", host, user.GetLogin())
+	_, _ = fmt.Fprintf(os.Stdout, "Token scopes: %s\n", resp.Header.Get("X-OAuth-Scopes"))
+	_, _ = fmt.Fprintf(os.Stdout, "Credentials stored in %s\n", store.Path())
+	return nil
+}
+
+// storedToken returns the token stored by RunLogin for the host.
+func storedToken(credentialsFile string, apiHost apiHost) (string, error) {
+	store, err := newCredentialStore(credentialsFile)
+	if err != nil {
+		return "", err
+	}
+	cred, err := store.Get(apiHost.webURL.Host)
+	if err != nil {
+		return "", err
+	}
+	if cred == nil {
+		return "", fmt.Errorf("GITHUB_PERSONAL_ACCESS_TOKEN not set and not logged in to %s, run `github-mcp-server login` to log in", apiHost.webURL.Host)
+	}
+	return cred.Token, nil
+}
+
+func newCredentialStore(path string) (*auth.CredentialStore, error) {
+	if path == "" {
+		defaultPath, err := auth.DefaultCredentialStorePath()
+		if err != nil {
+			return nil, err
+		}
+		path = defaultPath
+	}
+	return auth.NewCredentialStore(path), nil
+}
+
+func getAuthenticatedUser(ctx context.Context, version string, apiHost apiHost, transport http.RoundTripper, token string) (*gogithub.User, *gogithub.Response, error) {
+	client := gogithub.NewClient(&http.Client{Transport: transport}).WithAuthToken(token)
+	client.UserAgent = fmt.Sprintf("github-mcp-server/%s", version)
+	client.BaseURL = apiHost.baseRESTURL
+
+	user, resp, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, resp, fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	return user, resp, nil
+}
@@ -0,0 +1,70 @@
+package ghmcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/auth"
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	gogithub "github.com/google/go-github/v79/github"
+)
+
+// removeUnusableTools removes the tools the token can't use from the toolset group, based on the
+// scopes of a classic token or the permissions of a GitHub App installation. Tokens that don't
+// report what they are granted, such as fine-grained personal access tokens, keep every tool.
+func removeUnusableTools(ctx context.Context, tsg *toolsets.ToolsetGroup, restClient *gogithub.Client, authTransport http.RoundTripper, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var unusable func(tool toolsets.ServerTool) (bool, string)
+	if installation, ok := authTransport.(*auth.InstallationTransport); ok {
+		permissions, err := installation.Permissions(ctx)
+		if err != nil {
+			logger.Warn("failed to get GitHub App permissions, keeping all tools", "error", err)
+			return
+		}
+		unusable = github.UnusableWithPermissions(permissions)
+	} else {
+		scopes, ok, err := tokenScopes(ctx, restClient)
+		if err != nil {
+			logger.Warn("failed to get token scopes, keeping all tools", "error", err)
+			return
+		}
+		if !ok {
+			logger.Info("token does not report its scopes, keeping all tools")
+			return
+		}
+		unusable = github.UnusableWithScopes(scopes)
+	}
+
+	removed := tsg.RemoveTools(unusable)
+	names := make([]string, 0, len(removed))
+	for name := range removed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		logger.Info("tool removed", "tool", name, "reason", removed[name])
+	}
+}
+
+// tokenScopes returns the OAuth scopes of the client's token, or false if the token doesn't report any,
+// which is the case for everything but classic tokens. The rate limit endpoint is used for the probe as
+// calling it doesn't count against the rate limit.
+func tokenScopes(ctx context.Context, client *gogithub.Client) ([]string, bool, error) {
+	_, resp, err := client.RateLimit.Get(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to probe token scopes: %w", err)
+	}
+
+	values := resp.Header.Values("X-OAuth-Scopes")
+	if len(values) == 0 {
+		return nil, false, nil
+	}
+	return github.ParseOAuthScopes(values[0]), true, nil
+}
@@ -0,0 +1,49 @@
+package ghmcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gogithub "github.com/google/go-github/v79/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TokenScopes(t *testing.T) {
+	tests := []struct {
+		name           string
+		header         []string
+		expectedScopes []string
+		expectedOK     bool
+	}{
+		{name: "classic token", header: []string{"repo, read:org"}, expectedScopes: []string{"repo", "read:org"}, expectedOK: true},
+		{name: "classic token without scopes", header: []string{""}, expectedScopes: nil, expectedOK: true},
+		{name: "token that does not report scopes", header: nil, expectedScopes: nil, expectedOK: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/rate_limit", r.URL.Path)
+				for _, v := range tc.header {
+					w.Header().Add("X-OAuth-Scopes", v)
+				}
+				_, _ = w.Write([]byte(`{"resources":{}}`))
+			}))
+			defer ts.Close()
+
+			client := gogithub.NewClient(nil)
+			baseURL, err := url.Parse(ts.URL + "/")
+			require.NoError(t, err)
+			client.BaseURL = baseURL
+
+			scopes, ok, err := tokenScopes(context.Background(), client)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedScopes, scopes)
+		})
+	}
+}
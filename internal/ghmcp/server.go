@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -13,15 +14,28 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/github/github-mcp-server/pkg/audit"
+	"github.com/github/github-mcp-server/pkg/auth"
+	"github.com/github/github-mcp-server/pkg/cassette"
+	"github.com/github/github-mcp-server/pkg/confirmation"
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	"github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/httpcache"
 	"github.com/github/github-mcp-server/pkg/lockdown"
 	mcplog "github.com/github/github-mcp-server/pkg/log"
+	"github.com/github/github-mcp-server/pkg/metrics"
+	"github.com/github/github-mcp-server/pkg/netconfig"
+	"github.com/github/github-mcp-server/pkg/ratelimit"
 	"github.com/github/github-mcp-server/pkg/raw"
+	"github.com/github/github-mcp-server/pkg/repopolicy"
+	"github.com/github/github-mcp-server/pkg/retry"
+	"github.com/github/github-mcp-server/pkg/tracing"
 	"github.com/github/github-mcp-server/pkg/translations"
 	gogithub "github.com/google/go-github/v79/github"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/shurcooL/githubv4"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type MCPServerConfig struct {
@@ -34,6 +48,9 @@ type MCPServerConfig struct {
 	// GitHub Token to authenticate with the GitHub API
 	Token string
 
+	// App is a GitHub App installation to authenticate as instead of using Token
+	App *auth.AppConfig
+
 	// EnabledToolsets is a list of toolsets to enable
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
 	EnabledToolsets []string
@@ -42,6 +59,10 @@ type MCPServerConfig struct {
 	// When specified, these tools are registered in addition to any specified toolset tools
 	EnabledTools []string
 
+	// ExcludedTools are names and glob patterns (e.g. delete_*) of tools that are never registered, whether
+	// through their toolset, by name or dynamically
+	ExcludedTools []string
+
 	// Whether to enable dynamic toolsets
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#dynamic-tool-discovery
 	DynamicToolsets bool
@@ -49,6 +70,9 @@ type MCPServerConfig struct {
 	// ReadOnly indicates if we should only offer read-only tools
 	ReadOnly bool
 
+	// DisableScopeFiltering keeps tools that the token lacks the scopes or permissions for
+	DisableScopeFiltering bool
+
 	// Translator provides translated text for the server tooling
 	Translator translations.TranslationHelperFunc
 
@@ -62,16 +86,105 @@ type MCPServerConfig struct {
 	Logger *slog.Logger
 	// RepoAccessTTL overrides the default TTL for repository access cache entries.
 	RepoAccessTTL *time.Duration
+
+	// RetryMutations also retries requests that may change data after a transient failure,
+	// at the risk of applying them twice
+	RetryMutations bool
+
+	// TracerProvider receives a span for every MCP method and GitHub API request (nil disables tracing)
+	TracerProvider trace.TracerProvider
+
+	// Metrics collects metrics about tool calls and GitHub API requests (nil disables metrics)
+	Metrics *metrics.Metrics
+
+	// Endpoints override the API URLs derived from Host
+	Endpoints APIEndpoints
+
+	// Transport sends the requests to GitHub, including the subdomain isolation probe of
+	// GitHub Enterprise Server hosts (nil for http.DefaultTransport)
+	Transport http.RoundTripper
+
+	// ResponseCache stores GitHub API responses for conditional requests (nil disables caching).
+	// It may be shared between servers, as responses are cached per token.
+	ResponseCache httpcache.Store
+
+	// AuditLog records every call to a write tool (nil disables auditing)
+	AuditLog *audit.Log
+
+	// RepoPolicy confines tool calls to the repositories it allows (nil allows all repositories)
+	RepoPolicy *repopolicy.Policy
+
+	// Confirmation asks the user to approve calls to the tools it selects (nil confirms no calls)
+	Confirmation *confirmation.Policy
+
+	// DryRun makes every call to a write tool a dry run, which returns the operations it would perform
+	// without changing anything. Single calls can be dry runs with the dry_run argument regardless.
+	DryRun bool
+
+	// ErrorMeta attaches the GitHub API errors of a tool call to the _meta of its result
+	ErrorMeta bool
 }
 
 func NewMCPServer(cfg MCPServerConfig) (*mcp.Server, error) {
-	apiHost, err := parseAPIHost(cfg.Host)
+	apiHost, err := parseAPIHost(cfg.Host, cfg.Endpoints, cfg.Transport)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse API host: %w", err)
 	}
 
+	return newMCPServer(context.Background(), cfg, apiHost)
+}
+
+// newMCPServer creates the MCP server for an already parsed API host, so that callers creating
+// many servers (e.g. one per HTTP session) only resolve the host once. The context is used for
+// the GitHub API requests made while setting up the server.
+func newMCPServer(ctx context.Context, cfg MCPServerConfig, apiHost apiHost) (*mcp.Server, error) {
+	// Every request sent to GitHub is counted and gets a span, including retries
+	var tracer trace.Tracer
+	baseTransport := cfg.Transport
+	if baseTransport == nil {
+		baseTransport = http.DefaultTransport
+	}
+	if cfg.Metrics != nil {
+		baseTransport = cfg.Metrics.Transport(baseTransport, apiHost.rawURL)
+	}
+	if cfg.TracerProvider != nil {
+		tracer = tracing.Tracer(cfg.TracerProvider)
+		baseTransport = tracing.NewTransport(baseTransport, tracer)
+	}
+
+	// All clients share one rate limit aware transport, so that they see each other's budget
+	rateLimiter := ratelimit.NewTransport(baseTransport, cfg.Logger.With("component", "ratelimit"))
+
+	// Transient failures are retried on top of the rate limiter, so that every attempt counts against the budget
+	retrier := retry.NewTransport(rateLimiter, cfg.Logger.With("component", "retry"))
+	retrier.RetryMutations = cfg.RetryMutations
+	if cfg.Metrics != nil {
+		retrier.OnRetry = cfg.Metrics.ObserveRetry
+	}
+
+	// Only GET requests are cached, which leaves the GraphQL client (all POST) to the retrier
+	var transport http.RoundTripper = retrier
+	if cfg.ResponseCache != nil {
+		cacheOpts := []httpcache.Option{
+			httpcache.WithLogger(cfg.Logger.With("component", "httpcache")),
+			httpcache.WithRawURL(apiHost.rawURL),
+		}
+		if cfg.Metrics != nil {
+			cacheOpts = append(cacheOpts, httpcache.WithObserver(cfg.Metrics.ObserveCacheResult))
+		}
+		transport = httpcache.NewTransport(retrier, cfg.ResponseCache, cacheOpts...)
+	}
+
+	authTransport, err := newAuthTransport(ctx, cfg, apiHost, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write tools plan the requests that change data in dry runs, this makes sure none of them is sent
+	clientTransport := dryrun.NewTransport(authTransport)
+
 	// Construct our REST client
-	restClient := gogithub.NewClient(nil).WithAuthToken(cfg.Token)
+	restClient := gogithub.NewClient(&http.Client{Transport: clientTransport})
 	restClient.UserAgent = fmt.Sprintf("github-mcp-server/%s", cfg.Version)
 	restClient.BaseURL = apiHost.baseRESTURL
 	restClient.UploadURL = apiHost.uploadURL
@@ -80,10 +193,7 @@ func NewMCPServer(cfg MCPServerConfig) (*mcp.Server, error) {
 	// We're using NewEnterpriseClient here unconditionally as opposed to NewClient because we already
 	// did the necessary API host parsing so that github.com will return the correct URL anyway.
 	gqlHTTPClient := &http.Client{
-		Transport: &bearerAuthTransport{
-			transport: http.DefaultTransport,
-			token:     cfg.Token,
-		},
+		Transport: clientTransport,
 	} // We're going to wrap the Transport later in beforeInit
 	gqlClient := githubv4.NewEnterpriseClient(apiHost.graphqlURL.String(), gqlHTTPClient)
 	repoAccessOpts := []lockdown.RepoAccessOption{}
@@ -96,27 +206,12 @@ func NewMCPServer(cfg MCPServerConfig) (*mcp.Server, error) {
 	var repoAccessCache *lockdown.RepoAccessCache
 	if cfg.LockdownMode {
 		repoAccessCache = lockdown.GetInstance(gqlClient, repoAccessOpts...)
+		if cfg.Metrics != nil {
+			cfg.Metrics.RegisterLockdownCache(repoAccessCache)
+		}
 	}
 
-	enabledToolsets := cfg.EnabledToolsets
-
-	// If dynamic toolsets are enabled, remove "all" from the enabled toolsets
-	if cfg.DynamicToolsets {
-		enabledToolsets = github.RemoveToolset(enabledToolsets, github.ToolsetMetadataAll.ID)
-	}
-
-	// Clean up the passed toolsets
-	enabledToolsets, invalidToolsets := github.CleanToolsets(enabledToolsets)
-
-	// If "all" is present, override all other toolsets
-	if github.ContainsToolset(enabledToolsets, github.ToolsetMetadataAll.ID) {
-		enabledToolsets = []string{github.ToolsetMetadataAll.ID}
-	}
-	// If "default" is present, expand to real toolset IDs
-	if github.ContainsToolset(enabledToolsets, github.ToolsetMetadataDefault.ID) {
-		enabledToolsets = github.AddDefaultToolset(enabledToolsets)
-	}
-
+	enabledToolsets, invalidToolsets := resolveToolsets(cfg.EnabledToolsets, cfg.DynamicToolsets)
 	if len(invalidToolsets) > 0 {
 		fmt.Fprintf(os.Stderr, "Invalid toolsets ignored: %s\n", strings.Join(invalidToolsets, ", "))
 	}
@@ -147,7 +242,8 @@ func NewMCPServer(cfg MCPServerConfig) (*mcp.Server, error) {
 	})
 
 	// Add middlewares
-	ghServer.AddReceivingMiddleware(addGitHubAPIErrorToContext)
+	ghServer.AddReceivingMiddleware(errors.Middleware(cfg.Logger.With("component", "errors"), cfg.ErrorMeta))
+	ghServer.AddReceivingMiddleware(rateLimiter.Middleware())
 	ghServer.AddReceivingMiddleware(addUserAgentsMiddleware(cfg, restClient, gqlHTTPClient))
 
 	// Create default toolsets
@@ -162,10 +258,57 @@ func NewMCPServer(cfg MCPServerConfig) (*mcp.Server, error) {
 		repoAccessCache,
 	)
 
+	if cfg.Confirmation != nil {
+		// Added before the repository policy, so that the user is not asked to approve denied calls
+		ghServer.AddReceivingMiddleware(cfg.Confirmation.Middleware(cfg.Logger.With("component", "confirmation")))
+	}
+	if cfg.RepoPolicy != nil {
+		// Added before the metrics and the audit log, so that they cover denied calls
+		ghServer.AddReceivingMiddleware(cfg.RepoPolicy.Middleware(func(tool string) bool {
+			serverTool, _, err := tsg.FindToolByName(tool)
+			return err == nil && !serverTool.Tool.Annotations.ReadOnlyHint
+		}, cfg.Logger.With("component", "repopolicy")))
+	}
+	if cfg.Metrics != nil {
+		ghServer.AddReceivingMiddleware(cfg.Metrics.Middleware(func(tool string) (string, bool) {
+			_, toolset, err := tsg.FindToolByName(tool)
+			return toolset, err == nil
+		}))
+	}
+	if cfg.AuditLog != nil {
+		ghServer.AddReceivingMiddleware(cfg.AuditLog.Middleware(func(tool string) bool {
+			serverTool, _, err := tsg.FindToolByName(tool)
+			return err == nil && !serverTool.Tool.Annotations.ReadOnlyHint
+		}))
+	}
+	// Added after the audit log and the confirmations, so that they can leave out dry runs
+	ghServer.AddReceivingMiddleware(dryrun.Middleware(cfg.DryRun, func(tool string) (*mcp.Tool, bool) {
+		serverTool, _, err := tsg.FindToolByName(tool)
+		if err != nil {
+			return nil, false
+		}
+		return &serverTool.Tool, true
+	}))
+	if tracer != nil {
+		// Added last to be the outermost middleware, so that the span covers the whole method
+		ghServer.AddReceivingMiddleware(tracing.Middleware(tracer))
+	}
+
+	// Leave out the tools the token can't use, so that calls don't fail with opaque 403 or 404 errors
+	if !cfg.DisableScopeFiltering {
+		removeUnusableTools(ctx, tsg, restClient, authTransport, cfg.Logger)
+	}
+
+	if len(cfg.ExcludedTools) > 0 {
+		if err := excludeTools(tsg, cfg.ExcludedTools, cfg.Logger); err != nil {
+			return nil, err
+		}
+	}
+
 	// Enable and register toolsets if configured
 	// This always happens if toolsets are specified, regardless of whether tools are also specified
 	if len(enabledToolsets) > 0 {
-		err = tsg.EnableToolsets(enabledToolsets, nil)
+		err := tsg.EnableToolsets(enabledToolsets, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to enable toolsets: %w", err)
 		}
@@ -180,7 +323,7 @@ func NewMCPServer(cfg MCPServerConfig) (*mcp.Server, error) {
 		enabledTools := github.CleanTools(cfg.EnabledTools)
 
 		// Register the specified tools (additive to any toolsets already enabled)
-		err = tsg.RegisterSpecificTools(ghServer, enabledTools, cfg.ReadOnly)
+		err := tsg.RegisterSpecificTools(ghServer, enabledTools, cfg.ReadOnly)
 		if err != nil {
 			return nil, fmt.Errorf("failed to register tools: %w", err)
 		}
@@ -189,12 +332,61 @@ func NewMCPServer(cfg MCPServerConfig) (*mcp.Server, error) {
 	// Register dynamic toolsets if configured (additive to toolsets and tools)
 	if cfg.DynamicToolsets {
 		dynamic := github.InitDynamicToolset(ghServer, tsg, cfg.Translator)
-		dynamic.RegisterTools(ghServer)
+		tsg.RegisterToolsetTools(ghServer, dynamic)
 	}
 
 	return ghServer, nil
 }
 
+// resolveToolsets cleans up the configured toolsets and expands the "all" and "default" keywords. It
+// returns the toolsets to enable and the unknown ones, which are ignored.
+func resolveToolsets(toolsets []string, dynamic bool) ([]string, []string) {
+	// If dynamic toolsets are enabled, remove "all" from the enabled toolsets
+	if dynamic {
+		toolsets = github.RemoveToolset(toolsets, github.ToolsetMetadataAll.ID)
+	}
+
+	// Clean up the passed toolsets
+	enabledToolsets, invalidToolsets := github.CleanToolsets(toolsets)
+
+	// If "all" is present, override all other toolsets
+	if github.ContainsToolset(enabledToolsets, github.ToolsetMetadataAll.ID) {
+		enabledToolsets = []string{github.ToolsetMetadataAll.ID}
+	}
+	// If "default" is present, expand to real toolset IDs
+	if github.ContainsToolset(enabledToolsets, github.ToolsetMetadataDefault.ID) {
+		enabledToolsets = github.AddDefaultToolset(enabledToolsets)
+	}
+	return enabledToolsets, invalidToolsets
+}
+
+// newAuthTransport returns the transport that authenticates GitHub API requests, shared by all clients.
+// GitHub App installation tokens are minted and refreshed on demand. Otherwise the static token is used,
+// unless the request context carries its own token (e.g. from an HTTP Authorization header); this is why
+// the REST client doesn't use WithAuthToken. Authenticated requests are sent through transport.
+func newAuthTransport(ctx context.Context, cfg MCPServerConfig, apiHost apiHost, transport http.RoundTripper) (http.RoundTripper, error) {
+	if cfg.App == nil {
+		return &bearerAuthTransport{
+			transport: transport,
+			token:     cfg.Token,
+		}, nil
+	}
+
+	signer := auth.NewAppJWTSigner(cfg.App.AppID, cfg.App.PrivateKey)
+	installationID := cfg.App.InstallationID
+	if installationID == 0 {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		id, err := auth.FindInstallationID(ctx, transport, apiHost.baseRESTURL, signer, cfg.App.InstallationOwner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find GitHub App installation: %w", err)
+		}
+		installationID = id
+	}
+
+	return auth.NewInstallationTransport(transport, apiHost.baseRESTURL, signer, installationID), nil
+}
+
 type StdioServerConfig struct {
 	// Version of the server
 	Version string
@@ -205,6 +397,22 @@ type StdioServerConfig struct {
 	// GitHub Token to authenticate with the GitHub API
 	Token string
 
+	// AppID is the ID of a GitHub App to authenticate as instead of using Token
+	AppID string
+
+	// AppPrivateKeyFile is the path to the PEM encoded private key of the GitHub App
+	AppPrivateKeyFile string
+
+	// AppInstallationID is the GitHub App installation to authenticate as
+	AppInstallationID int64
+
+	// AppInstallationOwner is the account whose GitHub App installation is used when AppInstallationID is not set
+	AppInstallationOwner string
+
+	// CredentialsFile is the credential store used when neither Token nor AppID is set
+	// (empty for the default location). See RunLogin.
+	CredentialsFile string
+
 	// EnabledToolsets is a list of toolsets to enable
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
 	EnabledToolsets []string
@@ -213,6 +421,10 @@ type StdioServerConfig struct {
 	// When specified, these tools are registered in addition to any specified toolset tools
 	EnabledTools []string
 
+	// ExcludedTools are names and glob patterns (e.g. delete_*) of tools that are never registered, whether
+	// through their toolset, by name or dynamically
+	ExcludedTools []string
+
 	// Whether to enable dynamic toolsets
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#dynamic-tool-discovery
 	DynamicToolsets bool
@@ -220,16 +432,28 @@ type StdioServerConfig struct {
 	// ReadOnly indicates if we should only register read-only tools
 	ReadOnly bool
 
+	// DisableScopeFiltering keeps tools that the token lacks the scopes or permissions for
+	DisableScopeFiltering bool
+
 	// ExportTranslations indicates if we should export translations
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#i18n--overriding-descriptions
 	ExportTranslations bool
 
+	// TranslationOverrides override tool descriptions and other texts by translation key
+	TranslationOverrides map[string]string
+
 	// EnableCommandLogging indicates if we should log commands
 	EnableCommandLogging bool
 
 	// Path to the log file if not stderr
 	LogFilePath string
 
+	// LogFormat is the format of the logs, "text" or "json"
+	LogFormat string
+
+	// LogLevel is the minimum level of the logs (empty for debug in the log file and info on stderr)
+	LogLevel string
+
 	// Content window size
 	ContentWindowSize int
 
@@ -238,6 +462,59 @@ type StdioServerConfig struct {
 
 	// RepoAccessCacheTTL overrides the default TTL for repository access cache entries.
 	RepoAccessCacheTTL *time.Duration
+
+	// ResponseCacheSize is the memory used for caching GitHub API responses, in bytes (0 disables the memory cache)
+	ResponseCacheSize int64
+
+	// ResponseCacheDir is a directory to also cache GitHub API responses in, kept across restarts (empty for none)
+	ResponseCacheDir string
+
+	// RetryMutations also retries requests that may change data after a transient failure
+	RetryMutations bool
+
+	// OTLPEndpoint is the URL of an OpenTelemetry collector to export traces to over OTLP/HTTP (empty disables tracing)
+	OTLPEndpoint string
+
+	// MetricsAddress is the address to serve Prometheus metrics on at /metrics (empty disables metrics)
+	MetricsAddress string
+
+	// Endpoints override the API URLs derived from Host
+	Endpoints APIEndpoints
+
+	// Network configures the connections to GitHub: CA certificates, proxy and client certificate
+	Network netconfig.Config
+
+	// RecordDir is a directory to record the GitHub API traffic to, as cassettes (empty disables recording)
+	RecordDir string
+
+	// ReplayDir is a directory of cassettes to answer GitHub API requests from instead of GitHub (empty for none)
+	ReplayDir string
+
+	// AuditLogPath is the file to record every call to a write tool in, as JSON lines (empty disables auditing)
+	AuditLogPath string
+
+	// AuditLogMaxSize is the size in bytes at which the audit log is rotated (0 never rotates it)
+	AuditLogMaxSize int64
+
+	// AuditLogMaxBackups is the number of rotated audit logs to keep
+	AuditLogMaxBackups int
+
+	// ErrorMeta attaches the GitHub API errors of a tool call to the _meta of its result
+	ErrorMeta bool
+
+	// AllowedRepos are the owner/repo glob patterns of the repositories tools can access, optionally
+	// read-only (empty allows all repositories that are not denied)
+	AllowedRepos []string
+
+	// DeniedRepos are the owner/repo glob patterns of the repositories tools can't access
+	DeniedRepos []string
+
+	// ConfirmTools are the names, glob patterns and tool:method patterns of the tools whose calls the user
+	// must approve
+	ConfirmTools []string
+
+	// DryRun makes every call to a write tool a dry run
+	DryRun bool
 }
 
 // RunStdioServer is not concurrent safe.
@@ -246,38 +523,94 @@ func RunStdioServer(cfg StdioServerConfig) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	t, dumpTranslations := translations.TranslationHelper()
+	t, dumpTranslations := translations.TranslationHelperWithOverrides(cfg.TranslationOverrides)
 
-	var slogHandler slog.Handler
-	var logOutput io.Writer
-	if cfg.LogFilePath != "" {
-		file, err := os.OpenFile(cfg.LogFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	logger, err := newLogger(cfg.LogFilePath, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	logger.Info("starting server", "version", cfg.Version, "host", cfg.Host, "dynamicToolsets", cfg.DynamicToolsets, "readOnly", cfg.ReadOnly, "lockdownEnabled", cfg.LockdownMode, "dryRun", cfg.DryRun)
+
+	appConfig, err := stdioAppConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	transport, err := newGitHubTransport(cfg.Network, cfg.RecordDir, cfg.ReplayDir, logger)
+	if err != nil {
+		return err
+	}
+
+	apiHost, err := parseAPIHost(cfg.Host, cfg.Endpoints, transport)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+
+	tracerProvider, shutdownTracing, err := newTracerProvider(ctx, cfg.OTLPEndpoint, cfg.Version)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+
+	serverMetrics, err := startMetricsServer(ctx, cfg.MetricsAddress, logger)
+	if err != nil {
+		return err
+	}
+
+	auditLog, err := openAuditLog(cfg.AuditLogPath, cfg.AuditLogMaxSize, cfg.AuditLogMaxBackups, logger)
+	if err != nil {
+		return err
+	}
+	if auditLog != nil {
+		defer func() { _ = auditLog.Close() }()
+	}
+
+	repoPolicy, err := newRepoPolicy(cfg.AllowedRepos, cfg.DeniedRepos, logger)
+	if err != nil {
+		return err
+	}
+	confirmationPolicy, err := newConfirmationPolicy(cfg.ConfirmTools, logger)
+	if err != nil {
+		return err
+	}
+
+	token := cfg.Token
+	if token == "" && appConfig == nil && cfg.ReplayDir == "" {
+		token, err = storedToken(cfg.CredentialsFile, apiHost)
 		if err != nil {
-			return fmt.Errorf("failed to open log file: %w", err)
+			return err
 		}
-		logOutput = file
-		slogHandler = slog.NewTextHandler(logOutput, &slog.HandlerOptions{Level: slog.LevelDebug})
-	} else {
-		logOutput = os.Stderr
-		slogHandler = slog.NewTextHandler(logOutput, &slog.HandlerOptions{Level: slog.LevelInfo})
-	}
-	logger := slog.New(slogHandler)
-	logger.Info("starting server", "version", cfg.Version, "host", cfg.Host, "dynamicToolsets", cfg.DynamicToolsets, "readOnly", cfg.ReadOnly, "lockdownEnabled", cfg.LockdownMode)
-
-	ghServer, err := NewMCPServer(MCPServerConfig{
-		Version:           cfg.Version,
-		Host:              cfg.Host,
-		Token:             cfg.Token,
-		EnabledToolsets:   cfg.EnabledToolsets,
-		EnabledTools:      cfg.EnabledTools,
-		DynamicToolsets:   cfg.DynamicToolsets,
-		ReadOnly:          cfg.ReadOnly,
-		Translator:        t,
-		ContentWindowSize: cfg.ContentWindowSize,
-		LockdownMode:      cfg.LockdownMode,
-		Logger:            logger,
-		RepoAccessTTL:     cfg.RepoAccessCacheTTL,
-	})
+		logger.Info("using stored credentials", "host", apiHost.webURL.Host)
+	}
+
+	ghServer, err := newMCPServer(ctx, MCPServerConfig{
+		Version:               cfg.Version,
+		Host:                  cfg.Host,
+		Endpoints:             cfg.Endpoints,
+		Token:                 token,
+		App:                   appConfig,
+		EnabledToolsets:       cfg.EnabledToolsets,
+		EnabledTools:          cfg.EnabledTools,
+		ExcludedTools:         cfg.ExcludedTools,
+		DynamicToolsets:       cfg.DynamicToolsets,
+		ReadOnly:              cfg.ReadOnly,
+		DisableScopeFiltering: cfg.DisableScopeFiltering,
+		Translator:            t,
+		ContentWindowSize:     cfg.ContentWindowSize,
+		LockdownMode:          cfg.LockdownMode,
+		Logger:                logger,
+		RepoAccessTTL:         cfg.RepoAccessCacheTTL,
+		ResponseCache:         newResponseCache(cfg.ResponseCacheSize, cfg.ResponseCacheDir),
+		RetryMutations:        cfg.RetryMutations,
+		TracerProvider:        tracerProvider,
+		Metrics:               serverMetrics,
+		Transport:             transport,
+		AuditLog:              auditLog,
+		RepoPolicy:            repoPolicy,
+		Confirmation:          confirmationPolicy,
+		DryRun:                cfg.DryRun,
+		ErrorMeta:             cfg.ErrorMeta,
+	}, apiHost)
 	if err != nil {
 		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
@@ -323,11 +656,223 @@ func RunStdioServer(cfg StdioServerConfig) error {
 	return nil
 }
 
+// stdioAppConfig builds the GitHub App configuration from the stdio config, returning nil if no App is configured.
+func stdioAppConfig(cfg StdioServerConfig) (*auth.AppConfig, error) {
+	if cfg.AppID == "" {
+		return nil, nil
+	}
+	if cfg.AppPrivateKeyFile == "" {
+		return nil, fmt.Errorf("a private key file is required for GitHub App authentication")
+	}
+	if cfg.AppInstallationID == 0 && cfg.AppInstallationOwner == "" {
+		return nil, fmt.Errorf("either an installation ID or an installation owner is required for GitHub App authentication")
+	}
+
+	privateKey, err := auth.LoadPrivateKeyFile(cfg.AppPrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GitHub App private key: %w", err)
+	}
+
+	return &auth.AppConfig{
+		AppID:             cfg.AppID,
+		PrivateKey:        privateKey,
+		InstallationID:    cfg.AppInstallationID,
+		InstallationOwner: cfg.AppInstallationOwner,
+	}, nil
+}
+
+// newTracerProvider creates the tracer provider exporting to the OTLP endpoint, or returns nil if no
+// endpoint is given. The returned function flushes and stops the exporter.
+func newTracerProvider(ctx context.Context, endpoint, version string) (trace.TracerProvider, func(), error) {
+	if endpoint == "" {
+		return nil, func() {}, nil
+	}
+
+	provider, err := tracing.NewProvider(ctx, endpoint, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return provider, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = provider.Shutdown(ctx)
+	}, nil
+}
+
+// startMetricsServer serves Prometheus metrics on the address until ctx is done, and returns the
+// metrics to collect. It returns nil if no address is given.
+func startMetricsServer(ctx context.Context, address string, logger *slog.Logger) (*metrics.Metrics, error) {
+	if address == "" {
+		return nil, nil
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics: %w", err)
+	}
+
+	serverMetrics := metrics.New()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", serverMetrics.Handler())
+	metricsServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := metricsServer.Serve(listener); err != http.ErrServerClosed {
+			logger.Error("error serving metrics", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = metricsServer.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("serving metrics", "address", listener.Addr().String())
+	return serverMetrics, nil
+}
+
+// newResponseCache creates the store for cached GitHub API responses, keeping them in memory
+// and, if a directory is given, on disk. It returns nil if caching is disabled.
+func newResponseCache(size int64, dir string) httpcache.Store {
+	var stores []httpcache.Store
+	if size > 0 {
+		stores = append(stores, httpcache.NewMemoryStore(size))
+	}
+	if dir != "" {
+		stores = append(stores, httpcache.NewDiskStore(dir))
+	}
+
+	switch len(stores) {
+	case 0:
+		return nil
+	case 1:
+		return stores[0]
+	default:
+		return httpcache.NewTieredStore(stores...)
+	}
+}
+
+// newGitHubTransport creates the transport all requests to GitHub are sent through. When replaying,
+// requests are answered from the cassettes in replayDir. Otherwise they are sent over the network
+// as configured, and recorded to recordDir if one is given.
+func newGitHubTransport(network netconfig.Config, recordDir, replayDir string, logger *slog.Logger) (http.RoundTripper, error) {
+	if recordDir != "" && replayDir != "" {
+		return nil, fmt.Errorf("cannot record and replay at the same time")
+	}
+	if replayDir != "" {
+		player, err := cassette.NewPlayer(replayDir)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("replaying GitHub API traffic", "dir", replayDir)
+		return player, nil
+	}
+
+	transport, err := network.Transport()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure connections to GitHub: %w", err)
+	}
+	if recordDir == "" {
+		return transport, nil
+	}
+	recorder, err := cassette.NewRecorder(transport, recordDir)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("recording GitHub API traffic", "dir", recordDir)
+	return recorder, nil
+}
+
+// openAuditLog opens the audit log at path, or returns nil if no path is given.
+func openAuditLog(path string, maxSize int64, maxBackups int, logger *slog.Logger) (*audit.Log, error) {
+	if path == "" {
+		return nil, nil
+	}
+	auditLog, err := audit.Open(path,
+		audit.WithMaxSize(maxSize),
+		audit.WithMaxBackups(maxBackups),
+		audit.WithLogger(logger.With("component", "audit")))
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("auditing write tool calls", "path", path)
+	return auditLog, nil
+}
+
+// newRepoPolicy parses the repository policy, or returns nil if no patterns are given.
+func newRepoPolicy(allow, deny []string, logger *slog.Logger) (*repopolicy.Policy, error) {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	policy, err := repopolicy.New(allow, deny)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("enforcing repository policy", "allow", allow, "deny", deny)
+	return policy, nil
+}
+
+// newConfirmationPolicy parses the patterns of the tools to confirm, or returns nil if none are given.
+func newConfirmationPolicy(patterns []string, logger *slog.Logger) (*confirmation.Policy, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	policy, err := confirmation.New(patterns)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("confirming tool calls", "tools", patterns)
+	return policy, nil
+}
+
+// newLogger creates the server logger, writing to the log file if a path is given and to stderr
+// otherwise. The format is "text" or "json". Without a level, the log file gets debug logs and
+// stderr info logs. Secrets are redacted from every record.
+func newLogger(logFilePath, format, level string) (*slog.Logger, error) {
+	var logOutput io.Writer
+	logLevel := slog.LevelInfo
+	if logFilePath != "" {
+		file, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		logOutput = file
+		logLevel = slog.LevelDebug
+	} else {
+		logOutput = os.Stderr
+	}
+	if level != "" {
+		if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+		}
+	}
+
+	var slogHandler slog.Handler
+	opts := &slog.HandlerOptions{Level: logLevel}
+	switch format {
+	case "", "text":
+		slogHandler = slog.NewTextHandler(logOutput, opts)
+	case "json":
+		slogHandler = slog.NewJSONHandler(logOutput, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected \"text\" or \"json\"", format)
+	}
+	return slog.New(mcplog.NewRedactingHandler(slogHandler)), nil
+}
+
 type apiHost struct {
 	baseRESTURL *url.URL
 	graphqlURL  *url.URL
 	uploadURL   *url.URL
 	rawURL      *url.URL
+	webURL      *url.URL
+	// subdomainIsolation tells whether a GitHub Enterprise Server host serves uploads and raw content from
+	// subdomains, or is nil if the host wasn't probed for it
+	subdomainIsolation *bool
 }
 
 func newDotcomHost() (apiHost, error) {
@@ -351,11 +896,17 @@ func newDotcomHost() (apiHost, error) {
 		return apiHost{}, fmt.Errorf("failed to parse dotcom Raw URL: %w", err)
 	}
 
+	webURL, err := url.Parse("https://github.com/")
+	if err != nil {
+		return apiHost{}, fmt.Errorf("failed to parse dotcom Web URL: %w", err)
+	}
+
 	return apiHost{
 		baseRESTURL: baseRestURL,
 		graphqlURL:  gqlURL,
 		uploadURL:   uploadURL,
 		rawURL:      rawURL,
+		webURL:      webURL,
 	}, nil
 }
 
@@ -370,61 +921,75 @@ func newGHECHost(hostname string) (apiHost, error) {
 		return apiHost{}, fmt.Errorf("GHEC URL must be HTTPS")
 	}
 
-	restURL, err := url.Parse(fmt.Sprintf("https://api.%s/", u.Hostname()))
+	restURL, err := url.Parse(fmt.Sprintf("https://api.%s/", u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHEC REST URL: %w", err)
 	}
 
-	gqlURL, err := url.Parse(fmt.Sprintf("https://api.%s/graphql", u.Hostname()))
+	gqlURL, err := url.Parse(fmt.Sprintf("https://api.%s/graphql", u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHEC GraphQL URL: %w", err)
 	}
 
-	uploadURL, err := url.Parse(fmt.Sprintf("https://uploads.%s", u.Hostname()))
+	uploadURL, err := url.Parse(fmt.Sprintf("https://uploads.%s", u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHEC Upload URL: %w", err)
 	}
 
-	rawURL, err := url.Parse(fmt.Sprintf("https://raw.%s/", u.Hostname()))
+	rawURL, err := url.Parse(fmt.Sprintf("https://raw.%s/", u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHEC Raw URL: %w", err)
 	}
 
+	webURL, err := url.Parse(fmt.Sprintf("https://%s/", u.Host))
+	if err != nil {
+		return apiHost{}, fmt.Errorf("failed to parse GHEC Web URL: %w", err)
+	}
+
 	return apiHost{
 		baseRESTURL: restURL,
 		graphqlURL:  gqlURL,
 		uploadURL:   uploadURL,
 		rawURL:      rawURL,
+		webURL:      webURL,
 	}, nil
 }
 
-func newGHESHost(hostname string) (apiHost, error) {
+// newGHESHost derives the URLs of a GitHub Enterprise Server host. Where uploads and raw content are
+// served depends on whether subdomain isolation is enabled, which is probed through transport unless
+// endpoints give both URLs.
+func newGHESHost(hostname string, endpoints APIEndpoints, transport http.RoundTripper) (apiHost, error) {
 	u, err := url.Parse(hostname)
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES URL: %w", err)
 	}
 
-	restURL, err := url.Parse(fmt.Sprintf("%s://%s/api/v3/", u.Scheme, u.Hostname()))
+	restURL, err := url.Parse(fmt.Sprintf("%s://%s/api/v3/", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES REST URL: %w", err)
 	}
 
-	gqlURL, err := url.Parse(fmt.Sprintf("%s://%s/api/graphql", u.Scheme, u.Hostname()))
+	gqlURL, err := url.Parse(fmt.Sprintf("%s://%s/api/graphql", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES GraphQL URL: %w", err)
 	}
 
 	// Check if subdomain isolation is enabled
 	// See https://docs.github.com/en/enterprise-server@3.17/admin/configuring-settings/hardening-security-for-your-enterprise/enabling-subdomain-isolation#about-subdomain-isolation
-	hasSubdomainIsolation := checkSubdomainIsolation(u.Scheme, u.Hostname())
+	hasSubdomainIsolation := false
+	var probed *bool
+	if endpoints.UploadURL == "" || endpoints.RawURL == "" {
+		hasSubdomainIsolation = checkSubdomainIsolation(u.Scheme, u.Host, transport)
+		probed = &hasSubdomainIsolation
+	}
 
 	var uploadURL *url.URL
 	if hasSubdomainIsolation {
 		// With subdomain isolation: https://uploads.hostname/
-		uploadURL, err = url.Parse(fmt.Sprintf("%s://uploads.%s/", u.Scheme, u.Hostname()))
+		uploadURL, err = url.Parse(fmt.Sprintf("%s://uploads.%s/", u.Scheme, u.Host))
 	} else {
 		// Without subdomain isolation: https://hostname/api/uploads/
-		uploadURL, err = url.Parse(fmt.Sprintf("%s://%s/api/uploads/", u.Scheme, u.Hostname()))
+		uploadURL, err = url.Parse(fmt.Sprintf("%s://%s/api/uploads/", u.Scheme, u.Host))
 	}
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES Upload URL: %w", err)
@@ -433,30 +998,39 @@ func newGHESHost(hostname string) (apiHost, error) {
 	var rawURL *url.URL
 	if hasSubdomainIsolation {
 		// With subdomain isolation: https://raw.hostname/
-		rawURL, err = url.Parse(fmt.Sprintf("%s://raw.%s/", u.Scheme, u.Hostname()))
+		rawURL, err = url.Parse(fmt.Sprintf("%s://raw.%s/", u.Scheme, u.Host))
 	} else {
 		// Without subdomain isolation: https://hostname/raw/
-		rawURL, err = url.Parse(fmt.Sprintf("%s://%s/raw/", u.Scheme, u.Hostname()))
+		rawURL, err = url.Parse(fmt.Sprintf("%s://%s/raw/", u.Scheme, u.Host))
 	}
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES Raw URL: %w", err)
 	}
 
+	webURL, err := url.Parse(fmt.Sprintf("%s://%s/", u.Scheme, u.Host))
+	if err != nil {
+		return apiHost{}, fmt.Errorf("failed to parse GHES Web URL: %w", err)
+	}
+
 	return apiHost{
-		baseRESTURL: restURL,
-		graphqlURL:  gqlURL,
-		uploadURL:   uploadURL,
-		rawURL:      rawURL,
+		baseRESTURL:        restURL,
+		graphqlURL:         gqlURL,
+		uploadURL:          uploadURL,
+		rawURL:             rawURL,
+		webURL:             webURL,
+		subdomainIsolation: probed,
 	}, nil
 }
 
 // checkSubdomainIsolation detects if GitHub Enterprise Server has subdomain isolation enabled
 // by attempting to ping the raw.<host>/_ping endpoint on the subdomain. The raw subdomain must always exist for subdomain isolation.
-func checkSubdomainIsolation(scheme, hostname string) bool {
-	subdomainURL := fmt.Sprintf("%s://raw.%s/_ping", scheme, hostname)
+// The request is sent through transport (nil for http.DefaultTransport).
+func checkSubdomainIsolation(scheme, host string, transport http.RoundTripper) bool {
+	subdomainURL := fmt.Sprintf("%s://raw.%s/_ping", scheme, host)
 
 	client := &http.Client{
-		Timeout: 5 * time.Second,
+		Transport: transport,
+		Timeout:   5 * time.Second,
 		// Don't follow redirects - we just want to check if the endpoint exists
 		//nolint:revive // parameters are required by http.Client.CheckRedirect signature
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
@@ -473,8 +1047,59 @@ func checkSubdomainIsolation(scheme, hostname string) bool {
 	return resp.StatusCode == http.StatusOK
 }
 
-// Note that this does not handle ports yet, so development environments are out.
-func parseAPIHost(s string) (apiHost, error) {
+// APIEndpoints override the URLs derived from the GitHub host, e.g. to point the server at a local
+// stand-in or a staging appliance. Each URL may have a port and use plain HTTP. Empty fields keep the
+// derived URL.
+type APIEndpoints struct {
+	// RESTURL is the base URL of the REST API (e.g. https://ghes.example.com/api/v3/)
+	RESTURL string
+	// GraphQLURL is the URL of the GraphQL API (e.g. https://ghes.example.com/api/graphql)
+	GraphQLURL string
+	// UploadURL is the base URL for uploads (e.g. https://ghes.example.com/api/uploads/)
+	UploadURL string
+	// RawURL is the base URL of raw file contents (e.g. https://ghes.example.com/raw/)
+	RawURL string
+}
+
+// parseAPIHost derives the API URLs of the GitHub host, the empty string meaning github.com, and
+// applies the endpoint overrides. The host may have a port. GitHub Enterprise Server hosts are probed
+// for subdomain isolation through transport (nil for http.DefaultTransport), unless endpoints give the
+// URLs that depend on it.
+func parseAPIHost(s string, endpoints APIEndpoints, transport http.RoundTripper) (apiHost, error) {
+	host, err := newAPIHost(s, endpoints, transport)
+	if err != nil {
+		return apiHost{}, err
+	}
+
+	overrides := []struct {
+		name     string
+		value    string
+		target   **url.URL
+		basePath bool
+	}{
+		{name: "REST", value: endpoints.RESTURL, target: &host.baseRESTURL, basePath: true},
+		{name: "GraphQL", value: endpoints.GraphQLURL, target: &host.graphqlURL},
+		{name: "upload", value: endpoints.UploadURL, target: &host.uploadURL, basePath: true},
+		{name: "raw", value: endpoints.RawURL, target: &host.rawURL, basePath: true},
+	}
+	for _, override := range overrides {
+		if override.value == "" {
+			continue
+		}
+		u, err := url.Parse(override.value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return apiHost{}, fmt.Errorf("%s URL must be an absolute http or https URL: %s", override.name, override.value)
+		}
+		// Base URLs need a trailing slash for relative paths to resolve under them
+		if override.basePath && !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		*override.target = u
+	}
+	return host, nil
+}
+
+func newAPIHost(s string, endpoints APIEndpoints, transport http.RoundTripper) (apiHost, error) {
 	if s == "" {
 		return newDotcomHost()
 	}
@@ -496,7 +1121,7 @@ func parseAPIHost(s string) (apiHost, error) {
 		return newGHECHost(s)
 	}
 
-	return newGHESHost(s)
+	return newGHESHost(s, endpoints, transport)
 }
 
 type userAgentTransport struct {
@@ -516,18 +1141,29 @@ type bearerAuthTransport struct {
 }
 
 func (t *bearerAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := t.token
+	// A per-request token (e.g. from the Authorization header of an HTTP session) wins over the static one
+	if ctxToken, ok := tokenFromContext(req.Context()); ok {
+		token = ctxToken
+	}
 	req = req.Clone(req.Context())
-	req.Header.Set("Authorization", "Bearer "+t.token)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 	return t.transport.RoundTrip(req)
 }
 
-func addGitHubAPIErrorToContext(next mcp.MethodHandler) mcp.MethodHandler {
-	return func(ctx context.Context, method string, req mcp.Request) (result mcp.Result, err error) {
-		// Ensure the context is cleared of any previous errors
-		// as context isn't propagated through middleware
-		ctx = errors.ContextWithGitHubErrors(ctx)
-		return next(ctx, method, req)
-	}
+type tokenContextKey struct{}
+
+// contextWithToken returns a copy of ctx carrying the GitHub token to use for outbound requests.
+func contextWithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// tokenFromContext returns the GitHub token carried by ctx, if any.
+func tokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(tokenContextKey{}).(string)
+	return token, ok && token != ""
 }
 
 func addUserAgentsMiddleware(cfg MCPServerConfig, restClient *gogithub.Client, gqlHTTPClient *http.Client) func(next mcp.MethodHandler) mcp.MethodHandler {
@@ -0,0 +1,124 @@
+package ghmcp
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// probeTransport answers the subdomain isolation probe and records the URLs it was sent to.
+type probeTransport struct {
+	isolated bool
+	probed   []string
+}
+
+func (t *probeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.probed = append(t.probed, req.URL.String())
+	if !t.isolated {
+		return nil, errors.New("no such host")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func Test_ParseAPIHost(t *testing.T) {
+	tests := []struct {
+		name           string
+		host           string
+		endpoints      APIEndpoints
+		isolated       bool
+		expectedProbe  []string
+		expectedREST   string
+		expectedGQL    string
+		expectedUpload string
+		expectedRaw    string
+	}{
+		{
+			name:           "github.com",
+			expectedREST:   "https://api.github.com/",
+			expectedGQL:    "https://api.github.com/graphql",
+			expectedUpload: "https://uploads.github.com",
+			expectedRaw:    "https://raw.githubusercontent.com/",
+		},
+		{
+			name:           "ghe.com",
+			host:           "https://octocorp.ghe.com",
+			expectedREST:   "https://api.octocorp.ghe.com/",
+			expectedGQL:    "https://api.octocorp.ghe.com/graphql",
+			expectedUpload: "https://uploads.octocorp.ghe.com",
+			expectedRaw:    "https://raw.octocorp.ghe.com/",
+		},
+		{
+			name:           "GHES with a port",
+			host:           "https://ghes.example.com:8443",
+			expectedProbe:  []string{"https://raw.ghes.example.com:8443/_ping"},
+			expectedREST:   "https://ghes.example.com:8443/api/v3/",
+			expectedGQL:    "https://ghes.example.com:8443/api/graphql",
+			expectedUpload: "https://ghes.example.com:8443/api/uploads/",
+			expectedRaw:    "https://ghes.example.com:8443/raw/",
+		},
+		{
+			name:           "GHES with subdomain isolation",
+			host:           "https://ghes.example.com",
+			isolated:       true,
+			expectedProbe:  []string{"https://raw.ghes.example.com/_ping"},
+			expectedREST:   "https://ghes.example.com/api/v3/",
+			expectedGQL:    "https://ghes.example.com/api/graphql",
+			expectedUpload: "https://uploads.ghes.example.com/",
+			expectedRaw:    "https://raw.ghes.example.com/",
+		},
+		{
+			name: "GHES with explicit endpoints is not probed",
+			host: "http://localhost:8080",
+			endpoints: APIEndpoints{
+				RESTURL:    "http://localhost:8080/api/v3",
+				GraphQLURL: "http://localhost:8080/api/graphql",
+				UploadURL:  "http://localhost:8080/api/uploads/",
+				RawURL:     "http://127.0.0.1:8081",
+			},
+			expectedREST:   "http://localhost:8080/api/v3/",
+			expectedGQL:    "http://localhost:8080/api/graphql",
+			expectedUpload: "http://localhost:8080/api/uploads/",
+			expectedRaw:    "http://127.0.0.1:8081/",
+		},
+		{
+			name:           "github.com with a staging REST API",
+			endpoints:      APIEndpoints{RESTURL: "https://api.staging.example.com/"},
+			expectedREST:   "https://api.staging.example.com/",
+			expectedGQL:    "https://api.github.com/graphql",
+			expectedUpload: "https://uploads.github.com",
+			expectedRaw:    "https://raw.githubusercontent.com/",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &probeTransport{isolated: tc.isolated}
+
+			host, err := parseAPIHost(tc.host, tc.endpoints, transport)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedProbe, transport.probed)
+			assert.Equal(t, tc.expectedREST, host.baseRESTURL.String())
+			assert.Equal(t, tc.expectedGQL, host.graphqlURL.String())
+			assert.Equal(t, tc.expectedUpload, host.uploadURL.String())
+			assert.Equal(t, tc.expectedRaw, host.rawURL.String())
+			if tc.expectedProbe == nil {
+				assert.Nil(t, host.subdomainIsolation)
+			} else {
+				require.NotNil(t, host.subdomainIsolation)
+				assert.Equal(t, tc.isolated, *host.subdomainIsolation)
+			}
+		})
+	}
+}
+
+func Test_ParseAPIHostErrors(t *testing.T) {
+	_, err := parseAPIHost("ghes.example.com", APIEndpoints{}, nil)
+	assert.ErrorContains(t, err, "host must have a scheme")
+
+	_, err = parseAPIHost("", APIEndpoints{GraphQLURL: "localhost:8080/graphql"}, nil)
+	assert.ErrorContains(t, err, "GraphQL URL must be an absolute http or https URL")
+}
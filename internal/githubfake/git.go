@@ -0,0 +1,454 @@
+package githubfake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v79/github"
+)
+
+func (s *Server) registerGitRoutes() {
+	s.route("GET /repos/{owner}/{repo}/git/ref/{ref...}", s.getRef)
+	s.route("GET /repos/{owner}/{repo}/git/matching-refs/{ref...}", s.listMatchingRefs)
+	s.route("POST /repos/{owner}/{repo}/git/refs", s.createRef)
+	s.route("PATCH /repos/{owner}/{repo}/git/refs/{ref...}", s.updateRef)
+	s.route("DELETE /repos/{owner}/{repo}/git/refs/{ref...}", s.deleteRef)
+	s.route("GET /repos/{owner}/{repo}/git/commits/{sha}", s.getGitCommit)
+	s.route("POST /repos/{owner}/{repo}/git/commits", s.createGitCommit)
+	s.route("GET /repos/{owner}/{repo}/git/trees/{sha...}", s.getTree)
+	s.route("POST /repos/{owner}/{repo}/git/trees", s.createTree)
+	s.route("GET /repos/{owner}/{repo}/git/blobs/{sha}", s.getBlob)
+	s.route("POST /repos/{owner}/{repo}/git/blobs", s.createBlob)
+	s.route("GET /repos/{owner}/{repo}/git/tags/{sha}", s.getTag)
+	s.route("POST /repos/{owner}/{repo}/git/tags", s.createTag)
+}
+
+func (s *Server) restReference(repo *repository, ref string) *github.Reference {
+	sha := repo.refs[ref]
+	objectType, objectPath := "commit", "commits"
+	if _, ok := repo.tags[sha]; ok {
+		objectType, objectPath = "tag", "tags"
+	}
+	return &github.Reference{
+		Ref:    github.Ptr(ref),
+		URL:    github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/" + ref)),
+		NodeID: github.Ptr("REF_" + ref),
+		Object: &github.GitObject{
+			Type: github.Ptr(objectType),
+			SHA:  github.Ptr(sha),
+			URL:  github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/" + objectPath + "/" + sha)),
+		},
+	}
+}
+
+func (s *Server) getRef(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	ref := "refs/" + r.PathValue("ref")
+	if _, ok := repo.refs[ref]; !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.restReference(repo, ref))
+}
+
+func (s *Server) listMatchingRefs(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	prefix := "refs/" + r.PathValue("ref")
+	refs := []*github.Reference{}
+	for _, name := range repo.refNames(prefix) {
+		refs = append(refs, s.restReference(repo, prefix+name))
+	}
+	writeJSON(w, http.StatusOK, refs)
+}
+
+func (s *Server) createRef(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if !strings.HasPrefix(body.Ref, "refs/") || strings.Count(body.Ref, "/") < 2 {
+		writeValidationError(w, "Reference update failed", "ref must start with refs/ and have at least two slashes")
+		return
+	}
+	if _, exists := repo.refs[body.Ref]; exists {
+		writeValidationError(w, "Reference already exists", body.Ref)
+		return
+	}
+	if _, isCommit := repo.commits[body.SHA]; !isCommit {
+		if _, isTag := repo.tags[body.SHA]; !isTag {
+			writeValidationError(w, "Object does not exist", body.SHA)
+			return
+		}
+	}
+	repo.refs[body.Ref] = body.SHA
+	writeJSON(w, http.StatusCreated, s.restReference(repo, body.Ref))
+}
+
+func (s *Server) updateRef(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		SHA   string `json:"sha"`
+		Force bool   `json:"force"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	ref := "refs/" + strings.TrimPrefix(r.PathValue("ref"), "refs/")
+	current, exists := repo.refs[ref]
+	if !exists {
+		writeValidationError(w, "Reference does not exist", ref)
+		return
+	}
+	if _, ok := repo.commits[body.SHA]; !ok {
+		writeValidationError(w, "Object does not exist", body.SHA)
+		return
+	}
+	if !body.Force && !isAncestor(repo, current, body.SHA) {
+		writeValidationError(w, "Update is not a fast forward", ref)
+		return
+	}
+	repo.refs[ref] = body.SHA
+	writeJSON(w, http.StatusOK, s.restReference(repo, ref))
+}
+
+// isAncestor reports whether ancestor is reachable from sha.
+func isAncestor(repo *repository, ancestor, sha string) bool {
+	for _, c := range repo.history(sha) {
+		if c.sha == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) deleteRef(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	ref := "refs/" + strings.TrimPrefix(r.PathValue("ref"), "refs/")
+	if _, exists := repo.refs[ref]; !exists {
+		writeValidationError(w, "Reference does not exist", ref)
+		return
+	}
+	delete(repo.refs, ref)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getGitCommit(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	c, ok := repo.commits[r.PathValue("sha")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.restGitCommit(repo, c))
+}
+
+func (s *Server) createGitCommit(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Message string   `json:"message"`
+		Tree    string   `json:"tree"`
+		Parents []string `json:"parents"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if _, ok := repo.trees[body.Tree]; !ok {
+		writeValidationError(w, "Tree SHA does not exist", body.Tree)
+		return
+	}
+	for _, parent := range body.Parents {
+		if _, ok := repo.commits[parent]; !ok {
+			writeValidationError(w, "Parent SHA does not exist or is not a commit object", parent)
+			return
+		}
+	}
+	c := repo.writeCommit(body.Tree, body.Parents, body.Message, s.viewer, s.now())
+	writeJSON(w, http.StatusCreated, s.restGitCommit(repo, c))
+}
+
+// treeFor returns the tree with a SHA, or the tree of the commit a SHA or ref resolves to.
+func treeFor(repo *repository, sha string) (string, bool) {
+	if _, ok := repo.trees[sha]; ok {
+		return sha, true
+	}
+	if commitSHA, ok := repo.resolve(sha); ok {
+		return repo.commits[commitSHA].tree, true
+	}
+	return "", false
+}
+
+func (s *Server) getTree(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	sha, ok := treeFor(repo, r.PathValue("sha"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.restTree(repo, sha, r.URL.Query().Get("recursive") != ""))
+}
+
+// restTree lists a flat tree the way the API does: every file and directory when recursive, and
+// the top level otherwise.
+func (s *Server) restTree(repo *repository, sha string, recursive bool) *github.Tree {
+	files := repo.trees[sha]
+	entries := []*github.TreeEntry{}
+	dirs := map[string]bool{}
+	for _, name := range sortedPaths(files) {
+		parts := strings.Split(name, "/")
+		for i := 1; i < len(parts); i++ {
+			dir := strings.Join(parts[:i], "/")
+			if dirs[dir] || (!recursive && i > 1) {
+				continue
+			}
+			dirs[dir] = true
+			entries = append(entries, &github.TreeEntry{
+				Path: github.Ptr(dir),
+				Mode: github.Ptr("040000"),
+				Type: github.Ptr("tree"),
+				SHA:  github.Ptr(repo.writeTree(subtree(files, dir+"/"))),
+			})
+		}
+		if !recursive && len(parts) > 1 {
+			continue
+		}
+		blob := files[name]
+		entries = append(entries, &github.TreeEntry{
+			Path: github.Ptr(name),
+			Mode: github.Ptr("100644"),
+			Type: github.Ptr("blob"),
+			SHA:  github.Ptr(blob),
+			Size: github.Ptr(len(repo.blobs[blob])),
+			URL:  github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/blobs/" + blob)),
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].GetPath() < entries[j].GetPath()
+	})
+	return &github.Tree{
+		SHA:       github.Ptr(sha),
+		Entries:   entries,
+		Truncated: github.Ptr(false),
+	}
+}
+
+func (s *Server) createTree(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		BaseTree string `json:"base_tree"`
+		Tree     []struct {
+			Path    string          `json:"path"`
+			Type    string          `json:"type"`
+			SHA     json.RawMessage `json:"sha"`
+			Content *string         `json:"content"`
+		} `json:"tree"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+
+	files := tree{}
+	if body.BaseTree != "" {
+		base, ok := repo.trees[body.BaseTree]
+		if !ok {
+			writeValidationError(w, "Invalid tree info", "base_tree "+body.BaseTree+" does not exist")
+			return
+		}
+		for name, sha := range base {
+			files[name] = sha
+		}
+	}
+
+	for _, entry := range body.Tree {
+		if len(entry.SHA) == 0 && entry.Content == nil {
+			writeValidationError(w, "Invalid tree info", "Must supply tree.sha or tree.content")
+			return
+		}
+		var sha *string
+		if len(entry.SHA) > 0 {
+			if err := json.Unmarshal(entry.SHA, &sha); err != nil {
+				writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+				return
+			}
+		}
+		switch {
+		case entry.Content != nil:
+			files[entry.Path] = repo.writeBlob([]byte(*entry.Content))
+		case sha == nil:
+			// A null SHA deletes the file or directory
+			delete(files, entry.Path)
+			for name := range subtree(files, entry.Path+"/") {
+				delete(files, entry.Path+"/"+name)
+			}
+		case entry.Type == "tree":
+			sub, ok := repo.trees[*sha]
+			if !ok {
+				writeValidationError(w, "Invalid tree info", "tree "+*sha+" does not exist")
+				return
+			}
+			for name, blob := range sub {
+				files[entry.Path+"/"+name] = blob
+			}
+		default:
+			if _, ok := repo.blobs[*sha]; !ok {
+				writeValidationError(w, "Invalid tree info", "blob "+*sha+" does not exist")
+				return
+			}
+			files[entry.Path] = *sha
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, s.restTree(repo, repo.writeTree(files), true))
+}
+
+func (s *Server) getBlob(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	sha := r.PathValue("sha")
+	content, ok := repo.blobs[sha]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &github.Blob{
+		SHA:      github.Ptr(sha),
+		Size:     github.Ptr(len(content)),
+		Encoding: github.Ptr("base64"),
+		Content:  github.Ptr(base64.StdEncoding.EncodeToString(content)),
+		URL:      github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/blobs/" + sha)),
+	})
+}
+
+func (s *Server) createBlob(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	content := []byte(body.Content)
+	if body.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(body.Content)
+		if err != nil {
+			writeValidationError(w, "Invalid request.", "content is not valid Base64")
+			return
+		}
+		content = decoded
+	}
+	sha := repo.writeBlob(content)
+	writeJSON(w, http.StatusCreated, &github.Blob{
+		SHA: github.Ptr(sha),
+		URL: github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/blobs/" + sha)),
+	})
+}
+
+func (s *Server) restTag(repo *repository, t *tag) *github.Tag {
+	objectPath := "commits"
+	if t.objectType == "tag" {
+		objectPath = "tags"
+	}
+	return &github.Tag{
+		Tag:     github.Ptr(t.name),
+		SHA:     github.Ptr(t.sha),
+		Message: github.Ptr(t.message),
+		NodeID:  github.Ptr("TAG_" + t.sha),
+		URL:     github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/tags/" + t.sha)),
+		Tagger: &github.CommitAuthor{
+			Name:  github.Ptr(t.tagger.name),
+			Email: github.Ptr(t.tagger.login + "@users.noreply.github.com"),
+			Date:  timestamp(t.date),
+		},
+		Object: &github.GitObject{
+			Type: github.Ptr(t.objectType),
+			SHA:  github.Ptr(t.object),
+			URL:  github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/" + objectPath + "/" + t.object)),
+		},
+	}
+}
+
+func (s *Server) getTag(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	t, ok := repo.tags[r.PathValue("sha")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.restTag(repo, t))
+}
+
+func (s *Server) createTag(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Tag     string `json:"tag"`
+		Message string `json:"message"`
+		Object  string `json:"object"`
+		Type    string `json:"type"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	_, isCommit := repo.commits[body.Object]
+	_, isTag := repo.tags[body.Object]
+	if (body.Type == "commit" && !isCommit) || (body.Type == "tag" && !isTag) || (!isCommit && !isTag) {
+		writeValidationError(w, "Object does not exist", body.Object)
+		return
+	}
+
+	t := &tag{
+		name:       body.Tag,
+		message:    body.Message,
+		object:     body.Object,
+		objectType: body.Type,
+		tagger:     s.viewer,
+		date:       s.now(),
+	}
+	t.sha = hashObject("tag", []byte("object "+t.object+"\ntype "+t.objectType+"\ntag "+t.name+"\n\n"+t.message))
+	repo.tags[t.sha] = t
+	writeJSON(w, http.StatusCreated, s.restTag(repo, t))
+}
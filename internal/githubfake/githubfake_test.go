@@ -0,0 +1,423 @@
+package githubfake
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	mcpgithub "github.com/github/github-mcp-server/pkg/github"
+	"github.com/google/go-github/v79/github"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClients(t *testing.T, fake *Server) (*github.Client, *githubv4.Client) {
+	t.Helper()
+	client, err := github.NewClient(nil).WithAuthToken(fake.Token()).WithEnterpriseURLs(fake.URL(), fake.URL())
+	require.NoError(t, err)
+	return client, githubv4.NewEnterpriseClient(fake.GraphQLURL(), client.Client())
+}
+
+func Test_Authentication(t *testing.T) {
+	fake := New(WithLogin("monalisa"), WithToken("ghp_secret"))
+	defer fake.Close()
+
+	client, _ := newClients(t, fake)
+	user, _, err := client.Users.Get(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "monalisa", user.GetLogin())
+
+	unauthenticated, err := github.NewClient(nil).WithAuthToken("ghp_wrong").WithEnterpriseURLs(fake.URL(), fake.URL())
+	require.NoError(t, err)
+	_, resp, err := unauthenticated.Users.Get(context.Background(), "")
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func Test_UnimplementedRoute(t *testing.T) {
+	fake := New()
+	defer fake.Close()
+
+	client, _ := newClients(t, fake)
+	_, resp, err := client.Actions.ListWorkflows(context.Background(), "octocat", "hello-world", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Contains(t, err.Error(), "the fake does not implement")
+}
+
+func Test_FilesAndCommits(t *testing.T) {
+	fake := New()
+	defer fake.Close()
+	fake.AddRepository("octocat", "hello-world", map[string]string{"README.md": "# Hello\n"})
+
+	ctx := context.Background()
+	client, _ := newClients(t, fake)
+
+	main, _, err := client.Git.GetRef(ctx, "octocat", "hello-world", "refs/heads/main")
+	require.NoError(t, err)
+	_, resp, err := client.Git.CreateRef(ctx, "octocat", "hello-world", github.CreateRef{
+		Ref: "refs/heads/feature",
+		SHA: main.GetObject().GetSHA(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	created, resp, err := client.Repositories.CreateFile(ctx, "octocat", "hello-world", "docs/guide.md", &github.RepositoryContentFileOptions{
+		Message: github.Ptr("Add guide"),
+		Content: []byte("one\ntwo\n"),
+		Branch:  github.Ptr("feature"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Updating requires the SHA of the file being replaced
+	_, _, err = client.Repositories.UpdateFile(ctx, "octocat", "hello-world", "docs/guide.md", &github.RepositoryContentFileOptions{
+		Message: github.Ptr("Update guide"),
+		Content: []byte("one\nthree\n"),
+		Branch:  github.Ptr("feature"),
+	})
+	require.Error(t, err)
+	_, _, err = client.Repositories.UpdateFile(ctx, "octocat", "hello-world", "docs/guide.md", &github.RepositoryContentFileOptions{
+		Message: github.Ptr("Update guide"),
+		Content: []byte("one\nthree\n"),
+		Branch:  github.Ptr("feature"),
+		SHA:     created.Content.SHA,
+	})
+	require.NoError(t, err)
+
+	file, dir, _, err := client.Repositories.GetContents(ctx, "octocat", "hello-world", "docs/guide.md", &github.RepositoryContentGetOptions{Ref: "feature"})
+	require.NoError(t, err)
+	require.Nil(t, dir)
+	content, err := file.GetContent()
+	require.NoError(t, err)
+	assert.Equal(t, "one\nthree\n", content)
+
+	_, dir, _, err = client.Repositories.GetContents(ctx, "octocat", "hello-world", "", &github.RepositoryContentGetOptions{Ref: "feature"})
+	require.NoError(t, err)
+	require.Len(t, dir, 2)
+	assert.Equal(t, "README.md", dir[0].GetName())
+	assert.Equal(t, "docs", dir[1].GetName())
+	assert.Equal(t, "dir", dir[1].GetType())
+
+	// The file isn't on the default branch
+	_, _, resp, err = client.Repositories.GetContents(ctx, "octocat", "hello-world", "docs/guide.md", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	commits, _, err := client.Repositories.ListCommits(ctx, "octocat", "hello-world", &github.CommitsListOptions{SHA: "feature"})
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	assert.Equal(t, "Update guide", commits[0].GetCommit().GetMessage())
+	assert.Equal(t, "Initial commit", commits[2].GetCommit().GetMessage())
+
+	commit, _, err := client.Repositories.GetCommit(ctx, "octocat", "hello-world", commits[0].GetSHA(), nil)
+	require.NoError(t, err)
+	require.Len(t, commit.Files, 1)
+	assert.Equal(t, "modified", commit.Files[0].GetStatus())
+	assert.Equal(t, 1, commit.Files[0].GetAdditions())
+	assert.Equal(t, 1, commit.Files[0].GetDeletions())
+	assert.Equal(t, "@@ -2,1 +2,1 @@\n-two\n+three", commit.Files[0].GetPatch())
+
+	rawResp, err := client.Client().Get(fake.RawURL() + "octocat/hello-world/feature/docs/guide.md")
+	require.NoError(t, err)
+	defer func() { _ = rawResp.Body.Close() }()
+	raw, err := io.ReadAll(rawResp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rawResp.StatusCode)
+	assert.Equal(t, "one\nthree\n", string(raw))
+	assert.Contains(t, rawResp.Header.Get("Content-Type"), "text/plain")
+}
+
+func Test_DeleteWithGitData(t *testing.T) {
+	fake := New()
+	defer fake.Close()
+	fake.AddRepository("octocat", "hello-world", map[string]string{
+		"README.md":     "# Hello\n",
+		"src/main.go":   "package main\n",
+		"src/helper.go": "package main\n",
+	})
+
+	ctx := context.Background()
+	client, _ := newClients(t, fake)
+
+	ref, _, err := client.Git.GetRef(ctx, "octocat", "hello-world", "refs/heads/main")
+	require.NoError(t, err)
+	parent, _, err := client.Git.GetCommit(ctx, "octocat", "hello-world", ref.GetObject().GetSHA())
+	require.NoError(t, err)
+
+	// A null SHA deletes the entry, as the server does to delete files
+	tree, resp, err := client.Git.CreateTree(ctx, "octocat", "hello-world", parent.GetTree().GetSHA(), []*github.TreeEntry{{
+		Path: github.Ptr("src/helper.go"),
+		Mode: github.Ptr("100644"),
+		Type: github.Ptr("blob"),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	commit, resp, err := client.Git.CreateCommit(ctx, "octocat", "hello-world", github.Commit{
+		Message: github.Ptr("Delete helper"),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: parent.SHA}},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	_, _, err = client.Git.UpdateRef(ctx, "octocat", "hello-world", "refs/heads/main", github.UpdateRef{SHA: commit.GetSHA()})
+	require.NoError(t, err)
+
+	files, _, err := client.Git.GetTree(ctx, "octocat", "hello-world", "main", true)
+	require.NoError(t, err)
+	var paths []string
+	for _, entry := range files.Entries {
+		paths = append(paths, entry.GetPath())
+	}
+	assert.Equal(t, []string{"README.md", "src", "src/main.go"}, paths)
+}
+
+func Test_PullRequestReviews(t *testing.T) {
+	fake := New()
+	defer fake.Close()
+	fake.AddRepository("octocat", "hello-world", map[string]string{"README.md": "# Hello\n"})
+
+	ctx := context.Background()
+	client, gqlClient := newClients(t, fake)
+
+	main, _, err := client.Git.GetRef(ctx, "octocat", "hello-world", "refs/heads/main")
+	require.NoError(t, err)
+	_, _, err = client.Git.CreateRef(ctx, "octocat", "hello-world", github.CreateRef{Ref: "refs/heads/feature", SHA: main.GetObject().GetSHA()})
+	require.NoError(t, err)
+
+	// There must be commits to merge
+	_, resp, err := client.PullRequests.Create(ctx, "octocat", "hello-world", &github.NewPullRequest{
+		Title: github.Ptr("Add file"),
+		Head:  github.Ptr("feature"),
+		Base:  github.Ptr("main"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	_, _, err = client.Repositories.CreateFile(ctx, "octocat", "hello-world", "file.txt", &github.RepositoryContentFileOptions{
+		Message: github.Ptr("Add file"),
+		Content: []byte("hello\n"),
+		Branch:  github.Ptr("feature"),
+	})
+	require.NoError(t, err)
+	pr, resp, err := client.PullRequests.Create(ctx, "octocat", "hello-world", &github.NewPullRequest{
+		Title: github.Ptr("Add file"),
+		Head:  github.Ptr("feature"),
+		Base:  github.Ptr("main"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, 1, pr.GetChangedFiles())
+
+	var prQuery struct {
+		Repository struct {
+			PullRequest struct {
+				ID githubv4.ID
+			} `graphql:"pullRequest(number: $prNum)"`
+		} `graphql:"repository(owner: $owner, name: $repo)"`
+	}
+	require.NoError(t, gqlClient.Query(ctx, &prQuery, map[string]any{
+		"owner": githubv4.String("octocat"),
+		"repo":  githubv4.String("hello-world"),
+		"prNum": githubv4.Int(pr.GetNumber()),
+	}))
+
+	var addReview struct {
+		AddPullRequestReview struct {
+			PullRequestReview struct {
+				ID    githubv4.ID
+				State githubv4.PullRequestReviewState
+			}
+		} `graphql:"addPullRequestReview(input: $input)"`
+	}
+	require.NoError(t, gqlClient.Mutate(ctx, &addReview, githubv4.AddPullRequestReviewInput{
+		PullRequestID: prQuery.Repository.PullRequest.ID,
+	}, nil))
+	assert.Equal(t, githubv4.PullRequestReviewStatePending, addReview.AddPullRequestReview.PullRequestReview.State)
+
+	var addThread struct {
+		AddPullRequestReviewThread struct {
+			Thread struct {
+				ID githubv4.ID
+			}
+		} `graphql:"addPullRequestReviewThread(input: $input)"`
+	}
+	reviewID := addReview.AddPullRequestReview.PullRequestReview.ID
+	require.NoError(t, gqlClient.Mutate(ctx, &addThread, githubv4.AddPullRequestReviewThreadInput{
+		PullRequestReviewID: &reviewID,
+		Path:                "file.txt",
+		Body:                "Nice",
+		Line:                githubv4.NewInt(1),
+	}, nil))
+	assert.NotNil(t, addThread.AddPullRequestReviewThread.Thread.ID)
+
+	// Authors can only comment on their own pull requests
+	var submit struct {
+		SubmitPullRequestReview struct {
+			PullRequestReview struct {
+				State githubv4.PullRequestReviewState
+			}
+		} `graphql:"submitPullRequestReview(input: $input)"`
+	}
+	err = gqlClient.Mutate(ctx, &submit, githubv4.SubmitPullRequestReviewInput{
+		PullRequestReviewID: &reviewID,
+		Event:               githubv4.PullRequestReviewEventApprove,
+	}, nil)
+	require.ErrorContains(t, err, "Can not approve your own pull request")
+	require.NoError(t, gqlClient.Mutate(ctx, &submit, githubv4.SubmitPullRequestReviewInput{
+		PullRequestReviewID: &reviewID,
+		Event:               githubv4.PullRequestReviewEventComment,
+	}, nil))
+	assert.Equal(t, githubv4.PullRequestReviewStateCommented, submit.SubmitPullRequestReview.PullRequestReview.State)
+
+	reviews, _, err := client.PullRequests.ListReviews(ctx, "octocat", "hello-world", pr.GetNumber(), nil)
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	comments, _, err := client.PullRequests.ListReviewComments(ctx, "octocat", "hello-world", pr.GetNumber(), reviews[0].GetID(), nil)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "Nice", comments[0].GetBody())
+
+	merged, _, err := client.PullRequests.Merge(ctx, "octocat", "hello-world", pr.GetNumber(), "", nil)
+	require.NoError(t, err)
+	assert.True(t, merged.GetMerged())
+	_, _, _, err = client.Repositories.GetContents(ctx, "octocat", "hello-world", "file.txt", nil)
+	require.NoError(t, err)
+}
+
+func Test_IssuesAndAssignment(t *testing.T) {
+	fake := New()
+	defer fake.Close()
+	fake.AddRepository("octocat", "hello-world", nil)
+
+	ctx := context.Background()
+	client, gqlClient := newClients(t, fake)
+
+	for _, title := range []string{"First", "Second", "Third"} {
+		_, resp, err := client.Issues.Create(ctx, "octocat", "hello-world", &github.IssueRequest{
+			Title:  github.Ptr(title),
+			Labels: &[]string{"bug"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	var listQuery struct {
+		Repository struct {
+			Issues struct {
+				Nodes []struct {
+					Number githubv4.Int
+					Title  githubv4.String
+					Labels struct {
+						Nodes []struct{ Name githubv4.String }
+					} `graphql:"labels(first: 100)"`
+				}
+				PageInfo struct {
+					HasNextPage githubv4.Boolean
+					EndCursor   githubv4.String
+				}
+				TotalCount int
+			} `graphql:"issues(first: $first, after: $after, labels: $labels, states: $states, orderBy: {field: $orderBy, direction: $direction})"`
+		} `graphql:"repository(owner: $owner, name: $repo)"`
+	}
+	vars := map[string]any{
+		"owner":     githubv4.String("octocat"),
+		"repo":      githubv4.String("hello-world"),
+		"first":     githubv4.Int(2),
+		"after":     (*githubv4.String)(nil),
+		"labels":    []githubv4.String{"bug"},
+		"states":    []githubv4.IssueState{githubv4.IssueStateOpen},
+		"orderBy":   githubv4.IssueOrderFieldCreatedAt,
+		"direction": githubv4.OrderDirectionDesc,
+	}
+	require.NoError(t, gqlClient.Query(ctx, &listQuery, vars))
+	issues := listQuery.Repository.Issues
+	assert.Equal(t, 3, issues.TotalCount)
+	require.Len(t, issues.Nodes, 2)
+	assert.Equal(t, githubv4.String("Third"), issues.Nodes[0].Title)
+	assert.Equal(t, githubv4.String("bug"), issues.Nodes[0].Labels.Nodes[0].Name)
+	assert.True(t, bool(issues.PageInfo.HasNextPage))
+
+	vars["after"] = githubv4.String(issues.PageInfo.EndCursor)
+	require.NoError(t, gqlClient.Query(ctx, &listQuery, vars))
+	require.Len(t, listQuery.Repository.Issues.Nodes, 1)
+	assert.Equal(t, githubv4.String("First"), listQuery.Repository.Issues.Nodes[0].Title)
+
+	// Copilot is suggested as a bot, with its GraphQL login
+	var actorsQuery struct {
+		Repository struct {
+			SuggestedActors struct {
+				Nodes []struct {
+					TypeName string `graphql:"__typename"`
+					Bot      struct {
+						ID    githubv4.ID
+						Login githubv4.String
+					} `graphql:"... on Bot"`
+				}
+			} `graphql:"suggestedActors(first: 100, capabilities: CAN_BE_ASSIGNED)"`
+			Issue struct {
+				ID githubv4.ID
+			} `graphql:"issue(number: 1)"`
+		} `graphql:"repository(owner: $owner, name: $repo)"`
+	}
+	require.NoError(t, gqlClient.Query(ctx, &actorsQuery, map[string]any{
+		"owner": githubv4.String("octocat"),
+		"repo":  githubv4.String("hello-world"),
+	}))
+	bot := actorsQuery.Repository.SuggestedActors.Nodes[0]
+	assert.Equal(t, "Bot", bot.TypeName)
+	assert.Equal(t, githubv4.String("copilot-swe-agent"), bot.Bot.Login)
+
+	var replace struct {
+		ReplaceActorsForAssignable struct {
+			TypeName string `graphql:"__typename"`
+		} `graphql:"replaceActorsForAssignable(input: $input)"`
+	}
+	require.NoError(t, gqlClient.Mutate(ctx, &replace, mcpgithub.ReplaceActorsForAssignableInput{
+		AssignableID: actorsQuery.Repository.Issue.ID,
+		ActorIDs:     []githubv4.ID{bot.Bot.ID},
+	}, nil))
+
+	issue, _, err := client.Issues.Get(ctx, "octocat", "hello-world", 1)
+	require.NoError(t, err)
+	require.Len(t, issue.Assignees, 1)
+	assert.Equal(t, "Copilot", issue.Assignees[0].GetLogin())
+	assert.Equal(t, "Bot", issue.Assignees[0].GetType())
+
+	result, _, err := client.Search.Issues(ctx, "repo:octocat/hello-world is:issue Second", nil)
+	require.NoError(t, err)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, 2, result.Issues[0].GetNumber())
+
+	_, resp, err := client.Search.Issues(ctx, "repo:octocat/hello-world reactions:>5", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func Test_GraphQLErrors(t *testing.T) {
+	fake := New()
+	defer fake.Close()
+
+	ctx := context.Background()
+	_, gqlClient := newClients(t, fake)
+
+	var missing struct {
+		Repository struct {
+			ID githubv4.ID
+		} `graphql:"repository(owner: \"octocat\", name: \"missing\")"`
+	}
+	require.ErrorContains(t, gqlClient.Query(ctx, &missing, nil), "Could not resolve to a Repository with the name 'octocat/missing'.")
+
+	var unimplemented struct {
+		Viewer struct {
+			Sponsors struct {
+				TotalCount int
+			} `graphql:"sponsors(first: 1)"`
+		}
+	}
+	require.ErrorContains(t, gqlClient.Query(ctx, &unimplemented, nil), "Field 'sponsors' doesn't exist on type 'User', or is not implemented by the fake")
+}
@@ -0,0 +1,482 @@
+package githubfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// The fake executes GraphQL queries itself rather than with a schema-driven library, as it only
+// needs the handful of fields the server queries. A document is parsed into selections, which are
+// resolved against objects whose fields are functions of their arguments. Fields an object doesn't
+// have fail the query.
+
+type selection struct {
+	alias     string
+	name      string
+	arguments map[string]any
+	children  []*selection
+	// onType is set, with no name, for inline fragments (... on Type)
+	onType string
+}
+
+// variable refers to a variable in an argument, and is replaced by its value before fields resolve.
+type variable string
+
+// enum is an enum value in an argument, which resolves to its name.
+type enum string
+
+// resolver returns the value of a field given its arguments. The value is a scalar, nil, an
+// *object, or a slice of *object.
+type resolver func(args map[string]any) (any, error)
+
+type object struct {
+	typename string
+	fields   map[string]resolver
+}
+
+// queryError is an error reported to the client, worded as GitHub words it.
+type queryError string
+
+func (e queryError) Error() string {
+	return string(e)
+}
+
+func queryErrorf(format string, args ...any) error {
+	return queryError(fmt.Sprintf(format, args...))
+}
+
+// constant is a resolver for a field that takes no arguments.
+func constant(v any) resolver {
+	return func(map[string]any) (any, error) {
+		return v, nil
+	}
+}
+
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	writeResult := func(data any, err error) {
+		if err != nil {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"data":   nil,
+				"errors": []map[string]string{{"message": err.Error()}},
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	}
+
+	operation, selections, err := parseDocument(request.Query)
+	if err != nil {
+		writeResult(nil, err)
+		return
+	}
+	root := s.queryObject()
+	if operation == "mutation" {
+		root = s.mutationObject()
+	}
+	writeResult(execute(root, selections, request.Variables))
+}
+
+func execute(obj *object, selections []*selection, variables map[string]any) (map[string]any, error) {
+	result := map[string]any{}
+	for _, sel := range selections {
+		if sel.name == "" {
+			if sel.onType != obj.typename {
+				continue
+			}
+			fragment, err := execute(obj, sel.children, variables)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range fragment {
+				result[key] = value
+			}
+			continue
+		}
+
+		key := sel.alias
+		if key == "" {
+			key = sel.name
+		}
+		if sel.name == "__typename" {
+			result[key] = obj.typename
+			continue
+		}
+		field, ok := obj.fields[sel.name]
+		if !ok {
+			return nil, queryErrorf("Field '%s' doesn't exist on type '%s', or is not implemented by the fake", sel.name, obj.typename)
+		}
+		value, err := field(substitute(sel.arguments, variables).(map[string]any))
+		if err != nil {
+			return nil, err
+		}
+		if result[key], err = complete(value, sel, variables); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// complete resolves the selections of a field's value.
+func complete(value any, sel *selection, variables map[string]any) (any, error) {
+	switch v := value.(type) {
+	case *object:
+		if v == nil {
+			return nil, nil
+		}
+		if len(sel.children) == 0 {
+			return nil, queryErrorf("Field must have selections (field '%s' returns %s but has no selections)", sel.name, v.typename)
+		}
+		return execute(v, sel.children, variables)
+	case []*object:
+		list := make([]any, 0, len(v))
+		for _, item := range v {
+			completed, err := complete(item, sel, variables)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, completed)
+		}
+		return list, nil
+	default:
+		return v, nil
+	}
+}
+
+// substitute replaces variables in an argument value with their values, and enums with their names.
+func substitute(value any, variables map[string]any) any {
+	switch v := value.(type) {
+	case variable:
+		return variables[string(v)]
+	case enum:
+		return string(v)
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = substitute(item, variables)
+		}
+		return result
+	case []any:
+		result := make([]any, 0, len(v))
+		for _, item := range v {
+			result = append(result, substitute(item, variables))
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// argString returns a string argument, or "" if it is absent or null.
+func argString(args map[string]any, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// argInt returns an integer argument, or 0 if it is absent or null. Integers in variables arrive
+// as float64, as they are decoded from JSON.
+func argInt(args map[string]any, name string) int {
+	switch v := args[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// argStrings returns a list argument of strings, which may be given as a single string.
+func argStrings(args map[string]any, name string) []string {
+	switch v := args[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// argObject returns an input object argument, or an empty one if it is absent or null.
+func argObject(args map[string]any, name string) map[string]any {
+	if v, ok := args[name].(map[string]any); ok {
+		return v
+	}
+	return map[string]any{}
+}
+
+// parser is a recursive descent parser for the executable subset of GraphQL the server's client
+// generates: a single query or mutation with variables, fields with aliases and arguments, and inline
+// fragments.
+type parser struct {
+	src string
+	pos int
+}
+
+func parseDocument(src string) (string, []*selection, error) {
+	p := &parser{src: src}
+	operation := "query"
+	if name := p.peekName(); name == "query" || name == "mutation" {
+		operation = p.name()
+		if p.peekName() != "" {
+			p.name()
+		}
+		if p.peek() == '(' {
+			if err := p.skipVariableDefinitions(); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return "", nil, err
+	}
+	if p.skipIgnored(); p.pos < len(p.src) {
+		return "", nil, p.errorf("unexpected %q after the operation; the fake supports a single operation", p.src[p.pos:])
+	}
+	return operation, selections, nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return queryErrorf("Parse error at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// skipIgnored skips whitespace, commas and comments, which are insignificant in GraphQL.
+func (p *parser) skipIgnored() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) peek() byte {
+	p.skipIgnored()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func (p *parser) peekName() string {
+	p.skipIgnored()
+	end := p.pos
+	for end < len(p.src) && (isNameStart(p.src[end]) || (end > p.pos && p.src[end] >= '0' && p.src[end] <= '9')) {
+		end++
+	}
+	return p.src[p.pos:end]
+}
+
+func (p *parser) name() string {
+	name := p.peekName()
+	p.pos += len(name)
+	return name
+}
+
+// skipVariableDefinitions skips the variable definitions of an operation, as variables are used as
+// they are given.
+func (p *parser) skipVariableDefinitions() error {
+	depth := 0
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+		}
+		p.pos++
+	}
+	return p.errorf("unterminated variable definitions")
+}
+
+func (p *parser) selectionSet() ([]*selection, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	var selections []*selection
+	for p.peek() != '}' {
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated selection set")
+		}
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	p.pos++
+	return selections, nil
+}
+
+func (p *parser) selection() (*selection, error) {
+	if strings.HasPrefix(p.src[p.pos:], "...") {
+		p.pos += 3
+		if p.peekName() != "on" {
+			return nil, p.errorf("the fake only supports inline fragments with a type condition")
+		}
+		p.name()
+		sel := &selection{onType: p.name()}
+		children, err := p.selectionSet()
+		sel.children = children
+		return sel, err
+	}
+
+	sel := &selection{name: p.name()}
+	if sel.name == "" {
+		return nil, p.errorf("expected a field")
+	}
+	if p.peek() == ':' {
+		p.pos++
+		sel.alias, sel.name = sel.name, p.name()
+	}
+	if p.peek() == '(' {
+		p.pos++
+		args, err := p.objectFields(')')
+		if err != nil {
+			return nil, err
+		}
+		sel.arguments = args
+	}
+	if p.peek() == '{' {
+		children, err := p.selectionSet()
+		if err != nil {
+			return nil, err
+		}
+		sel.children = children
+	}
+	return sel, nil
+}
+
+// objectFields parses name: value pairs up to and including end, for arguments and input objects.
+func (p *parser) objectFields(end byte) (map[string]any, error) {
+	fields := map[string]any{}
+	for p.peek() != end {
+		name := p.name()
+		if name == "" {
+			return nil, p.errorf("expected a name")
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = value
+	}
+	p.pos++
+	return fields, nil
+}
+
+func (p *parser) value() (any, error) {
+	switch c := p.peek(); {
+	case c == '$':
+		p.pos++
+		return variable(p.name()), nil
+	case c == '"':
+		return p.stringValue()
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		number := p.src[start:p.pos]
+		if n, err := strconv.Atoi(number); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", number)
+		}
+		return f, nil
+	case c == '[':
+		p.pos++
+		list := []any{}
+		for p.peek() != ']' {
+			if p.pos >= len(p.src) {
+				return nil, p.errorf("unterminated list")
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		p.pos++
+		return list, nil
+	case c == '{':
+		p.pos++
+		return p.objectFields('}')
+	case isNameStart(c):
+		switch name := p.name(); name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			return enum(name), nil
+		}
+	default:
+		return nil, p.errorf("expected a value")
+	}
+}
+
+func (p *parser) stringValue() (string, error) {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.src) && p.src[p.pos] != '"' {
+		if p.src[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		return "", p.errorf("unterminated string")
+	}
+	p.pos++
+	var s string
+	if err := json.Unmarshal([]byte(p.src[start:p.pos]), &s); err != nil {
+		return "", p.errorf("invalid string: %v", err)
+	}
+	return s, nil
+}
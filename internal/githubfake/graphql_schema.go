@@ -0,0 +1,639 @@
+package githubfake
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (s *Server) queryObject() *object {
+	return &object{typename: "Query", fields: map[string]resolver{
+		"viewer": constant(s.gqlUser(s.viewer)),
+		"repository": func(args map[string]any) (any, error) {
+			repo, ok := s.repository(argString(args, "owner"), argString(args, "name"))
+			if !ok {
+				return nil, queryErrorf("Could not resolve to a Repository with the name '%s/%s'.", argString(args, "owner"), argString(args, "name"))
+			}
+			return s.gqlRepository(repo), nil
+		},
+		"user": func(args map[string]any) (any, error) {
+			u, ok := s.users[strings.ToLower(argString(args, "login"))]
+			if !ok {
+				return nil, queryErrorf("Could not resolve to a User with the login of '%s'.", argString(args, "login"))
+			}
+			return s.gqlUser(u), nil
+		},
+	}}
+}
+
+func (s *Server) mutationObject() *object {
+	return &object{typename: "Mutation", fields: map[string]resolver{
+		"addPullRequestReview":          s.addPullRequestReview,
+		"submitPullRequestReview":       s.submitPullRequestReview,
+		"deletePullRequestReview":       s.deletePullRequestReview,
+		"addPullRequestReviewThread":    s.addPullRequestReviewThread,
+		"replaceActorsForAssignable":    s.replaceActorsForAssignable,
+		"closeIssue":                    s.closeIssue,
+		"reopenIssue":                   s.reopenIssue,
+		"convertPullRequestToDraft":     s.setDraft(true),
+		"markPullRequestReadyForReview": s.setDraft(false),
+	}}
+}
+
+// dateTime formats a time as a GraphQL DateTime, or null if it is zero.
+func dateTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
+
+// connection pages through nodes with the first, after, last and before arguments.
+func connection(typename string, nodes []*object, args map[string]any) (*object, error) {
+	start, end := 0, len(nodes)
+	if after := argString(args, "after"); after != "" {
+		n, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		start = min(n, end)
+	}
+	if before := argString(args, "before"); before != "" {
+		n, err := decodeCursor(before)
+		if err != nil {
+			return nil, err
+		}
+		end = max(min(n-1, end), start)
+	}
+	if first := argInt(args, "first"); first > 0 {
+		end = min(start+first, end)
+	}
+	if last := argInt(args, "last"); last > 0 {
+		start = max(end-last, start)
+	}
+
+	page := nodes[start:end]
+	edges := make([]*object, 0, len(page))
+	for i, node := range page {
+		edges = append(edges, &object{typename: typename + "Edge", fields: map[string]resolver{
+			"cursor": constant(encodeCursor(start + i + 1)),
+			"node":   constant(node),
+		}})
+	}
+	var startCursor, endCursor any
+	if len(page) > 0 {
+		startCursor, endCursor = encodeCursor(start+1), encodeCursor(end)
+	}
+	return &object{typename: typename + "Connection", fields: map[string]resolver{
+		"nodes":      constant(page),
+		"edges":      constant(edges),
+		"totalCount": constant(len(nodes)),
+		"pageInfo": constant(&object{typename: "PageInfo", fields: map[string]resolver{
+			"hasNextPage":     constant(end < len(nodes)),
+			"hasPreviousPage": constant(start > 0),
+			"startCursor":     constant(startCursor),
+			"endCursor":       constant(endCursor),
+		}}),
+	}}, nil
+}
+
+// encodeCursor encodes the 1-based position of a node, in the form GitHub's cursors take.
+func encodeCursor(position int) string {
+	return base64.StdEncoding.EncodeToString([]byte("cursor:" + strconv.Itoa(position)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err == nil {
+		if position, ok := strings.CutPrefix(string(decoded), "cursor:"); ok {
+			if n, err := strconv.Atoi(position); err == nil {
+				return n, nil
+			}
+		}
+	}
+	return 0, queryErrorf("`%s` does not appear to be a valid cursor.", cursor)
+}
+
+func (s *Server) gqlUser(u *user) *object {
+	login := u.login
+	if u.graphQLLogin != "" {
+		login = u.graphQLLogin
+	}
+	return &object{typename: u.typ, fields: map[string]resolver{
+		"id":         constant(u.nodeID()),
+		"databaseId": constant(u.id),
+		"login":      constant(login),
+		"name":       constant(u.name),
+		"url":        constant(s.htmlURL(u.login)),
+		"avatarUrl":  constant(s.htmlURL(u.login + ".png")),
+	}}
+}
+
+func (s *Server) gqlUsers(users []*user) []*object {
+	result := make([]*object, 0, len(users))
+	for _, u := range users {
+		result = append(result, s.gqlUser(u))
+	}
+	return result
+}
+
+func (s *Server) gqlRepository(repo *repository) *object {
+	return &object{typename: "Repository", fields: map[string]resolver{
+		"id":            constant(fmtNodeID("R", repo.id)),
+		"databaseId":    constant(repo.id),
+		"name":          constant(repo.name),
+		"nameWithOwner": constant(repo.fullName()),
+		"owner":         constant(s.gqlUser(repo.owner)),
+		"isPrivate":     constant(repo.private),
+		"description":   constant(repo.description),
+		"url":           constant(s.htmlURL(repo.fullName())),
+		"defaultBranchRef": constant(&object{typename: "Ref", fields: map[string]resolver{
+			"name":   constant(repo.defaultBranch),
+			"prefix": constant("refs/heads/"),
+		}}),
+		"issue": func(args map[string]any) (any, error) {
+			i, ok := repo.issues[argInt(args, "number")]
+			if !ok || i.pull != nil {
+				return nil, queryErrorf("Could not resolve to an Issue with the number of %d.", argInt(args, "number"))
+			}
+			return s.gqlIssue(i), nil
+		},
+		"pullRequest": func(args map[string]any) (any, error) {
+			i, ok := repo.issues[argInt(args, "number")]
+			if !ok || i.pull == nil {
+				return nil, queryErrorf("Could not resolve to a PullRequest with the number of %d.", argInt(args, "number"))
+			}
+			return s.gqlIssue(i), nil
+		},
+		"issues": func(args map[string]any) (any, error) {
+			return s.gqlIssues(repo, false, args)
+		},
+		"pullRequests": func(args map[string]any) (any, error) {
+			return s.gqlIssues(repo, true, args)
+		},
+		"suggestedActors": func(args map[string]any) (any, error) {
+			// Copilot can be assigned to issues, as well as the people who can push
+			actors := []*object{s.gqlUser(s.copilot), s.gqlUser(repo.owner)}
+			if repo.owner != s.viewer {
+				actors = append(actors, s.gqlUser(s.viewer))
+			}
+			return connection("Actor", actors, args)
+		},
+	}}
+}
+
+// gqlState is the GraphQL state of an issue or pull request.
+func gqlState(i *issue) string {
+	if i.pull != nil && i.pull.merged {
+		return "MERGED"
+	}
+	return strings.ToUpper(i.state)
+}
+
+// gqlIssues lists the issues or pull requests of a repository, filtered by the states, labels and
+// filterBy arguments, and sorted by orderBy.
+func (s *Server) gqlIssues(repo *repository, pulls bool, args map[string]any) (any, error) {
+	states := argStrings(args, "states")
+	labels := argStrings(args, "labels")
+	var since time.Time
+	if value := argString(argObject(args, "filterBy"), "since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, queryErrorf("Argument 'since' on InputObject 'IssueFilters' has an invalid value (%s).", value)
+		}
+	}
+
+	var issues []*issue
+	for _, i := range repo.issues {
+		switch {
+		case (i.pull != nil) != pulls,
+			len(states) > 0 && !slices.Contains(states, gqlState(i)),
+			!containsAll(i.labels, labels),
+			i.updatedAt.Before(since):
+			continue
+		}
+		issues = append(issues, i)
+	}
+
+	orderBy := argObject(args, "orderBy")
+	key := func(i *issue) int64 {
+		switch argString(orderBy, "field") {
+		case "UPDATED_AT":
+			return i.updatedAt.Unix()
+		case "COMMENTS":
+			return int64(len(i.comments))
+		default:
+			return i.createdAt.Unix()
+		}
+	}
+	descending := argString(orderBy, "direction") == "DESC"
+	sort.SliceStable(issues, func(a, b int) bool {
+		ka, kb := key(issues[a]), key(issues[b])
+		if ka == kb {
+			ka, kb = int64(issues[a].number), int64(issues[b].number)
+		}
+		if descending {
+			return ka > kb
+		}
+		return ka < kb
+	})
+
+	nodes := make([]*object, 0, len(issues))
+	for _, i := range issues {
+		nodes = append(nodes, s.gqlIssue(i))
+	}
+	typename := "Issue"
+	if pulls {
+		typename = "PullRequest"
+	}
+	return connection(typename, nodes, args)
+}
+
+// gqlIssue is an Issue, or a PullRequest for pull requests.
+func (s *Server) gqlIssue(i *issue) *object {
+	path := "issues"
+	if i.pull != nil {
+		path = "pull"
+	}
+	fields := map[string]resolver{
+		"id":         constant(i.nodeID()),
+		"databaseId": constant(i.id),
+		"number":     constant(i.number),
+		"title":      constant(i.title),
+		"body":       constant(i.body),
+		"state":      constant(gqlState(i)),
+		"closed":     constant(i.state == "closed"),
+		"author":     constant(s.gqlUser(i.user)),
+		"createdAt":  constant(dateTime(i.createdAt)),
+		"updatedAt":  constant(dateTime(i.updatedAt)),
+		"closedAt":   constant(dateTime(i.closedAt)),
+		"url":        constant(s.htmlURL(i.repo.fullName() + "/" + path + "/" + strconv.Itoa(i.number))),
+		"repository": func(map[string]any) (any, error) {
+			return s.gqlRepository(i.repo), nil
+		},
+		"labels": func(args map[string]any) (any, error) {
+			labels := make([]*object, 0, len(i.labels))
+			for _, name := range i.labels {
+				labels = append(labels, &object{typename: "Label", fields: map[string]resolver{
+					"id":          constant(fmt.Sprintf("LA_%d_%s", i.repo.id, name)),
+					"name":        constant(name),
+					"description": constant(""),
+					"color":       constant("ededed"),
+				}})
+			}
+			return connection("Label", labels, args)
+		},
+		"comments": func(args map[string]any) (any, error) {
+			comments := make([]*object, 0, len(i.comments))
+			for _, c := range i.comments {
+				comments = append(comments, &object{typename: "IssueComment", fields: map[string]resolver{
+					"id":         constant(fmtNodeID("IC", c.id)),
+					"databaseId": constant(c.id),
+					"body":       constant(c.body),
+					"author":     constant(s.gqlUser(c.user)),
+					"createdAt":  constant(dateTime(c.createdAt)),
+				}})
+			}
+			return connection("IssueComment", comments, args)
+		},
+		"assignees": func(args map[string]any) (any, error) {
+			return connection("User", s.gqlUsers(i.assignees), args)
+		},
+	}
+	if i.pull == nil {
+		var stateReason any
+		if i.stateReason != "" {
+			stateReason = strings.ToUpper(i.stateReason)
+		}
+		fields["stateReason"] = constant(stateReason)
+		return &object{typename: "Issue", fields: fields}
+	}
+
+	p := i.pull
+	fields["isDraft"] = constant(p.draft)
+	fields["merged"] = constant(p.merged)
+	fields["mergedAt"] = constant(dateTime(p.mergedAt))
+	fields["headRefName"] = constant(p.head)
+	fields["baseRefName"] = constant(p.base)
+	fields["headRefOid"] = constant(p.headSHA(i.repo))
+	fields["baseRefOid"] = constant(i.repo.refs["refs/heads/"+p.base])
+	fields["reviews"] = func(args map[string]any) (any, error) {
+		author, states := argString(args, "author"), argStrings(args, "states")
+		var reviews []*object
+		for _, r := range p.reviews {
+			switch {
+			case !r.visibleTo(s.viewer),
+				author != "" && !strings.EqualFold(author, r.user.login) && !strings.EqualFold(author, r.user.graphQLLogin),
+				len(states) > 0 && !slices.Contains(states, r.state):
+				continue
+			}
+			reviews = append(reviews, s.gqlReview(r))
+		}
+		return connection("PullRequestReview", reviews, args)
+	}
+	return &object{typename: "PullRequest", fields: fields}
+}
+
+func (s *Server) gqlReview(r *review) *object {
+	return &object{typename: "PullRequestReview", fields: map[string]resolver{
+		"id":          constant(r.nodeID()),
+		"databaseId":  constant(r.id),
+		"state":       constant(r.state),
+		"body":        constant(r.body),
+		"author":      constant(s.gqlUser(r.user)),
+		"submittedAt": constant(dateTime(r.submittedAt)),
+		"url":         constant(s.htmlURL(r.pull.repo.fullName() + "/pull/" + strconv.Itoa(r.pull.number) + "#pullrequestreview-" + strconv.FormatInt(r.id, 10))),
+		"pullRequest": func(map[string]any) (any, error) {
+			return s.gqlIssue(r.pull), nil
+		},
+	}}
+}
+
+func (s *Server) gqlReviewThread(c *reviewComment) *object {
+	var line, startLine any
+	if c.line != 0 {
+		line = c.line
+	}
+	if c.startLine != 0 {
+		startLine = c.startLine
+	}
+	return &object{typename: "PullRequestReviewThread", fields: map[string]resolver{
+		"id":          constant(c.nodeID()),
+		"path":        constant(c.path),
+		"line":        constant(line),
+		"startLine":   constant(startLine),
+		"diffSide":    constant(c.side),
+		"subjectType": constant(c.subjectType),
+		"isResolved":  constant(false),
+		"isOutdated":  constant(false),
+	}}
+}
+
+// findIssue returns the issue or pull request with a node ID.
+func (s *Server) findIssue(nodeID string) (*issue, error) {
+	for _, repo := range s.repos {
+		for _, i := range repo.issues {
+			if i.nodeID() == nodeID {
+				return i, nil
+			}
+		}
+	}
+	return nil, queryErrorf("Could not resolve to a node with the global id of '%s'", nodeID)
+}
+
+// findReview returns the review with a node ID.
+func (s *Server) findReview(nodeID string) (*review, error) {
+	for _, repo := range s.repos {
+		for _, i := range repo.issues {
+			if i.pull == nil {
+				continue
+			}
+			for _, r := range i.pull.reviews {
+				if r.nodeID() == nodeID && r.visibleTo(s.viewer) {
+					return r, nil
+				}
+			}
+		}
+	}
+	return nil, queryErrorf("Could not resolve to a node with the global id of '%s'", nodeID)
+}
+
+// findActor returns the user or bot with a node ID.
+func (s *Server) findActor(nodeID string) (*user, error) {
+	for _, u := range s.users {
+		if u.nodeID() == nodeID {
+			return u, nil
+		}
+	}
+	return nil, queryErrorf("Could not resolve to a node with the global id of '%s'", nodeID)
+}
+
+// reviewStates maps review events to the states of the reviews they submit.
+var reviewStates = map[string]string{
+	"COMMENT":         "COMMENTED",
+	"APPROVE":         "APPROVED",
+	"REQUEST_CHANGES": "CHANGES_REQUESTED",
+}
+
+// submitReview submits a pending review with an event.
+func (s *Server) submitReview(r *review, event, body string) error {
+	state, ok := reviewStates[event]
+	if !ok {
+		return queryErrorf("Argument 'event' on InputObject has an invalid value (%s).", event)
+	}
+	switch {
+	case r.user == r.pull.user && state == "APPROVED":
+		return queryErrorf("Review Can not approve your own pull request")
+	case r.user == r.pull.user && state == "CHANGES_REQUESTED":
+		return queryErrorf("Review Can not request changes on your own pull request")
+	}
+	if body != "" {
+		r.body = body
+	}
+	r.state = state
+	r.submittedAt = s.now()
+	r.pull.updatedAt = r.submittedAt
+	return nil
+}
+
+func (s *Server) reviewPayload(r *review) *object {
+	return &object{typename: "Payload", fields: map[string]resolver{
+		"pullRequestReview": constant(s.gqlReview(r)),
+	}}
+}
+
+// addPullRequestReview starts a pending review, or submits one if an event is given.
+func (s *Server) addPullRequestReview(args map[string]any) (any, error) {
+	input := argObject(args, "input")
+	i, err := s.findIssue(argString(input, "pullRequestId"))
+	if err != nil {
+		return nil, err
+	}
+	if i.pull == nil {
+		return nil, queryErrorf("Could not resolve to a PullRequest with the global id of '%s'", argString(input, "pullRequestId"))
+	}
+	for _, r := range i.pull.reviews {
+		if r.user == s.viewer && r.state == "PENDING" {
+			return nil, queryErrorf("User can only have one pending review per pull request")
+		}
+	}
+
+	r := &review{
+		id:       s.newID(),
+		pull:     i,
+		user:     s.viewer,
+		state:    "PENDING",
+		body:     argString(input, "body"),
+		commitID: argString(input, "commitOID"),
+	}
+	if r.commitID == "" {
+		r.commitID = i.pull.headSHA(i.repo)
+	}
+	if event := argString(input, "event"); event != "" {
+		if err := s.submitReview(r, event, ""); err != nil {
+			return nil, err
+		}
+	}
+	i.pull.reviews = append(i.pull.reviews, r)
+	return s.reviewPayload(r), nil
+}
+
+func (s *Server) submitPullRequestReview(args map[string]any) (any, error) {
+	input := argObject(args, "input")
+	r, err := s.findReview(argString(input, "pullRequestReviewId"))
+	if err != nil {
+		return nil, err
+	}
+	if r.state != "PENDING" {
+		return nil, queryErrorf("Review is not pending")
+	}
+	if err := s.submitReview(r, argString(input, "event"), argString(input, "body")); err != nil {
+		return nil, err
+	}
+	return s.reviewPayload(r), nil
+}
+
+// deletePullRequestReview deletes a pending review and its comments.
+func (s *Server) deletePullRequestReview(args map[string]any) (any, error) {
+	input := argObject(args, "input")
+	r, err := s.findReview(argString(input, "pullRequestReviewId"))
+	if err != nil {
+		return nil, err
+	}
+	if r.state != "PENDING" {
+		return nil, queryErrorf("Review is not pending")
+	}
+	p := r.pull.pull
+	p.reviews = slices.DeleteFunc(p.reviews, func(other *review) bool { return other == r })
+	p.comments = slices.DeleteFunc(p.comments, func(c *reviewComment) bool { return c.review == r })
+	return s.reviewPayload(r), nil
+}
+
+// addPullRequestReviewThread comments on a file of a pull request's diff, as part of the viewer's
+// pending review.
+func (s *Server) addPullRequestReviewThread(args map[string]any) (any, error) {
+	input := argObject(args, "input")
+	r, err := s.findReview(argString(input, "pullRequestReviewId"))
+	if err != nil {
+		return nil, err
+	}
+	if r.state != "PENDING" || r.user != s.viewer {
+		return nil, queryErrorf("Review is not pending")
+	}
+	path := argString(input, "path")
+	if !slices.ContainsFunc(r.pull.pull.changes(r.pull.repo), func(change fileChange) bool { return change.filename == path }) {
+		return nil, queryErrorf("Path %q is not part of the pull request's diff", path)
+	}
+	subjectType := argString(input, "subjectType")
+	if subjectType == "" {
+		subjectType = "LINE"
+	}
+	c := &reviewComment{
+		id:          s.newID(),
+		review:      r,
+		path:        path,
+		body:        argString(input, "body"),
+		subjectType: subjectType,
+		side:        argString(input, "side"),
+		line:        argInt(input, "line"),
+		startLine:   argInt(input, "startLine"),
+		commitID:    r.commitID,
+		user:        s.viewer,
+		createdAt:   s.now(),
+	}
+	if c.subjectType == "LINE" && c.line == 0 {
+		return nil, queryErrorf("A line is required for comments on lines")
+	}
+	if c.side == "" && c.line != 0 {
+		c.side = "RIGHT"
+	}
+	r.pull.pull.comments = append(r.pull.pull.comments, c)
+	return &object{typename: "AddPullRequestReviewThreadPayload", fields: map[string]resolver{
+		"thread": constant(s.gqlReviewThread(c)),
+	}}, nil
+}
+
+// replaceActorsForAssignable replaces the assignees of an issue or pull request.
+func (s *Server) replaceActorsForAssignable(args map[string]any) (any, error) {
+	input := argObject(args, "input")
+	i, err := s.findIssue(argString(input, "assignableId"))
+	if err != nil {
+		return nil, err
+	}
+	var assignees []*user
+	for _, id := range argStrings(input, "actorIds") {
+		actor, err := s.findActor(id)
+		if err != nil {
+			return nil, err
+		}
+		assignees = append(assignees, actor)
+	}
+	i.assignees = assignees
+	i.updatedAt = s.now()
+	return &object{typename: "ReplaceActorsForAssignablePayload", fields: map[string]resolver{
+		"assignable": constant(s.gqlIssue(i)),
+	}}, nil
+}
+
+func (s *Server) issuePayload(i *issue) *object {
+	return &object{typename: "Payload", fields: map[string]resolver{
+		"issue": constant(s.gqlIssue(i)),
+	}}
+}
+
+func (s *Server) closeIssue(args map[string]any) (any, error) {
+	input := argObject(args, "input")
+	i, err := s.findIssue(argString(input, "issueId"))
+	if err != nil {
+		return nil, err
+	}
+	if duplicateID := argString(input, "duplicateIssueId"); duplicateID != "" {
+		if _, err := s.findIssue(duplicateID); err != nil {
+			return nil, err
+		}
+	}
+	stateReason := strings.ToLower(argString(input, "stateReason"))
+	if stateReason == "" {
+		stateReason = "completed"
+	}
+	i.state = "closed"
+	i.stateReason = stateReason
+	i.closedAt = s.now()
+	i.updatedAt = i.closedAt
+	return s.issuePayload(i), nil
+}
+
+func (s *Server) reopenIssue(args map[string]any) (any, error) {
+	i, err := s.findIssue(argString(argObject(args, "input"), "issueId"))
+	if err != nil {
+		return nil, err
+	}
+	i.state = "open"
+	i.stateReason = "reopened"
+	i.closedAt = time.Time{}
+	i.updatedAt = s.now()
+	return s.issuePayload(i), nil
+}
+
+// setDraft returns a mutation that converts a pull request to a draft, or marks it ready for review.
+func (s *Server) setDraft(draft bool) resolver {
+	return func(args map[string]any) (any, error) {
+		id := argString(argObject(args, "input"), "pullRequestId")
+		i, err := s.findIssue(id)
+		if err != nil {
+			return nil, err
+		}
+		if i.pull == nil {
+			return nil, queryErrorf("Could not resolve to a PullRequest with the global id of '%s'", id)
+		}
+		i.pull.draft = draft
+		i.updatedAt = s.now()
+		return &object{typename: "Payload", fields: map[string]resolver{
+			"pullRequest": constant(s.gqlIssue(i)),
+		}}, nil
+	}
+}
@@ -0,0 +1,299 @@
+package githubfake
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v79/github"
+)
+
+func (s *Server) registerIssueRoutes() {
+	s.route("GET /repos/{owner}/{repo}/issues", s.listIssues)
+	s.route("POST /repos/{owner}/{repo}/issues", s.createIssue)
+	s.route("GET /repos/{owner}/{repo}/issues/{number}", s.getIssue)
+	s.route("PATCH /repos/{owner}/{repo}/issues/{number}", s.editIssue)
+	s.route("GET /repos/{owner}/{repo}/issues/{number}/comments", s.listIssueComments)
+	s.route("POST /repos/{owner}/{repo}/issues/{number}/comments", s.createIssueComment)
+}
+
+func (s *Server) restIssue(i *issue) *github.Issue {
+	path := "issues"
+	if i.pull != nil {
+		path = "pull"
+	}
+	labels := make([]*github.Label, 0, len(i.labels))
+	for _, name := range i.labels {
+		labels = append(labels, &github.Label{
+			Name:  github.Ptr(name),
+			URL:   github.Ptr(s.apiURL("repos/" + i.repo.fullName() + "/labels/" + name)),
+			Color: github.Ptr("ededed"),
+		})
+	}
+	result := &github.Issue{
+		ID:        github.Ptr(i.id),
+		NodeID:    github.Ptr(i.nodeID()),
+		Number:    github.Ptr(i.number),
+		Title:     github.Ptr(i.title),
+		Body:      github.Ptr(i.body),
+		State:     github.Ptr(i.state),
+		User:      s.restUser(i.user),
+		Labels:    labels,
+		Assignees: s.restUsers(i.assignees),
+		Comments:  github.Ptr(len(i.comments)),
+		CreatedAt: timestamp(i.createdAt),
+		UpdatedAt: timestamp(i.updatedAt),
+		ClosedAt:  timestamp(i.closedAt),
+		HTMLURL:   github.Ptr(s.htmlURL(i.repo.fullName() + "/" + path + "/" + strconv.Itoa(i.number))),
+		URL:       github.Ptr(s.apiURL("repos/" + i.repo.fullName() + "/issues/" + strconv.Itoa(i.number))),
+
+		RepositoryURL: github.Ptr(s.apiURL("repos/" + i.repo.fullName())),
+	}
+	if i.stateReason != "" {
+		result.StateReason = github.Ptr(i.stateReason)
+	}
+	if len(i.assignees) > 0 {
+		result.Assignee = result.Assignees[0]
+	}
+	if i.pull != nil {
+		result.PullRequestLinks = &github.PullRequestLinks{
+			URL:     github.Ptr(s.apiURL("repos/" + i.repo.fullName() + "/pulls/" + strconv.Itoa(i.number))),
+			HTMLURL: result.HTMLURL,
+		}
+	}
+	return result
+}
+
+func (s *Server) restIssueComment(i *issue, c *issueComment) *github.IssueComment {
+	return &github.IssueComment{
+		ID:        github.Ptr(c.id),
+		NodeID:    github.Ptr(fmtNodeID("IC", c.id)),
+		Body:      github.Ptr(c.body),
+		User:      s.restUser(c.user),
+		CreatedAt: timestamp(c.createdAt),
+		UpdatedAt: timestamp(c.createdAt),
+		HTMLURL:   github.Ptr(s.htmlURL(i.repo.fullName() + "/issues/" + strconv.Itoa(i.number) + "#issuecomment-" + strconv.FormatInt(c.id, 10))),
+		IssueURL:  github.Ptr(s.apiURL("repos/" + i.repo.fullName() + "/issues/" + strconv.Itoa(i.number))),
+	}
+}
+
+// sortedIssues returns a repository's issues and pull requests, newest first.
+func (r *repository) sortedIssues() []*issue {
+	issues := make([]*issue, 0, len(r.issues))
+	for _, i := range r.issues {
+		issues = append(issues, i)
+	}
+	sort.Slice(issues, func(a, b int) bool {
+		return issues[a].number > issues[b].number
+	})
+	return issues
+}
+
+// requestIssue returns the issue or pull request numbered by the request, writing a 404 if there is none.
+func (s *Server) requestIssue(w http.ResponseWriter, r *http.Request) (*issue, bool) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return nil, false
+	}
+	number, ok := pathNumber(w, r, "number")
+	if !ok {
+		return nil, false
+	}
+	i, ok := repo.issues[number]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+	return i, ok
+}
+
+// newIssue adds an open issue, or the issue of a pull request, to a repository.
+func (s *Server) newIssue(repo *repository, title, body string) *issue {
+	i := &issue{
+		id:        s.newID(),
+		number:    repo.nextNumber,
+		repo:      repo,
+		title:     title,
+		body:      body,
+		state:     "open",
+		user:      s.viewer,
+		createdAt: s.now(),
+		updatedAt: s.now(),
+	}
+	repo.issues[i.number] = i
+	repo.nextNumber++
+	return i
+}
+
+// usersByLogin looks up users, reporting the first login that isn't one.
+func (s *Server) usersByLogin(logins []string) ([]*user, string, bool) {
+	users := make([]*user, 0, len(logins))
+	for _, login := range logins {
+		u, ok := s.users[strings.ToLower(login)]
+		if !ok {
+			return nil, login, false
+		}
+		users = append(users, u)
+	}
+	return users, "", true
+}
+
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		state = "open"
+	}
+	var labels []string
+	if query.Get("labels") != "" {
+		labels = strings.Split(query.Get("labels"), ",")
+	}
+
+	issues := []*github.Issue{}
+	for _, i := range repo.sortedIssues() {
+		if state != "all" && i.state != state {
+			continue
+		}
+		if !containsAll(i.labels, labels) {
+			continue
+		}
+		issues = append(issues, s.restIssue(i))
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), issues))
+}
+
+// containsAll reports whether names include every one of want, ignoring case.
+func containsAll(names, want []string) bool {
+	for _, name := range want {
+		if !slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) }) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Title     string   `json:"title"`
+		Body      string   `json:"body"`
+		Labels    []string `json:"labels"`
+		Assignees []string `json:"assignees"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Title == "" {
+		writeValidationError(w, "Validation Failed", "title is missing")
+		return
+	}
+	assignees, login, ok := s.usersByLogin(body.Assignees)
+	if !ok {
+		writeValidationError(w, "Validation Failed", login+" is not a valid assignee")
+		return
+	}
+
+	i := s.newIssue(repo, body.Title, body.Body)
+	i.labels = body.Labels
+	i.assignees = assignees
+	writeJSON(w, http.StatusCreated, s.restIssue(i))
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestIssue(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.restIssue(i))
+}
+
+func (s *Server) editIssue(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestIssue(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Title       *string   `json:"title"`
+		Body        *string   `json:"body"`
+		State       *string   `json:"state"`
+		StateReason *string   `json:"state_reason"`
+		Labels      *[]string `json:"labels"`
+		Assignees   *[]string `json:"assignees"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Assignees != nil {
+		assignees, login, ok := s.usersByLogin(*body.Assignees)
+		if !ok {
+			writeValidationError(w, "Validation Failed", login+" is not a valid assignee")
+			return
+		}
+		i.assignees = assignees
+	}
+	if body.Title != nil {
+		i.title = *body.Title
+	}
+	if body.Body != nil {
+		i.body = *body.Body
+	}
+	if body.Labels != nil {
+		i.labels = *body.Labels
+	}
+	if body.State != nil && *body.State != i.state {
+		i.state = *body.State
+		i.closedAt = s.now()
+		i.stateReason = "completed"
+		if i.state == "open" {
+			i.closedAt = time.Time{}
+			i.stateReason = "reopened"
+		}
+	}
+	if body.StateReason != nil {
+		i.stateReason = *body.StateReason
+	}
+	i.updatedAt = s.now()
+	writeJSON(w, http.StatusOK, s.restIssue(i))
+}
+
+func (s *Server) listIssueComments(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestIssue(w, r)
+	if !ok {
+		return
+	}
+	comments := make([]*github.IssueComment, 0, len(i.comments))
+	for _, c := range i.comments {
+		comments = append(comments, s.restIssueComment(i, c))
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), comments))
+}
+
+func (s *Server) createIssueComment(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestIssue(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Body string `json:"body"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Body == "" {
+		writeValidationError(w, "Validation Failed", "body is missing")
+		return
+	}
+	c := &issueComment{id: s.newID(), body: body.Body, user: s.viewer, createdAt: s.now()}
+	i.comments = append(i.comments, c)
+	i.updatedAt = c.createdAt
+	writeJSON(w, http.StatusCreated, s.restIssueComment(i, c))
+}
@@ -0,0 +1,541 @@
+package githubfake
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v79/github"
+)
+
+func (s *Server) registerPullRequestRoutes() {
+	s.route("GET /repos/{owner}/{repo}/pulls", s.listPullRequests)
+	s.route("POST /repos/{owner}/{repo}/pulls", s.createPullRequest)
+	s.route("GET /repos/{owner}/{repo}/pulls/{number}", s.getPullRequest)
+	s.route("PATCH /repos/{owner}/{repo}/pulls/{number}", s.editPullRequest)
+	s.route("GET /repos/{owner}/{repo}/pulls/{number}/files", s.listPullRequestFiles)
+	s.route("GET /repos/{owner}/{repo}/pulls/{number}/comments", s.listReviewComments)
+	s.route("GET /repos/{owner}/{repo}/pulls/{number}/reviews", s.listReviews)
+	s.route("GET /repos/{owner}/{repo}/pulls/{number}/reviews/{id}/comments", s.listCommentsOfReview)
+	s.route("GET /repos/{owner}/{repo}/pulls/{number}/requested_reviewers", s.listRequestedReviewers)
+	s.route("POST /repos/{owner}/{repo}/pulls/{number}/requested_reviewers", s.requestReviewers)
+	s.route("PUT /repos/{owner}/{repo}/pulls/{number}/merge", s.mergePullRequest)
+	s.route("PUT /repos/{owner}/{repo}/pulls/{number}/update-branch", s.updatePullRequestBranch)
+}
+
+// headSHA returns the commit the head branch of a pull request points to, or "" once it's deleted.
+func (p *pullRequest) headSHA(repo *repository) string {
+	return repo.refs["refs/heads/"+p.head]
+}
+
+// mergeBase returns the newest commit reachable from both a and b, or "" if there is none.
+func mergeBase(repo *repository, a, b string) string {
+	for _, c := range repo.history(a) {
+		if isAncestor(repo, c.sha, b) {
+			return c.sha
+		}
+	}
+	return ""
+}
+
+// changes returns the changes a pull request makes to its base branch.
+func (p *pullRequest) changes(repo *repository) []fileChange {
+	head, base := p.headSHA(repo), repo.refs["refs/heads/"+p.base]
+	if head == "" || base == "" {
+		return nil
+	}
+	var baseTree string
+	if mb := mergeBase(repo, head, base); mb != "" {
+		baseTree = repo.commits[mb].tree
+	}
+	return repo.diffTrees(baseTree, repo.commits[head].tree)
+}
+
+// commitCount returns the number of commits on the head branch that aren't on the base branch.
+func (p *pullRequest) commitCount(repo *repository) int {
+	base := repo.refs["refs/heads/"+p.base]
+	var count int
+	for _, c := range repo.history(p.headSHA(repo)) {
+		if base == "" || !isAncestor(repo, c.sha, base) {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) restPullRequestBranch(repo *repository, branch, sha string) *github.PullRequestBranch {
+	return &github.PullRequestBranch{
+		Label: github.Ptr(repo.owner.login + ":" + branch),
+		Ref:   github.Ptr(branch),
+		SHA:   github.Ptr(sha),
+		Repo:  s.restRepository(repo),
+		User:  s.restUser(repo.owner),
+	}
+}
+
+func (s *Server) restPullRequest(i *issue) *github.PullRequest {
+	repo, p := i.repo, i.pull
+	number := strconv.Itoa(i.number)
+	issue := s.restIssue(i)
+
+	changes := p.changes(repo)
+	var additions, deletions int
+	for _, change := range changes {
+		additions += change.additions
+		deletions += change.deletions
+	}
+	result := &github.PullRequest{
+		ID:                 github.Ptr(i.id),
+		NodeID:             github.Ptr(i.nodeID()),
+		Number:             github.Ptr(i.number),
+		State:              github.Ptr(i.state),
+		Title:              github.Ptr(i.title),
+		Body:               github.Ptr(i.body),
+		Draft:              github.Ptr(p.draft),
+		Merged:             github.Ptr(p.merged),
+		Mergeable:          github.Ptr(!p.merged && i.state == "open"),
+		User:               issue.User,
+		Labels:             issue.Labels,
+		Assignees:          issue.Assignees,
+		Assignee:           issue.Assignee,
+		RequestedReviewers: s.restUsers(p.requestedReviewers),
+		Head:               s.restPullRequestBranch(repo, p.head, p.headSHA(repo)),
+		Base:               s.restPullRequestBranch(repo, p.base, repo.refs["refs/heads/"+p.base]),
+		Commits:            github.Ptr(p.commitCount(repo)),
+		Additions:          github.Ptr(additions),
+		Deletions:          github.Ptr(deletions),
+		ChangedFiles:       github.Ptr(len(changes)),
+		Comments:           issue.Comments,
+		ReviewComments:     github.Ptr(len(p.comments)),
+		CreatedAt:          issue.CreatedAt,
+		UpdatedAt:          issue.UpdatedAt,
+		ClosedAt:           issue.ClosedAt,
+		MergedAt:           timestamp(p.mergedAt),
+		HTMLURL:            issue.HTMLURL,
+		URL:                github.Ptr(s.apiURL("repos/" + repo.fullName() + "/pulls/" + number)),
+		DiffURL:            github.Ptr(s.htmlURL(repo.fullName() + "/pull/" + number + ".diff")),
+		PatchURL:           github.Ptr(s.htmlURL(repo.fullName() + "/pull/" + number + ".patch")),
+		IssueURL:           issue.URL,
+	}
+	if p.mergeCommit != "" {
+		result.MergeCommitSHA = github.Ptr(p.mergeCommit)
+	}
+	return result
+}
+
+func (s *Server) restReview(r *review) *github.PullRequestReview {
+	repo := r.pull.repo
+	number := strconv.Itoa(r.pull.number)
+	return &github.PullRequestReview{
+		ID:             github.Ptr(r.id),
+		NodeID:         github.Ptr(r.nodeID()),
+		User:           s.restUser(r.user),
+		Body:           github.Ptr(r.body),
+		State:          github.Ptr(r.state),
+		CommitID:       github.Ptr(r.commitID),
+		SubmittedAt:    timestamp(r.submittedAt),
+		HTMLURL:        github.Ptr(s.htmlURL(repo.fullName() + "/pull/" + number + "#pullrequestreview-" + strconv.FormatInt(r.id, 10))),
+		PullRequestURL: github.Ptr(s.apiURL("repos/" + repo.fullName() + "/pulls/" + number)),
+	}
+}
+
+func (s *Server) restReviewComment(c *reviewComment) *github.PullRequestComment {
+	repo := c.review.pull.repo
+	number := strconv.Itoa(c.review.pull.number)
+	result := &github.PullRequestComment{
+		ID:                  github.Ptr(c.id),
+		NodeID:              github.Ptr(c.nodeID()),
+		PullRequestReviewID: github.Ptr(c.review.id),
+		Path:                github.Ptr(c.path),
+		Body:                github.Ptr(c.body),
+		SubjectType:         github.Ptr(strings.ToLower(c.subjectType)),
+		CommitID:            github.Ptr(c.commitID),
+		User:                s.restUser(c.user),
+		CreatedAt:           timestamp(c.createdAt),
+		UpdatedAt:           timestamp(c.createdAt),
+		HTMLURL:             github.Ptr(s.htmlURL(repo.fullName() + "/pull/" + number + "#discussion_r" + strconv.FormatInt(c.id, 10))),
+		PullRequestURL:      github.Ptr(s.apiURL("repos/" + repo.fullName() + "/pulls/" + number)),
+	}
+	if c.line != 0 {
+		result.Line = github.Ptr(c.line)
+		result.Side = github.Ptr(c.side)
+	}
+	if c.startLine != 0 {
+		result.StartLine = github.Ptr(c.startLine)
+		result.StartSide = github.Ptr(c.side)
+	}
+	return result
+}
+
+// visibleTo reports whether the viewer can see a review, which they can't while it's pending unless
+// it is theirs.
+func (r *review) visibleTo(viewer *user) bool {
+	return r.state != "PENDING" || r.user == viewer
+}
+
+// requestPullRequest returns the pull request numbered by the request, writing a 404 if there is none.
+func (s *Server) requestPullRequest(w http.ResponseWriter, r *http.Request) (*issue, bool) {
+	i, ok := s.requestIssue(w, r)
+	if ok && i.pull == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return nil, false
+	}
+	return i, ok
+}
+
+func (s *Server) listPullRequests(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		state = "open"
+	}
+	head := query.Get("head")
+	if _, branch, ok := strings.Cut(head, ":"); ok {
+		head = branch
+	}
+
+	pulls := []*github.PullRequest{}
+	for _, i := range repo.sortedIssues() {
+		switch {
+		case i.pull == nil,
+			state != "all" && i.state != state,
+			head != "" && i.pull.head != head,
+			query.Get("base") != "" && i.pull.base != query.Get("base"):
+			continue
+		}
+		pulls = append(pulls, s.restPullRequest(i))
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), pulls))
+}
+
+func (s *Server) createPullRequest(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+		Draft bool   `json:"draft"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if _, branch, ok := strings.Cut(body.Head, ":"); ok {
+		body.Head = branch
+	}
+	head, headOK := repo.refs["refs/heads/"+body.Head]
+	base, baseOK := repo.refs["refs/heads/"+body.Base]
+	switch {
+	case body.Title == "":
+		writeValidationError(w, "Validation Failed", "title is missing")
+		return
+	case !headOK:
+		writeValidationError(w, "Validation Failed", "head is invalid")
+		return
+	case !baseOK:
+		writeValidationError(w, "Validation Failed", "base is invalid")
+		return
+	case isAncestor(repo, head, base):
+		writeValidationError(w, "Validation Failed", fmt.Sprintf("No commits between %s and %s", body.Base, body.Head))
+		return
+	}
+	for _, i := range repo.issues {
+		if i.pull != nil && i.state == "open" && i.pull.head == body.Head && i.pull.base == body.Base {
+			writeValidationError(w, "Validation Failed", "A pull request already exists for "+repo.owner.login+":"+body.Head+".")
+			return
+		}
+	}
+
+	i := s.newIssue(repo, body.Title, body.Body)
+	i.pull = &pullRequest{head: body.Head, base: body.Base, draft: body.Draft}
+	writeJSON(w, http.StatusCreated, s.restPullRequest(i))
+}
+
+func (s *Server) getPullRequest(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	if !strings.Contains(r.Header.Get("Accept"), "diff") {
+		writeJSON(w, http.StatusOK, s.restPullRequest(i))
+		return
+	}
+
+	var b strings.Builder
+	for _, change := range i.pull.changes(i.repo) {
+		from, to := "a/"+change.filename, "b/"+change.filename
+		switch change.status {
+		case "added":
+			from = "/dev/null"
+		case "removed":
+			to = "/dev/null"
+		}
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- %s\n+++ %s\n%s\n", change.filename, change.filename, from, to, change.patch)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(b.String()))
+}
+
+func (s *Server) editPullRequest(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+		State *string `json:"state"`
+		Base  *string `json:"base"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Base != nil {
+		if _, ok := i.repo.refs["refs/heads/"+*body.Base]; !ok {
+			writeValidationError(w, "Validation Failed", "base is invalid")
+			return
+		}
+		i.pull.base = *body.Base
+	}
+	if body.Title != nil {
+		i.title = *body.Title
+	}
+	if body.Body != nil {
+		i.body = *body.Body
+	}
+	if body.State != nil && *body.State != i.state && !i.pull.merged {
+		i.state = *body.State
+		i.closedAt = s.now()
+		if i.state == "open" {
+			i.closedAt = time.Time{}
+		}
+	}
+	i.updatedAt = s.now()
+	writeJSON(w, http.StatusOK, s.restPullRequest(i))
+}
+
+func (s *Server) listPullRequestFiles(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	files := s.restCommitFiles(i.repo, i.pull.headSHA(i.repo), i.pull.changes(i.repo))
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), files))
+}
+
+func (s *Server) listReviewComments(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	comments := []*github.PullRequestComment{}
+	for _, c := range i.pull.comments {
+		if c.review.visibleTo(s.viewer) {
+			comments = append(comments, s.restReviewComment(c))
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), comments))
+}
+
+func (s *Server) listReviews(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	reviews := []*github.PullRequestReview{}
+	for _, review := range i.pull.reviews {
+		if review.visibleTo(s.viewer) {
+			reviews = append(reviews, s.restReview(review))
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), reviews))
+}
+
+func (s *Server) listCommentsOfReview(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	comments := []*github.PullRequestComment{}
+	for _, c := range i.pull.comments {
+		if c.review.id == id && c.review.visibleTo(s.viewer) {
+			comments = append(comments, s.restReviewComment(c))
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), comments))
+}
+
+func (s *Server) listRequestedReviewers(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, &github.Reviewers{
+		Users: s.restUsers(i.pull.requestedReviewers),
+		Teams: []*github.Team{},
+	})
+}
+
+func (s *Server) requestReviewers(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Reviewers []string `json:"reviewers"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	reviewers, login, ok := s.usersByLogin(body.Reviewers)
+	if !ok {
+		writeValidationError(w, "Reviews may only be requested from collaborators. One or more of the users or teams you specified is not a collaborator of the "+i.repo.fullName()+" repository.", login+" is not a collaborator")
+		return
+	}
+	for _, reviewer := range reviewers {
+		if reviewer == i.user {
+			writeValidationError(w, "Review cannot be requested from pull request author.", reviewer.login+" is the author")
+			return
+		}
+		if !slices.Contains(i.pull.requestedReviewers, reviewer) {
+			i.pull.requestedReviewers = append(i.pull.requestedReviewers, reviewer)
+		}
+	}
+	i.updatedAt = s.now()
+	writeJSON(w, http.StatusCreated, s.restPullRequest(i))
+}
+
+// mergeChanges returns the changes to apply to the base branch to merge the head branch into it,
+// or the path of a conflicting file.
+func mergeChanges(repo *repository, base, head string) (map[string][]byte, string, bool) {
+	var mergeBaseTree string
+	if mb := mergeBase(repo, head, base); mb != "" {
+		mergeBaseTree = repo.commits[mb].tree
+	}
+	baseFiles := repo.trees[repo.commits[base].tree]
+	original := repo.trees[mergeBaseTree]
+
+	changes := map[string][]byte{}
+	for _, change := range repo.diffTrees(mergeBaseTree, repo.commits[head].tree) {
+		if baseFiles[change.filename] != original[change.filename] && baseFiles[change.filename] != change.sha {
+			return nil, change.filename, false
+		}
+		changes[change.filename] = nil
+		if change.sha != "" {
+			changes[change.filename] = repo.blobs[change.sha]
+		}
+	}
+	return changes, "", true
+}
+
+func (s *Server) mergePullRequest(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		CommitTitle   string `json:"commit_title"`
+		CommitMessage string `json:"commit_message"`
+		MergeMethod   string `json:"merge_method"`
+		SHA           string `json:"sha"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	repo, p := i.repo, i.pull
+	head, base := p.headSHA(repo), repo.refs["refs/heads/"+p.base]
+	switch {
+	case p.merged || i.state != "open" || head == "" || base == "":
+		writeError(w, http.StatusMethodNotAllowed, "Pull Request is not mergeable")
+		return
+	case p.draft:
+		writeError(w, http.StatusMethodNotAllowed, "Pull Request is still a draft")
+		return
+	case body.SHA != "" && body.SHA != head:
+		writeError(w, http.StatusConflict, "Head branch was modified. Review and try the merge again.")
+		return
+	}
+	changes, conflict, ok := mergeChanges(repo, base, head)
+	if !ok {
+		writeError(w, http.StatusMethodNotAllowed, "Merge conflict in "+conflict)
+		return
+	}
+
+	// Every merge method is recorded as a single commit on the base branch, as the fake doesn't
+	// distinguish between them
+	message := body.CommitTitle
+	if message == "" {
+		message = fmt.Sprintf("Merge pull request #%d from %s/%s", i.number, repo.owner.login, p.head)
+	}
+	if body.CommitMessage != "" {
+		message += "\n\n" + body.CommitMessage
+	}
+	c := repo.commitChanges(base, changes, message, s.viewer, s.now())
+	repo.refs["refs/heads/"+p.base] = c.sha
+
+	p.merged = true
+	p.mergedAt = c.date
+	p.mergeCommit = c.sha
+	i.state = "closed"
+	i.closedAt = c.date
+	i.updatedAt = c.date
+	writeJSON(w, http.StatusOK, &github.PullRequestMergeResult{
+		SHA:     github.Ptr(c.sha),
+		Merged:  github.Ptr(true),
+		Message: github.Ptr("Pull Request successfully merged"),
+	})
+}
+
+func (s *Server) updatePullRequestBranch(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.requestPullRequest(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		ExpectedHeadSHA string `json:"expected_head_sha"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	repo, p := i.repo, i.pull
+	head, base := p.headSHA(repo), repo.refs["refs/heads/"+p.base]
+	switch {
+	case head == "" || base == "":
+		writeValidationError(w, "Validation Failed", "head or base branch is missing")
+		return
+	case body.ExpectedHeadSHA != "" && body.ExpectedHeadSHA != head:
+		writeValidationError(w, "expected head sha didn't match current head ref.", "expected_head_sha is stale")
+		return
+	case isAncestor(repo, base, head):
+		writeValidationError(w, "There are no new commits on the base branch.", "head is up to date")
+		return
+	}
+	changes, conflict, ok := mergeChanges(repo, head, base)
+	if !ok {
+		writeValidationError(w, "merge conflict between base and head", conflict+" conflicts")
+		return
+	}
+	treeSHA := repo.applyChanges(repo.commits[head].tree, changes)
+	c := repo.writeCommit(treeSHA, []string{head, base}, fmt.Sprintf("Merge branch '%s' into %s", p.base, p.head), s.viewer, s.now())
+	repo.refs["refs/heads/"+p.head] = c.sha
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "Updating pull request branch.",
+		"url":     s.htmlURL(repo.fullName() + "/pull/" + strconv.Itoa(i.number)),
+	})
+}
@@ -0,0 +1,37 @@
+package githubfake
+
+import (
+	"net/http"
+	"strings"
+)
+
+// serveRaw serves file contents at /raw/{owner}/{repo}/{ref or SHA}/{path}, like the raw content API.
+func (s *Server) serveRaw(w http.ResponseWriter, r *http.Request) {
+	notFound := func() {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("404: Not Found"))
+	}
+
+	repo, ok := s.repository(r.PathValue("owner"), r.PathValue("repo"))
+	if !ok {
+		notFound()
+		return
+	}
+	sha, filePath, ok := repo.resolvePath(r.PathValue("path"))
+	if !ok {
+		notFound()
+		return
+	}
+	blob, ok := repo.trees[repo.commits[sha].tree][strings.Trim(filePath, "/")]
+	if !ok {
+		notFound()
+		return
+	}
+
+	// Like the raw content API, text is served as text/plain whatever its type
+	content := repo.blobs[blob]
+	w.Header().Set("Content-Type", http.DetectContentType(content))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
@@ -0,0 +1,535 @@
+package githubfake
+
+import (
+	"encoding/base64"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v79/github"
+)
+
+func (s *Server) registerRepositoryRoutes() {
+	s.route("POST /user/repos", s.createRepository)
+	s.route("POST /orgs/{org}/repos", s.createRepository)
+	s.route("GET /repos/{owner}/{repo}", s.getRepository)
+	s.route("DELETE /repos/{owner}/{repo}", s.deleteRepository)
+	s.route("GET /repos/{owner}/{repo}/branches", s.listBranches)
+	s.route("GET /repos/{owner}/{repo}/branches/{branch...}", s.getBranch)
+	s.route("GET /repos/{owner}/{repo}/tags", s.listTags)
+	s.route("GET /repos/{owner}/{repo}/commits", s.listCommits)
+	s.route("GET /repos/{owner}/{repo}/commits/{sha}", s.getCommit)
+	s.route("GET /repos/{owner}/{repo}/commits/{ref}/status", s.getCombinedStatus)
+	s.route("GET /repos/{owner}/{repo}/contents/{path...}", s.getContents)
+	s.route("PUT /repos/{owner}/{repo}/contents/{path...}", s.putContents)
+	s.route("DELETE /repos/{owner}/{repo}/contents/{path...}", s.deleteContents)
+}
+
+func (s *Server) restRepository(repo *repository) *github.Repository {
+	visibility := "public"
+	if repo.private {
+		visibility = "private"
+	}
+	return &github.Repository{
+		ID:            github.Ptr(repo.id),
+		NodeID:        github.Ptr(fmtNodeID("R", repo.id)),
+		Name:          github.Ptr(repo.name),
+		FullName:      github.Ptr(repo.fullName()),
+		Owner:         s.restUser(repo.owner),
+		Private:       github.Ptr(repo.private),
+		Visibility:    github.Ptr(visibility),
+		Description:   github.Ptr(repo.description),
+		DefaultBranch: github.Ptr(repo.defaultBranch),
+		Fork:          github.Ptr(false),
+		Archived:      github.Ptr(false),
+		HTMLURL:       github.Ptr(s.htmlURL(repo.fullName())),
+		CloneURL:      github.Ptr(s.htmlURL(repo.fullName() + ".git")),
+		URL:           github.Ptr(s.apiURL("repos/" + repo.fullName())),
+		CreatedAt:     timestamp(repo.createdAt),
+		UpdatedAt:     timestamp(repo.createdAt),
+		Permissions:   map[string]bool{"admin": true, "push": true, "pull": true},
+	}
+}
+
+// restGitCommit is a commit as returned by the git data API.
+func (s *Server) restGitCommit(repo *repository, c *commit) *github.Commit {
+	author := &github.CommitAuthor{
+		Name:  github.Ptr(c.author.name),
+		Email: github.Ptr(c.author.login + "@users.noreply.github.com"),
+		Date:  timestamp(c.date),
+		Login: github.Ptr(c.author.login),
+	}
+	parents := make([]*github.Commit, 0, len(c.parents))
+	for _, parent := range c.parents {
+		parents = append(parents, &github.Commit{
+			SHA: github.Ptr(parent),
+			URL: github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/commits/" + parent)),
+		})
+	}
+	return &github.Commit{
+		SHA:       github.Ptr(c.sha),
+		NodeID:    github.Ptr("C_" + c.sha),
+		Message:   github.Ptr(c.message),
+		Author:    author,
+		Committer: author,
+		Tree: &github.Tree{
+			SHA: github.Ptr(c.tree),
+		},
+		Parents: parents,
+		URL:     github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/commits/" + c.sha)),
+		HTMLURL: github.Ptr(s.htmlURL(repo.fullName() + "/commit/" + c.sha)),
+	}
+}
+
+// restRepositoryCommit is a commit as returned by the commits API, with its files if withFiles is set.
+func (s *Server) restRepositoryCommit(repo *repository, c *commit, withFiles bool) *github.RepositoryCommit {
+	gitCommit := s.restGitCommit(repo, c)
+	result := &github.RepositoryCommit{
+		SHA:       github.Ptr(c.sha),
+		NodeID:    gitCommit.NodeID,
+		Commit:    gitCommit,
+		Author:    s.restUser(c.author),
+		Committer: s.restUser(c.author),
+		Parents:   gitCommit.Parents,
+		HTMLURL:   gitCommit.HTMLURL,
+		URL:       github.Ptr(s.apiURL("repos/" + repo.fullName() + "/commits/" + c.sha)),
+	}
+	if withFiles {
+		result.Files = s.restCommitFiles(repo, c.sha, repo.commitChangesOf(c))
+		stats := &github.CommitStats{Additions: github.Ptr(0), Deletions: github.Ptr(0), Total: github.Ptr(0)}
+		for _, file := range result.Files {
+			*stats.Additions += file.GetAdditions()
+			*stats.Deletions += file.GetDeletions()
+			*stats.Total += file.GetChanges()
+		}
+		result.Stats = stats
+	}
+	return result
+}
+
+func (s *Server) restCommitFiles(repo *repository, ref string, changes []fileChange) []*github.CommitFile {
+	files := make([]*github.CommitFile, 0, len(changes))
+	for _, change := range changes {
+		file := &github.CommitFile{
+			Filename:  github.Ptr(change.filename),
+			Status:    github.Ptr(change.status),
+			Additions: github.Ptr(change.additions),
+			Deletions: github.Ptr(change.deletions),
+			Changes:   github.Ptr(change.additions + change.deletions),
+			Patch:     github.Ptr(change.patch),
+			BlobURL:   github.Ptr(s.htmlURL(repo.fullName() + "/blob/" + ref + "/" + change.filename)),
+			RawURL:    github.Ptr(s.RawURL() + repo.fullName() + "/" + ref + "/" + change.filename),
+		}
+		if change.sha != "" {
+			file.SHA = github.Ptr(change.sha)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+func (s *Server) restContent(repo *repository, ref, filePath, blobSHA string, withContent bool) *github.RepositoryContent {
+	content := &github.RepositoryContent{
+		Type:    github.Ptr("file"),
+		Name:    github.Ptr(path.Base(filePath)),
+		Path:    github.Ptr(filePath),
+		SHA:     github.Ptr(blobSHA),
+		Size:    github.Ptr(len(repo.blobs[blobSHA])),
+		URL:     github.Ptr(s.apiURL("repos/" + repo.fullName() + "/contents/" + filePath + "?ref=" + ref)),
+		HTMLURL: github.Ptr(s.htmlURL(repo.fullName() + "/blob/" + ref + "/" + filePath)),
+		GitURL:  github.Ptr(s.apiURL("repos/" + repo.fullName() + "/git/blobs/" + blobSHA)),
+
+		DownloadURL: github.Ptr(s.RawURL() + repo.fullName() + "/" + ref + "/" + filePath),
+	}
+	if withContent {
+		content.Encoding = github.Ptr("base64")
+		content.Content = github.Ptr(base64.StdEncoding.EncodeToString(repo.blobs[blobSHA]))
+	}
+	return content
+}
+
+func (s *Server) createRepository(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Private     bool   `json:"private"`
+		AutoInit    bool   `json:"auto_init"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Name == "" {
+		writeValidationError(w, "Repository creation failed.", "name is missing")
+		return
+	}
+	owner := s.viewer
+	if org := r.PathValue("org"); org != "" {
+		owner = s.userByLogin(org)
+	}
+	if _, ok := s.repository(owner.login, body.Name); ok {
+		writeValidationError(w, "Repository creation failed.", "name already exists on this account")
+		return
+	}
+
+	repo := s.newRepository(owner, body.Name, body.Description, body.Private)
+	if body.AutoInit {
+		c := repo.commitChanges("", map[string][]byte{"README.md": []byte("# " + body.Name + "\n")}, "Initial commit", s.viewer, s.now())
+		repo.refs["refs/heads/"+repo.defaultBranch] = c.sha
+	}
+	writeJSON(w, http.StatusCreated, s.restRepository(repo))
+}
+
+func (s *Server) getRepository(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.restRepository(repo))
+}
+
+func (s *Server) deleteRepository(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	delete(s.repos, strings.ToLower(repo.fullName()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// refNames returns the names of the refs with a prefix, without it, sorted.
+func (r *repository) refNames(prefix string) []string {
+	var names []string
+	for ref := range r.refs {
+		if name, ok := strings.CutPrefix(ref, prefix); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Server) restBranch(repo *repository, name string) *github.Branch {
+	sha := repo.refs["refs/heads/"+name]
+	return &github.Branch{
+		Name:      github.Ptr(name),
+		Protected: github.Ptr(false),
+		Commit: &github.RepositoryCommit{
+			SHA: github.Ptr(sha),
+			URL: github.Ptr(s.apiURL("repos/" + repo.fullName() + "/commits/" + sha)),
+		},
+	}
+}
+
+func (s *Server) listBranches(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var branches []*github.Branch
+	for _, name := range repo.refNames("refs/heads/") {
+		branches = append(branches, s.restBranch(repo, name))
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), branches))
+}
+
+func (s *Server) getBranch(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	name := r.PathValue("branch")
+	if _, ok := repo.refs["refs/heads/"+name]; !ok {
+		writeError(w, http.StatusNotFound, "Branch not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.restBranch(repo, name))
+}
+
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var tags []*github.RepositoryTag
+	for _, name := range repo.refNames("refs/tags/") {
+		sha := repo.peel(repo.refs["refs/tags/"+name])
+		tags = append(tags, &github.RepositoryTag{
+			Name: github.Ptr(name),
+			Commit: &github.Commit{
+				SHA: github.Ptr(sha),
+				URL: github.Ptr(s.apiURL("repos/" + repo.fullName() + "/commits/" + sha)),
+			},
+			ZipballURL: github.Ptr(s.apiURL("repos/" + repo.fullName() + "/zipball/refs/tags/" + name)),
+			TarballURL: github.Ptr(s.apiURL("repos/" + repo.fullName() + "/tarball/refs/tags/" + name)),
+		})
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), tags))
+}
+
+// requestCommit resolves a ref or SHA to a commit, writing an error if it can't be.
+func requestCommit(w http.ResponseWriter, repo *repository, ref string) (*commit, bool) {
+	if ref == "" {
+		ref = repo.defaultBranch
+	}
+	sha, ok := repo.resolve(ref)
+	if !ok {
+		if len(repo.refs) == 0 {
+			writeError(w, http.StatusConflict, "Git Repository is empty.")
+		} else {
+			writeError(w, http.StatusNotFound, "No commit found for SHA: "+ref)
+		}
+		return nil, false
+	}
+	return repo.commits[sha], true
+}
+
+func (s *Server) listCommits(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	head, ok := requestCommit(w, repo, query.Get("sha"))
+	if !ok {
+		return
+	}
+
+	commits := []*github.RepositoryCommit{}
+	for _, c := range repo.history(head.sha) {
+		if author := query.Get("author"); author != "" && !strings.EqualFold(author, c.author.login) {
+			continue
+		}
+		if filePath := query.Get("path"); filePath != "" && !touches(repo.commitChangesOf(c), filePath) {
+			continue
+		}
+		commits = append(commits, s.restRepositoryCommit(repo, c, false))
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, s.URL(), commits))
+}
+
+// touches reports whether changes include a file at or below a path.
+func touches(changes []fileChange, filePath string) bool {
+	filePath = strings.Trim(filePath, "/")
+	for _, change := range changes {
+		if change.filename == filePath || strings.HasPrefix(change.filename, filePath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) getCommit(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	c, ok := requestCommit(w, repo, r.PathValue("sha"))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.restRepositoryCommit(repo, c, true))
+}
+
+// getCombinedStatus reports no statuses, as the fake runs no checks.
+func (s *Server) getCombinedStatus(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	c, ok := requestCommit(w, repo, r.PathValue("ref"))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, &github.CombinedStatus{
+		State:         github.Ptr("pending"),
+		SHA:           github.Ptr(c.sha),
+		TotalCount:    github.Ptr(0),
+		Statuses:      []*github.RepoStatus{},
+		RepositoryURL: github.Ptr(s.apiURL("repos/" + repo.fullName())),
+	})
+}
+
+func (s *Server) getContents(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		ref = repo.defaultBranch
+	}
+	c, ok := requestCommit(w, repo, ref)
+	if !ok {
+		return
+	}
+	files := repo.trees[c.tree]
+	filePath := strings.Trim(r.PathValue("path"), "/")
+
+	if sha, ok := files[filePath]; ok {
+		writeJSON(w, http.StatusOK, s.restContent(repo, ref, filePath, sha, true))
+		return
+	}
+
+	// Otherwise list the directory's files and subdirectories
+	prefix := filePath + "/"
+	if filePath == "" {
+		prefix = ""
+	}
+	entries := []*github.RepositoryContent{}
+	dirs := map[string]bool{}
+	for _, name := range sortedPaths(files) {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if dir, _, isNested := strings.Cut(rest, "/"); isNested {
+			if !dirs[dir] {
+				dirs[dir] = true
+				entries = append(entries, &github.RepositoryContent{
+					Type:    github.Ptr("dir"),
+					Name:    github.Ptr(dir),
+					Path:    github.Ptr(prefix + dir),
+					SHA:     github.Ptr(repo.writeTree(subtree(files, prefix+dir+"/"))),
+					Size:    github.Ptr(0),
+					URL:     github.Ptr(s.apiURL("repos/" + repo.fullName() + "/contents/" + prefix + dir + "?ref=" + ref)),
+					HTMLURL: github.Ptr(s.htmlURL(repo.fullName() + "/tree/" + ref + "/" + prefix + dir)),
+				})
+			}
+			continue
+		}
+		entries = append(entries, s.restContent(repo, ref, name, files[name], false))
+	}
+	if len(entries) == 0 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func sortedPaths(files tree) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// subtree returns the files below a directory prefix, relative to it.
+func subtree(files tree, prefix string) tree {
+	result := tree{}
+	for name, sha := range files {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			result[rest] = sha
+		}
+	}
+	return result
+}
+
+// branchHead returns the commit a branch points to, or "" for the default branch of an empty repository.
+// It writes an error if the branch doesn't exist.
+func branchHead(w http.ResponseWriter, repo *repository, branch string) (string, bool) {
+	sha, ok := repo.refs["refs/heads/"+branch]
+	if !ok && (len(repo.refs) > 0 || branch != repo.defaultBranch) {
+		writeError(w, http.StatusNotFound, "Branch "+branch+" not found")
+		return "", false
+	}
+	return sha, true
+}
+
+func (s *Server) putContents(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Message string `json:"message"`
+		Content string `json:"content"`
+		SHA     string `json:"sha"`
+		Branch  string `json:"branch"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	content, err := base64.StdEncoding.DecodeString(body.Content)
+	if err != nil {
+		writeValidationError(w, "Invalid request.", "content is not valid Base64")
+		return
+	}
+	if body.Branch == "" {
+		body.Branch = repo.defaultBranch
+	}
+	parent, ok := branchHead(w, repo, body.Branch)
+	if !ok {
+		return
+	}
+
+	filePath := strings.Trim(r.PathValue("path"), "/")
+	status := http.StatusCreated
+	if parent != "" {
+		if existing, exists := repo.trees[repo.commits[parent].tree][filePath]; exists {
+			switch {
+			case body.SHA == "":
+				writeValidationError(w, "Invalid request.", `"sha" wasn't supplied.`)
+				return
+			case body.SHA != existing:
+				writeError(w, http.StatusConflict, filePath+" does not match "+body.SHA)
+				return
+			}
+			status = http.StatusOK
+		}
+	}
+
+	c := repo.commitChanges(parent, map[string][]byte{filePath: content}, body.Message, s.viewer, s.now())
+	repo.refs["refs/heads/"+body.Branch] = c.sha
+	writeJSON(w, status, &github.RepositoryContentResponse{
+		Content: s.restContent(repo, body.Branch, filePath, repo.trees[c.tree][filePath], false),
+		Commit:  *s.restGitCommit(repo, c),
+	})
+}
+
+func (s *Server) deleteContents(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.requestRepository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Message string `json:"message"`
+		SHA     string `json:"sha"`
+		Branch  string `json:"branch"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Branch == "" {
+		body.Branch = repo.defaultBranch
+	}
+	parent, ok := branchHead(w, repo, body.Branch)
+	if !ok {
+		return
+	}
+
+	filePath := strings.Trim(r.PathValue("path"), "/")
+	existing, exists := "", false
+	if parent != "" {
+		existing, exists = repo.trees[repo.commits[parent].tree][filePath]
+	}
+	switch {
+	case !exists:
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	case body.SHA == "":
+		writeValidationError(w, "Invalid request.", `"sha" wasn't supplied.`)
+		return
+	case body.SHA != existing:
+		writeError(w, http.StatusConflict, filePath+" does not match "+body.SHA)
+		return
+	}
+
+	c := repo.commitChanges(parent, map[string][]byte{filePath: nil}, body.Message, s.viewer, s.now())
+	repo.refs["refs/heads/"+body.Branch] = c.sha
+	writeJSON(w, http.StatusOK, &github.RepositoryContentResponse{
+		Commit: *s.restGitCommit(repo, c),
+	})
+}
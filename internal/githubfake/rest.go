@@ -0,0 +1,172 @@
+package githubfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v79/github"
+)
+
+func (s *Server) registerREST() {
+	s.route("GET /user", s.getViewer)
+	s.route("GET /users/{username}", s.getUser)
+	s.route("GET /rate_limit", s.getRateLimit)
+
+	s.registerRepositoryRoutes()
+	s.registerGitRoutes()
+	s.registerIssueRoutes()
+	s.registerPullRequestRoutes()
+	s.registerSearchRoutes()
+}
+
+// route registers a REST handler for a method and path relative to the REST base URL.
+func (s *Server) route(pattern string, handler http.HandlerFunc) {
+	method, path, _ := strings.Cut(pattern, " ")
+	s.mux.HandleFunc(method+" "+restPrefix+path, handler)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
+
+// writeValidationError writes a 422 response with a validation error, the way the API reports
+// invalid or conflicting input.
+func writeValidationError(w http.ResponseWriter, message, detail string) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+		"message":           message,
+		"errors":            []map[string]string{{"message": detail}},
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return false
+	}
+	return true
+}
+
+// paginate returns the page of items the request asks for, and links the next and last pages.
+func paginate[T any](w http.ResponseWriter, r *http.Request, baseURL string, items []T) []T {
+	query := r.URL.Query()
+	perPage, _ := strconv.Atoi(query.Get("per_page"))
+	if perPage <= 0 {
+		perPage = 30
+	}
+	perPage = min(perPage, 100)
+	page, _ := strconv.Atoi(query.Get("page"))
+	page = max(page, 1)
+
+	lastPage := max((len(items)+perPage-1)/perPage, 1)
+	if page < lastPage {
+		link := func(page int, rel string) string {
+			query.Set("page", strconv.Itoa(page))
+			return fmt.Sprintf(`<%s%s?%s>; rel="%s"`, baseURL, r.URL.Path, query.Encode(), rel)
+		}
+		w.Header().Set("Link", link(page+1, "next")+", "+link(lastPage, "last"))
+	}
+
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+	return items[start:end]
+}
+
+// requestRepository returns the repository named by the request's owner and repo, writing a 404
+// if there is none.
+func (s *Server) requestRepository(w http.ResponseWriter, r *http.Request) (*repository, bool) {
+	repo, ok := s.repository(r.PathValue("owner"), r.PathValue("repo"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+	return repo, ok
+}
+
+// pathNumber parses a numeric path value, writing a 404 if it isn't one.
+func pathNumber(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	n, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return 0, false
+	}
+	return n, true
+}
+
+func timestamp(t time.Time) *github.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return &github.Timestamp{Time: t}
+}
+
+func (s *Server) restUser(u *user) *github.User {
+	return &github.User{
+		Login:     github.Ptr(u.login),
+		ID:        github.Ptr(u.id),
+		NodeID:    github.Ptr(u.nodeID()),
+		Type:      github.Ptr(u.typ),
+		Name:      github.Ptr(u.name),
+		HTMLURL:   github.Ptr(s.htmlURL(u.login)),
+		URL:       github.Ptr(s.apiURL("users/" + u.login)),
+		AvatarURL: github.Ptr(s.htmlURL(u.login + ".png")),
+		SiteAdmin: github.Ptr(false),
+	}
+}
+
+func (s *Server) restUsers(users []*user) []*github.User {
+	result := make([]*github.User, 0, len(users))
+	for _, u := range users {
+		result = append(result, s.restUser(u))
+	}
+	return result
+}
+
+func (s *Server) getViewer(w http.ResponseWriter, _ *http.Request) {
+	viewer := s.restUser(s.viewer)
+	var publicRepos int
+	for _, repo := range s.repos {
+		if repo.owner == s.viewer && !repo.private {
+			publicRepos++
+		}
+	}
+	viewer.PublicRepos = github.Ptr(publicRepos)
+	writeJSON(w, http.StatusOK, viewer)
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	u, ok := s.users[strings.ToLower(r.PathValue("username"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.restUser(u))
+}
+
+// getRateLimit reports a full budget for every resource, as the fake doesn't limit requests.
+func (s *Server) getRateLimit(w http.ResponseWriter, _ *http.Request) {
+	reset := s.now().Add(time.Hour).Unix()
+	budget := func(limit int) map[string]int64 {
+		return map[string]int64{"limit": int64(limit), "used": 0, "remaining": int64(limit), "reset": reset}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"resources": map[string]any{
+			"core":    budget(5000),
+			"search":  budget(30),
+			"graphql": budget(5000),
+		},
+		"rate": budget(5000),
+	})
+}
@@ -0,0 +1,250 @@
+package githubfake
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v79/github"
+)
+
+func (s *Server) registerSearchRoutes() {
+	s.route("GET /search/issues", s.searchIssues)
+	s.route("GET /search/repositories", s.searchRepositories)
+	s.route("GET /search/users", s.searchUsers)
+	s.route("GET /search/code", s.searchCode)
+}
+
+// searchQuery is a parsed search query: free text terms, which must all match, and qualifiers.
+type searchQuery struct {
+	terms      []string
+	qualifiers map[string][]string
+}
+
+// parseSearchQuery parses a query, failing on qualifiers outside of supported, which the fake would
+// otherwise silently ignore. The in: qualifier is accepted but ignored, as free text matches any field.
+func parseSearchQuery(w http.ResponseWriter, r *http.Request, supported ...string) (*searchQuery, bool) {
+	q := &searchQuery{qualifiers: map[string][]string{}}
+	for _, token := range splitQuery(r.URL.Query().Get("q")) {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || value == "" {
+			q.terms = append(q.terms, strings.ToLower(strings.Trim(token, `"`)))
+			continue
+		}
+		key = strings.ToLower(key)
+		if !slices.Contains(supported, key) {
+			writeValidationError(w, "Validation Failed", "the fake does not support the "+key+" qualifier")
+			return nil, false
+		}
+		q.qualifiers[key] = append(q.qualifiers[key], strings.ToLower(strings.Trim(value, `"`)))
+	}
+	return q, true
+}
+
+// splitQuery splits a query on spaces outside of double quotes.
+func splitQuery(query string) []string {
+	var tokens []string
+	var b strings.Builder
+	quoted := false
+	for _, c := range query {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			b.WriteRune(c)
+		case c == ' ' && !quoted:
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+	return tokens
+}
+
+// matches reports whether text contains every free text term, ignoring case.
+func (q *searchQuery) matches(text ...string) bool {
+	haystack := strings.ToLower(strings.Join(text, "\n"))
+	for _, term := range q.terms {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// allows reports whether every value of the qualifier key is one of allowed, ignoring case. A
+// qualifier that isn't in the query allows anything.
+func (q *searchQuery) allows(key string, allowed ...string) bool {
+	for _, value := range q.qualifiers[key] {
+		var ok bool
+		for _, a := range allowed {
+			ok = ok || strings.EqualFold(a, value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// searchRepositoriesOf returns the repositories that the repo, user, org and owner qualifiers select, sorted.
+func (s *Server) searchRepositoriesOf(q *searchQuery) []*repository {
+	var repos []*repository
+	for _, repo := range s.repos {
+		if q.allows("repo", repo.fullName()) &&
+			q.allows("user", repo.owner.login) &&
+			q.allows("org", repo.owner.login) &&
+			q.allows("owner", repo.owner.login) {
+			repos = append(repos, repo)
+		}
+	}
+	sort.Slice(repos, func(a, b int) bool {
+		return repos[a].fullName() < repos[b].fullName()
+	})
+	return repos
+}
+
+func (s *Server) searchIssues(w http.ResponseWriter, r *http.Request) {
+	q, ok := parseSearchQuery(w, r, "repo", "user", "org", "owner", "is", "type", "state", "author", "assignee", "label", "in")
+	if !ok {
+		return
+	}
+	issues := []*github.Issue{}
+	for _, repo := range s.searchRepositoriesOf(q) {
+		for _, i := range repo.sortedIssues() {
+			// is: and type: share their values, as neither is checked for consistency
+			is := []string{"issue", i.state, "public"}
+			if i.pull != nil {
+				is[0] = "pr"
+				is = append(is, "pull-request")
+				if i.pull.merged {
+					is = append(is, "merged")
+				}
+			}
+			if repo.private {
+				is[2] = "private"
+			}
+			var assignees []string
+			for _, a := range i.assignees {
+				assignees = append(assignees, a.login)
+			}
+			switch {
+			case !q.allows("is", is...),
+				!q.allows("type", is...),
+				!q.allows("state", i.state),
+				!q.allows("author", i.user.login),
+				!q.allows("assignee", assignees...),
+				!q.allows("label", i.labels...),
+				!q.matches(i.title, i.body):
+				continue
+			}
+			issues = append(issues, s.restIssue(i))
+		}
+	}
+	writeJSON(w, http.StatusOK, &github.IssuesSearchResult{
+		Total:             github.Ptr(len(issues)),
+		IncompleteResults: github.Ptr(false),
+		Issues:            paginate(w, r, s.URL(), issues),
+	})
+}
+
+func (s *Server) searchRepositories(w http.ResponseWriter, r *http.Request) {
+	q, ok := parseSearchQuery(w, r, "repo", "user", "org", "owner", "is", "in")
+	if !ok {
+		return
+	}
+	repos := []*github.Repository{}
+	for _, repo := range s.searchRepositoriesOf(q) {
+		visibility := "public"
+		if repo.private {
+			visibility = "private"
+		}
+		if q.allows("is", visibility) && q.matches(repo.name, repo.description) {
+			repos = append(repos, s.restRepository(repo))
+		}
+	}
+	writeJSON(w, http.StatusOK, &github.RepositoriesSearchResult{
+		Total:             github.Ptr(len(repos)),
+		IncompleteResults: github.Ptr(false),
+		Repositories:      paginate(w, r, s.URL(), repos),
+	})
+}
+
+func (s *Server) searchUsers(w http.ResponseWriter, r *http.Request) {
+	q, ok := parseSearchQuery(w, r, "type", "user", "org", "in")
+	if !ok {
+		return
+	}
+	// A user can be known by several logins, so collect each once
+	seen := map[*user]bool{}
+	var found []*user
+	for _, u := range s.users {
+		typ := "user"
+		if u.typ == "Bot" {
+			typ = "bot"
+		}
+		if seen[u] || !q.allows("type", typ) || !q.allows("user", u.login) || !q.allows("org", u.login) || !q.matches(u.login, u.name) {
+			continue
+		}
+		seen[u] = true
+		found = append(found, u)
+	}
+	sort.Slice(found, func(a, b int) bool {
+		return found[a].login < found[b].login
+	})
+	writeJSON(w, http.StatusOK, &github.UsersSearchResult{
+		Total:             github.Ptr(len(found)),
+		IncompleteResults: github.Ptr(false),
+		Users:             paginate(w, r, s.URL(), s.restUsers(found)),
+	})
+}
+
+// searchCode searches the files on the default branch of each repository.
+func (s *Server) searchCode(w http.ResponseWriter, r *http.Request) {
+	q, ok := parseSearchQuery(w, r, "repo", "user", "org", "owner", "path", "in")
+	if !ok {
+		return
+	}
+	results := []*github.CodeResult{}
+	for _, repo := range s.searchRepositoriesOf(q) {
+		head, ok := repo.headCommit()
+		if !ok {
+			continue
+		}
+		files := repo.trees[head.tree]
+		for _, filePath := range sortedPaths(files) {
+			if !q.allowsPrefix("path", filePath) || !q.matches(filePath, string(repo.blobs[files[filePath]])) {
+				continue
+			}
+			results = append(results, &github.CodeResult{
+				Name:       github.Ptr(filePath[strings.LastIndex(filePath, "/")+1:]),
+				Path:       github.Ptr(filePath),
+				SHA:        github.Ptr(files[filePath]),
+				HTMLURL:    github.Ptr(s.htmlURL(repo.fullName() + "/blob/" + head.sha + "/" + filePath)),
+				Repository: s.restRepository(repo),
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, &github.CodeSearchResult{
+		Total:             github.Ptr(len(results)),
+		IncompleteResults: github.Ptr(false),
+		CodeResults:       paginate(w, r, s.URL(), results),
+	})
+}
+
+// allowsPrefix reports whether value is at or below every value of the qualifier key.
+func (q *searchQuery) allowsPrefix(key, value string) bool {
+	for _, prefix := range q.qualifiers[key] {
+		prefix = strings.Trim(prefix, "/")
+		if !strings.EqualFold(value, prefix) && !strings.HasPrefix(strings.ToLower(value), prefix+"/") {
+			return false
+		}
+	}
+	return true
+}
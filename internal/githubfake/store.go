@@ -0,0 +1,395 @@
+package githubfake
+
+import (
+	"crypto/sha1" //nolint:gosec // git object IDs are SHA-1
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// user is a user or bot account.
+type user struct {
+	id    int64
+	login string
+	name  string
+	// typ is "User" or "Bot"
+	typ string
+	// graphQLLogin is the login reported over GraphQL, when it differs from the REST one
+	graphQLLogin string
+}
+
+func (u *user) nodeID() string {
+	if u.typ == "Bot" {
+		return fmtNodeID("BOT", u.id)
+	}
+	return fmtNodeID("U", u.id)
+}
+
+// repository holds a repository's git objects, refs, issues and pull requests.
+type repository struct {
+	id            int64
+	owner         *user
+	name          string
+	description   string
+	private       bool
+	defaultBranch string
+	createdAt     time.Time
+
+	// refs maps fully qualified ref names (e.g. refs/heads/main) to commit or tag SHAs
+	refs    map[string]string
+	blobs   map[string][]byte
+	trees   map[string]tree
+	commits map[string]*commit
+	tags    map[string]*tag
+
+	// issues holds issues and pull requests, which share numbers
+	issues     map[int]*issue
+	nextNumber int
+}
+
+func (r *repository) fullName() string {
+	return r.owner.login + "/" + r.name
+}
+
+// tree maps the paths of all files in a tree to their blob SHAs. Unlike git, trees are flat.
+type tree map[string]string
+
+type commit struct {
+	sha     string
+	message string
+	tree    string
+	parents []string
+	author  *user
+	date    time.Time
+}
+
+type tag struct {
+	sha        string
+	name       string
+	message    string
+	object     string
+	objectType string
+	tagger     *user
+	date       time.Time
+}
+
+type issue struct {
+	id          int64
+	number      int
+	repo        *repository
+	title       string
+	body        string
+	state       string
+	stateReason string
+	user        *user
+	labels      []string
+	assignees   []*user
+	comments    []*issueComment
+	createdAt   time.Time
+	updatedAt   time.Time
+	closedAt    time.Time
+	// pull is set for pull requests
+	pull *pullRequest
+}
+
+func (i *issue) nodeID() string {
+	if i.pull != nil {
+		return fmtNodeID("PR", i.id)
+	}
+	return fmtNodeID("I", i.id)
+}
+
+type issueComment struct {
+	id        int64
+	body      string
+	user      *user
+	createdAt time.Time
+}
+
+type pullRequest struct {
+	head     string
+	base     string
+	draft    bool
+	merged   bool
+	mergedAt time.Time
+	// mergeCommit is the SHA of the merge commit, once merged
+	mergeCommit        string
+	requestedReviewers []*user
+	reviews            []*review
+	comments           []*reviewComment
+}
+
+type review struct {
+	id          int64
+	pull        *issue
+	user        *user
+	state       string
+	body        string
+	commitID    string
+	submittedAt time.Time
+}
+
+func (r *review) nodeID() string {
+	return fmtNodeID("PRR", r.id)
+}
+
+type reviewComment struct {
+	id          int64
+	review      *review
+	path        string
+	body        string
+	subjectType string
+	side        string
+	line        int
+	startLine   int
+	commitID    string
+	user        *user
+	createdAt   time.Time
+}
+
+func (c *reviewComment) nodeID() string {
+	return fmtNodeID("PRRT", c.id)
+}
+
+// fileChange is a change to a file between two trees.
+type fileChange struct {
+	filename  string
+	status    string
+	additions int
+	deletions int
+	patch     string
+	sha       string
+}
+
+// fmtNodeID formats a GraphQL node ID. Real node IDs are opaque, so the fake's only need to be
+// unique and to identify the type of the node.
+func fmtNodeID(prefix string, id int64) string {
+	return fmt.Sprintf("%s_%d", prefix, id)
+}
+
+func hashObject(kind string, data []byte) string {
+	h := sha1.New() //nolint:gosec // git object IDs are SHA-1
+	fmt.Fprintf(h, "%s %d\x00", kind, len(data))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (r *repository) writeBlob(content []byte) string {
+	sha := hashObject("blob", content)
+	r.blobs[sha] = content
+	return sha
+}
+
+func (r *repository) writeTree(t tree) string {
+	paths := make([]string, 0, len(t))
+	for path := range t {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&b, "100644 %s\x00%s\n", path, t[path])
+	}
+	sha := hashObject("tree", []byte(b.String()))
+	r.trees[sha] = t
+	return sha
+}
+
+func (r *repository) writeCommit(treeSHA string, parents []string, message string, author *user, date time.Time) *commit {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tree %s\n", treeSHA)
+	for _, parent := range parents {
+		fmt.Fprintf(&b, "parent %s\n", parent)
+	}
+	fmt.Fprintf(&b, "author %s %d +0000\n\n%s", author.login, date.Unix(), message)
+	c := &commit{
+		sha:     hashObject("commit", []byte(b.String())),
+		message: message,
+		tree:    treeSHA,
+		parents: parents,
+		author:  author,
+		date:    date,
+	}
+	r.commits[c.sha] = c
+	return c
+}
+
+// commitChanges commits changes on top of parent, which is empty for the first commit. A nil
+// content deletes the file.
+func (r *repository) commitChanges(parent string, changes map[string][]byte, message string, author *user, date time.Time) *commit {
+	var parentTree string
+	var parents []string
+	if parent != "" {
+		parentTree = r.commits[parent].tree
+		parents = []string{parent}
+	}
+	return r.writeCommit(r.applyChanges(parentTree, changes), parents, message, author, date)
+}
+
+// applyChanges writes the tree that results from changing a tree, which may be empty. A nil content
+// deletes the file.
+func (r *repository) applyChanges(treeSHA string, changes map[string][]byte) string {
+	files := tree{}
+	for path, sha := range r.trees[treeSHA] {
+		files[path] = sha
+	}
+	for path, content := range changes {
+		if content == nil {
+			delete(files, path)
+			continue
+		}
+		files[path] = r.writeBlob(content)
+	}
+	return r.writeTree(files)
+}
+
+// resolve returns the SHA of the commit that a SHA, fully or partially qualified ref, branch or tag
+// name points to, peeling annotated tags.
+func (r *repository) resolve(ref string) (string, bool) {
+	if _, ok := r.commits[ref]; ok {
+		return ref, true
+	}
+	candidates := []string{ref, "refs/" + ref, "refs/heads/" + ref, "refs/tags/" + ref}
+	for _, candidate := range candidates {
+		if sha, ok := r.refs[candidate]; ok {
+			return r.peel(sha), true
+		}
+	}
+	return "", false
+}
+
+func (r *repository) peel(sha string) string {
+	for {
+		t, ok := r.tags[sha]
+		if !ok {
+			return sha
+		}
+		sha = t.object
+	}
+}
+
+// resolvePath splits a path that starts with a SHA or ref, such as those of the raw content API,
+// into the commit it points to and the remaining path. Longer refs win, so that branch names
+// containing slashes resolve.
+func (r *repository) resolvePath(path string) (string, string, bool) {
+	parts := strings.Split(path, "/")
+	for i := len(parts) - 1; i >= 1; i-- {
+		if sha, ok := r.resolve(strings.Join(parts[:i], "/")); ok {
+			return sha, strings.Join(parts[i:], "/"), true
+		}
+	}
+	return "", "", false
+}
+
+func (r *repository) headCommit() (*commit, bool) {
+	sha, ok := r.refs["refs/heads/"+r.defaultBranch]
+	if !ok {
+		return nil, false
+	}
+	return r.commits[sha], true
+}
+
+// history returns the commits reachable from sha, newest first.
+func (r *repository) history(sha string) []*commit {
+	var commits []*commit
+	seen := map[string]bool{}
+	queue := []string{sha}
+	for len(queue) > 0 {
+		c, ok := r.commits[queue[0]]
+		queue = queue[1:]
+		if !ok || seen[c.sha] {
+			continue
+		}
+		seen[c.sha] = true
+		commits = append(commits, c)
+		queue = append(queue, c.parents...)
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].date.After(commits[j].date)
+	})
+	return commits
+}
+
+// diffTrees compares two trees, either of which may be empty.
+func (r *repository) diffTrees(from, to string) []fileChange {
+	before, after := r.trees[from], r.trees[to]
+	paths := map[string]bool{}
+	for path := range before {
+		paths[path] = true
+	}
+	for path := range after {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var changes []fileChange
+	for _, path := range sorted {
+		oldSHA, newSHA := before[path], after[path]
+		if oldSHA == newSHA {
+			continue
+		}
+		change := fileChange{filename: path, sha: newSHA}
+		switch {
+		case oldSHA == "":
+			change.status = "added"
+		case newSHA == "":
+			change.status = "removed"
+		default:
+			change.status = "modified"
+		}
+		change.patch, change.additions, change.deletions = diffLines(lines(r.blobs[oldSHA]), lines(r.blobs[newSHA]))
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// commitChangesOf returns the changes a commit made to its first parent.
+func (r *repository) commitChangesOf(c *commit) []fileChange {
+	var parentTree string
+	if len(c.parents) > 0 {
+		parentTree = r.commits[c.parents[0]].tree
+	}
+	return r.diffTrees(parentTree, c.tree)
+}
+
+func lines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// diffLines returns a single hunk patch replacing the lines that differ between the common prefix
+// and suffix of before and after. It is not minimal, but is enough for line counts and comments.
+func diffLines(before, after []string) (string, int, int) {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	removed := before[prefix : len(before)-suffix]
+	added := after[prefix : len(after)-suffix]
+	if len(removed) == 0 && len(added) == 0 {
+		return "", 0, 0
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", prefix+1, len(removed), prefix+1, len(added))
+	for _, line := range removed {
+		b.WriteString("-" + line + "\n")
+	}
+	for _, line := range added {
+		b.WriteString("+" + line + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), len(added), len(removed)
+}
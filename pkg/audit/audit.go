@@ -0,0 +1,176 @@
+// Package audit records every call to a tool that can change data on GitHub, so that there is a
+// trail of the mutations an agent made.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/dryrun"
+	mcplog "github.com/github/github-mcp-server/pkg/log"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxResourceURLs bounds the resource URLs recorded for a call.
+const maxResourceURLs = 20
+
+// Entry is one line of the audit log.
+type Entry struct {
+	// Time is when the call completed
+	Time time.Time `json:"timestamp"`
+	// Tool is the name of the tool called
+	Tool string `json:"tool"`
+	// Arguments are the arguments of the call, with secrets redacted
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	// Owner and Repo are the repository the call targeted, if any
+	Owner string `json:"owner,omitempty"`
+	Repo  string `json:"repo,omitempty"`
+	// Success is whether the call completed without an error
+	Success bool `json:"success"`
+	// Error is the error the call failed with
+	Error string `json:"error,omitempty"`
+	// ResourceURLs are the URLs of the resources the call created or changed, as far as its result tells
+	ResourceURLs []string `json:"resource_urls,omitempty"`
+	// ClientName and ClientVersion identify the MCP client, as given in its initialize request
+	ClientName    string `json:"client_name,omitempty"`
+	ClientVersion string `json:"client_version,omitempty"`
+	// SessionID is the ID of the MCP session the call was made in, if the transport has sessions
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// IsWriteToolFunc reports whether a tool can change data, and thus whether its calls are audited.
+type IsWriteToolFunc func(tool string) bool
+
+// Middleware returns receiving middleware that writes an entry for every call to a write tool once
+// it completes, except for dry runs. Failing to write an entry is logged and doesn't fail the call.
+func (l *Log) Middleware(isWriteTool IsWriteToolFunc) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			// Dry runs don't change anything
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil || !isWriteTool(call.Params.Name) || dryrun.IsDryRun(ctx) {
+				return next(ctx, method, req)
+			}
+
+			result, err := next(ctx, method, req)
+
+			toolResult, _ := result.(*mcp.CallToolResult)
+			entry := newEntry(call, toolResult, err)
+			if writeErr := l.Write(entry); writeErr != nil {
+				l.logger.Error("failed to write audit log", "tool", entry.Tool, "error", writeErr)
+			}
+			return result, err
+		}
+	}
+}
+
+func newEntry(call *mcp.CallToolRequest, result *mcp.CallToolResult, err error) Entry {
+	entry := Entry{
+		Time:      time.Now().UTC(),
+		Tool:      call.Params.Name,
+		Arguments: redactArguments(call.Params.Arguments),
+		Success:   err == nil && (result == nil || !result.IsError),
+	}
+
+	var args struct {
+		Owner string `json:"owner"`
+		Repo  string `json:"repo"`
+	}
+	if json.Unmarshal(call.Params.Arguments, &args) == nil {
+		entry.Owner, entry.Repo = args.Owner, args.Repo
+	}
+
+	switch {
+	case err != nil:
+		entry.Error = mcplog.Redact(err.Error())
+	case result != nil && result.IsError:
+		entry.Error = mcplog.Redact(resultText(result))
+	case result != nil:
+		entry.ResourceURLs = resourceURLs(result)
+	}
+
+	if session, ok := call.GetSession().(*mcp.ServerSession); ok && session != nil {
+		entry.SessionID = session.ID()
+		if params := session.InitializeParams(); params != nil && params.ClientInfo != nil {
+			entry.ClientName = params.ClientInfo.Name
+			entry.ClientVersion = params.ClientInfo.Version
+		}
+	}
+	return entry
+}
+
+// redactArguments masks secrets in the arguments. The redaction keeps JSON valid in all but
+// contrived cases, which are recorded as a JSON string instead.
+func redactArguments(arguments json.RawMessage) json.RawMessage {
+	if len(arguments) == 0 {
+		return nil
+	}
+	redacted := json.RawMessage(mcplog.Redact(string(arguments)))
+	if json.Valid(redacted) {
+		return redacted
+	}
+	quoted, _ := json.Marshal(string(redacted))
+	return quoted
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// resourceURLs collects the URLs in the JSON results of a call. Write tools return the object they
+// created or changed, or a minimal response with its URL, so the URL is taken from the top-level
+// "html_url" field, or the "url" field if there is none. Results without these, such as a file update returning the new
+// content and commit, contribute the "html_url" of their top-level objects instead.
+func resourceURLs(result *mcp.CallToolResult) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	add := func(value any) {
+		url, ok := value.(string)
+		if !ok || seen[url] || len(urls) >= maxResourceURLs ||
+			!(strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")) {
+			return
+		}
+		seen[url] = true
+		urls = append(urls, url)
+	}
+
+	for _, content := range result.Content {
+		text, ok := content.(*mcp.TextContent)
+		if !ok {
+			continue
+		}
+		var object map[string]any
+		if json.Unmarshal([]byte(text.Text), &object) != nil {
+			continue
+		}
+
+		if _, ok := object["html_url"]; ok {
+			add(object["html_url"])
+			continue
+		}
+		if _, ok := object["url"]; ok {
+			add(object["url"])
+			continue
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if nested, ok := object[key].(map[string]any); ok {
+				add(nested["html_url"])
+			}
+		}
+	}
+	return urls
+}
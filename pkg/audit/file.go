@@ -0,0 +1,157 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+const (
+	// DefaultMaxSize is the size at which the audit log is rotated by default.
+	DefaultMaxSize = 100 << 20
+	// DefaultMaxBackups is the number of rotated audit logs kept by default.
+	DefaultMaxBackups = 5
+)
+
+// Log appends entries as JSON lines to a file. When the file would grow beyond its maximum size it
+// is rotated: path is renamed to path.1, path.1 to path.2 and so on, and the oldest backup beyond the
+// maximum number of backups is removed. It is safe for concurrent use.
+type Log struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	logger     *slog.Logger
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Option configures a Log at construction time.
+type Option func(*Log)
+
+// WithMaxSize sets the size in bytes at which the file is rotated (0 never rotates it).
+func WithMaxSize(maxSize int64) Option {
+	return func(l *Log) {
+		l.maxSize = maxSize
+	}
+}
+
+// WithMaxBackups sets the number of rotated files to keep.
+func WithMaxBackups(maxBackups int) Option {
+	return func(l *Log) {
+		l.maxBackups = maxBackups
+	}
+}
+
+// WithLogger sets the logger that failures to write the audit log are reported to.
+func WithLogger(logger *slog.Logger) Option {
+	return func(l *Log) {
+		l.logger = logger
+	}
+}
+
+// Open opens the audit log at path for appending, creating it if needed. The file is only
+// accessible to the current user.
+func Open(path string, opts ...Option) (*Log, error) {
+	l := &Log{
+		path:       path,
+		maxSize:    DefaultMaxSize,
+		maxBackups: DefaultMaxBackups,
+		logger:     slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write appends the entry to the log.
+func (l *Log) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fs.ErrClosed
+	}
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file. Entries written afterwards are rejected.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *Log) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	l.file = nil
+
+	if l.maxBackups > 0 {
+		// Shift the backups up by one, which overwrites the oldest
+		for i := l.maxBackups - 1; i > 0; i-- {
+			err := os.Rename(l.backupPath(i), l.backupPath(i+1))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("failed to rotate audit log: %w", err)
+			}
+		}
+		if err := os.Rename(l.path, l.backupPath(1)); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	} else if err := os.Remove(l.path); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	return l.open()
+}
+
+func (l *Log) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", l.path, i)
+}
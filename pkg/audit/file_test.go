@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
+
+func Test_LogAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	log, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, log.Write(Entry{Tool: "create_issue", Success: true}))
+	require.NoError(t, log.Close())
+
+	// Reopening appends to the existing file
+	log, err = Open(path)
+	require.NoError(t, err)
+	require.NoError(t, log.Write(Entry{Tool: "merge_pull_request"}))
+	require.NoError(t, log.Close())
+	require.Error(t, log.Write(Entry{Tool: "create_branch"}))
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "create_issue", entries[0].Tool)
+	assert.True(t, entries[0].Success)
+	assert.Equal(t, "merge_pull_request", entries[1].Tool)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func Test_LogRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	line, err := json.Marshal(Entry{Tool: "create_issue"})
+	require.NoError(t, err)
+
+	// Room for two entries per file
+	log, err := Open(path, WithMaxSize(int64(2*(len(line)+1))), WithMaxBackups(2))
+	require.NoError(t, err)
+	defer log.Close()
+
+	for range 7 {
+		require.NoError(t, log.Write(Entry{Tool: "create_issue"}))
+	}
+
+	assert.Len(t, readEntries(t, path), 1)
+	assert.Len(t, readEntries(t, path+".1"), 2)
+	assert.Len(t, readEntries(t, path+".2"), 2)
+	assert.NoFileExists(t, path+".3")
+}
+
+func Test_LogRotatesWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	log, err := Open(path, WithMaxSize(1), WithMaxBackups(0))
+	require.NoError(t, err)
+	defer log.Close()
+
+	require.NoError(t, log.Write(Entry{Tool: "create_issue"}))
+	require.NoError(t, log.Write(Entry{Tool: "update_issue"}))
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "update_issue", entries[0].Tool)
+	assert.NoFileExists(t, path+".1")
+}
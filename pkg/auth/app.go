@@ -0,0 +1,231 @@
+// Package auth provides ways for the server to authenticate with GitHub other than a static token.
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v79/github"
+)
+
+const (
+	// jwtClockSkew backdates the JWT issue time to allow for clock drift between us and GitHub.
+	jwtClockSkew = 60 * time.Second
+	// jwtLifetime is how long a JWT is valid for. GitHub rejects JWTs valid for more than 10 minutes.
+	jwtLifetime = 9 * time.Minute
+	// installationTokenRefreshMargin is how long before expiry an installation token is replaced.
+	installationTokenRefreshMargin = 5 * time.Minute
+)
+
+// AppConfig identifies a GitHub App installation to authenticate as.
+type AppConfig struct {
+	// AppID is the ID (or client ID) of the GitHub App
+	AppID string
+	// PrivateKey is the private key of the GitHub App, used to sign JWTs
+	PrivateKey *rsa.PrivateKey
+	// InstallationID is the installation to authenticate as. If zero, it is looked up from InstallationOwner.
+	InstallationID int64
+	// InstallationOwner is the user or organization account the App is installed on
+	InstallationOwner string
+}
+
+// LoadPrivateKeyFile reads a PEM encoded RSA private key, as downloaded from the GitHub App settings page.
+func LoadPrivateKeyFile(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is provided by the operator running the server
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file: %w", err)
+	}
+	return ParsePrivateKey(data)
+}
+
+// ParsePrivateKey parses a PEM encoded RSA private key in either PKCS#1 or PKCS#8 form.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is of type %T, expected RSA", parsed)
+	}
+	return key, nil
+}
+
+// AppJWTSigner creates the short-lived JWTs that authenticate requests as a GitHub App.
+type AppJWTSigner struct {
+	appID string
+	key   *rsa.PrivateKey
+	now   func() time.Time
+}
+
+// NewAppJWTSigner creates a signer for the given App ID and private key.
+func NewAppJWTSigner(appID string, key *rsa.PrivateKey) *AppJWTSigner {
+	return &AppJWTSigner{appID: appID, key: key, now: time.Now}
+}
+
+// Sign returns a new RS256 signed JWT for the App.
+func (s *AppJWTSigner) Sign() (string, error) {
+	now := s.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-jwtClockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": s.appID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT claims: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// AppTransport authenticates requests as the GitHub App itself, which is needed for the /app endpoints.
+type AppTransport struct {
+	Transport http.RoundTripper
+	Signer    *AppJWTSigner
+}
+
+func (t *AppTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := t.Signer.Sign()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return t.Transport.RoundTrip(req)
+}
+
+// newAppClient creates a REST client authenticated as the App against the given API base URL.
+func newAppClient(transport http.RoundTripper, baseURL *url.URL, signer *AppJWTSigner) *github.Client {
+	client := github.NewClient(&http.Client{Transport: &AppTransport{Transport: transport, Signer: signer}})
+	client.BaseURL = baseURL
+	return client
+}
+
+// FindInstallationID looks up the installation of the App on the given user or organization account.
+func FindInstallationID(ctx context.Context, transport http.RoundTripper, baseURL *url.URL, signer *AppJWTSigner, owner string) (int64, error) {
+	client := newAppClient(transport, baseURL, signer)
+
+	installation, resp, err := client.Apps.FindOrganizationInstallation(ctx, owner)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		installation, resp, err = client.Apps.FindUserInstallation(ctx, owner)
+	}
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return 0, fmt.Errorf("GitHub App %s is not installed on %s", signer.appID, owner)
+		}
+		return 0, fmt.Errorf("failed to find installation for %s: %w", owner, err)
+	}
+
+	return installation.GetID(), nil
+}
+
+// InstallationTransport authenticates requests as a GitHub App installation. It mints an installation
+// token on first use and replaces it shortly before it expires, so it can be shared by long-lived clients.
+type InstallationTransport struct {
+	transport      http.RoundTripper
+	appClient      *github.Client
+	installationID int64
+	now            func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	expiresAt   time.Time
+	permissions *github.InstallationPermissions
+}
+
+// NewInstallationTransport creates a transport authenticating as the given installation. Tokens are
+// minted against the REST API at baseURL, and requests are sent through transport.
+func NewInstallationTransport(transport http.RoundTripper, baseURL *url.URL, signer *AppJWTSigner, installationID int64) *InstallationTransport {
+	return &InstallationTransport{
+		transport:      transport,
+		appClient:      newAppClient(transport, baseURL, signer),
+		installationID: installationID,
+		now:            time.Now,
+	}
+}
+
+// Token returns a valid installation token, minting a new one if the current one is missing or about to expire.
+func (t *InstallationTransport) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && t.now().Add(installationTokenRefreshMargin).Before(t.expiresAt) {
+		return t.token, nil
+	}
+
+	token, _, err := t.appClient.Apps.CreateInstallationToken(ctx, t.installationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token for installation %d: %w", t.installationID, err)
+	}
+
+	t.token = token.GetToken()
+	t.expiresAt = token.GetExpiresAt().Time
+	t.permissions = token.GetPermissions()
+	return t.token, nil
+}
+
+// Permissions returns the permissions granted to the installation, mapping each permission name
+// (e.g. "contents") to its access level (e.g. "read").
+func (t *InstallationTransport) Permissions(ctx context.Context) (map[string]string, error) {
+	if _, err := t.Token(ctx); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// The JSON form of InstallationPermissions is exactly the permission name to level mapping
+	data, err := json.Marshal(t.permissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal installation permissions: %w", err)
+	}
+	permissions := make(map[string]string)
+	if err := json.Unmarshal(data, &permissions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal installation permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+func (t *InstallationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.transport.RoundTrip(req)
+}
@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+// verifyJWT checks the signature of an RS256 JWT and returns its claims.
+func verifyJWT(t *testing.T, key *rsa.PublicKey, jwt string) map[string]any {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	return claims
+}
+
+// fakeGitHub is a local stand-in for the GitHub App endpoints.
+type fakeGitHub struct {
+	t              *testing.T
+	key            *rsa.PublicKey
+	tokenLifetime  time.Duration
+	tokensMinted   atomic.Int32
+	installedOwner string
+}
+
+func (f *fakeGitHub) handler() http.Handler {
+	mux := http.NewServeMux()
+	requireAppJWT := func(r *http.Request) {
+		jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		claims := verifyJWT(f.t, f.key, jwt)
+		assert.Equal(f.t, "12345", claims["iss"])
+	}
+	mux.HandleFunc("POST /app/installations/{id}/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		requireAppJWT(r)
+		n := f.tokensMinted.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":       fmt.Sprintf("ghs_installation_%s_%d", r.PathValue("id"), n),
+			"expires_at":  time.Now().Add(f.tokenLifetime).UTC().Format(time.RFC3339),
+			"permissions": map[string]string{"contents": "read", "issues": "write"},
+		})
+	})
+	mux.HandleFunc("GET /orgs/{org}/installation", func(w http.ResponseWriter, r *http.Request) {
+		requireAppJWT(r)
+		if r.PathValue("org") != f.installedOwner {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 42})
+	})
+	mux.HandleFunc("GET /users/{user}/installation", func(w http.ResponseWriter, r *http.Request) {
+		requireAppJWT(r)
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	})
+	return mux
+}
+
+func newFakeGitHub(t *testing.T, key *rsa.PrivateKey, tokenLifetime time.Duration) (*fakeGitHub, *url.URL) {
+	t.Helper()
+	fake := &fakeGitHub{t: t, key: &key.PublicKey, tokenLifetime: tokenLifetime, installedOwner: "octo-org"}
+	ts := httptest.NewServer(fake.handler())
+	t.Cleanup(ts.Close)
+	baseURL, err := url.Parse(ts.URL + "/")
+	require.NoError(t, err)
+	return fake, baseURL
+}
+
+func Test_ParsePrivateKey(t *testing.T) {
+	key := generateKey(t)
+
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	parsed, err := ParsePrivateKey(pkcs1)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes})
+	parsed, err = ParsePrivateKey(pkcs8)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	_, err = ParsePrivateKey([]byte("not a key"))
+	require.Error(t, err)
+}
+
+func Test_AppJWTSigner(t *testing.T) {
+	key := generateKey(t)
+	now := time.Unix(1700000000, 0)
+	signer := NewAppJWTSigner("12345", key)
+	signer.now = func() time.Time { return now }
+
+	jwt, err := signer.Sign()
+	require.NoError(t, err)
+
+	claims := verifyJWT(t, &key.PublicKey, jwt)
+	assert.Equal(t, "12345", claims["iss"])
+	assert.Equal(t, float64(now.Add(-jwtClockSkew).Unix()), claims["iat"])
+	assert.Equal(t, float64(now.Add(jwtLifetime).Unix()), claims["exp"])
+}
+
+func Test_FindInstallationID(t *testing.T) {
+	key := generateKey(t)
+	_, baseURL := newFakeGitHub(t, key, time.Hour)
+	signer := NewAppJWTSigner("12345", key)
+
+	id, err := FindInstallationID(context.Background(), http.DefaultTransport, baseURL, signer, "octo-org")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+
+	_, err = FindInstallationID(context.Background(), http.DefaultTransport, baseURL, signer, "someone-else")
+	require.ErrorContains(t, err, "not installed on someone-else")
+}
+
+func Test_InstallationTransport(t *testing.T) {
+	key := generateKey(t)
+
+	t.Run("reuses a token until it is about to expire", func(t *testing.T) {
+		fake, baseURL := newFakeGitHub(t, key, time.Hour)
+		transport := NewInstallationTransport(http.DefaultTransport, baseURL, NewAppJWTSigner("12345", key), 42)
+		client := &http.Client{Transport: transport}
+
+		get := func() string {
+			resp, err := client.Get(baseURL.String() + "user")
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			return string(body)
+		}
+
+		assert.Equal(t, "Bearer ghs_installation_42_1", get())
+		assert.Equal(t, "Bearer ghs_installation_42_1", get())
+		assert.Equal(t, int32(1), fake.tokensMinted.Load())
+
+		// Move the clock to within the refresh margin of the expiry
+		transport.now = func() time.Time { return time.Now().Add(time.Hour - installationTokenRefreshMargin + time.Second) }
+		assert.Equal(t, "Bearer ghs_installation_42_2", get())
+		assert.Equal(t, int32(2), fake.tokensMinted.Load())
+	})
+
+	t.Run("mints a single token for concurrent requests", func(t *testing.T) {
+		fake, baseURL := newFakeGitHub(t, key, time.Hour)
+		transport := NewInstallationTransport(http.DefaultTransport, baseURL, NewAppJWTSigner("12345", key), 42)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := transport.Token(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, "ghs_installation_42_1", token)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), fake.tokensMinted.Load())
+	})
+
+	t.Run("reports the installation permissions", func(t *testing.T) {
+		_, baseURL := newFakeGitHub(t, key, time.Hour)
+		transport := NewInstallationTransport(http.DefaultTransport, baseURL, NewAppJWTSigner("12345", key), 42)
+
+		permissions, err := transport.Permissions(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"contents": "read", "issues": "write"}, permissions)
+	})
+
+	t.Run("surfaces token endpoint failures", func(t *testing.T) {
+		_, baseURL := newFakeGitHub(t, key, time.Hour)
+		// Tokens are minted against a server that rejects the JWT
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, `{"message":"A JSON web token could not be decoded"}`, http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+		badURL, err := url.Parse(ts.URL + "/")
+		require.NoError(t, err)
+
+		transport := NewInstallationTransport(http.DefaultTransport, badURL, NewAppJWTSigner("12345", key), 42)
+		_, err = (&http.Client{Transport: transport}).Get(baseURL.String() + "user")
+		require.ErrorContains(t, err, "failed to create token for installation 42")
+	})
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// deviceGrantType is the OAuth grant type used to exchange a device code for a token.
+	deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// slowDownIncrement is added to the polling interval each time GitHub asks us to slow down.
+	slowDownIncrement = 5 * time.Second
+)
+
+var (
+	// ErrDeviceCodeExpired is returned when the user did not authorize the device before the code expired.
+	ErrDeviceCodeExpired = errors.New("the device code has expired")
+	// ErrAccessDenied is returned when the user cancelled the authorization.
+	ErrAccessDenied = errors.New("the authorization request was denied")
+)
+
+// DeviceCode is the code the user enters at VerificationURI to authorize the device.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// AccessToken is the result of a completed device flow.
+type AccessToken struct {
+	Token  string
+	Scopes []string
+}
+
+// DeviceFlow runs the OAuth device authorization flow of an OAuth App against a GitHub host.
+// See https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/authorizing-oauth-apps#device-flow
+type DeviceFlow struct {
+	// BaseURL is the web URL of the GitHub host (e.g. https://github.com/), not its API URL
+	BaseURL *url.URL
+	// ClientID is the client ID of the OAuth App, which must have device flow enabled
+	ClientID string
+	// Scopes are the OAuth scopes to request
+	Scopes []string
+	// HTTPClient is used for the requests, defaulting to http.DefaultClient
+	HTTPClient *http.Client
+
+	// sleep waits between polls, replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// RequestCode starts the flow, returning the code the user must enter to authorize the device.
+func (f *DeviceFlow) RequestCode(ctx context.Context) (*DeviceCode, error) {
+	form := url.Values{
+		"client_id": {f.ClientID},
+		"scope":     {strings.Join(f.Scopes, " ")},
+	}
+
+	var code struct {
+		DeviceCode
+		deviceFlowError
+	}
+	if err := f.post(ctx, "login/device/code", form, &code); err != nil {
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+	if code.Code != "" {
+		return nil, fmt.Errorf("failed to request device code: %w", code.deviceFlowError)
+	}
+
+	return &code.DeviceCode, nil
+}
+
+// PollToken waits for the user to authorize the device, returning the issued token.
+func (f *DeviceFlow) PollToken(ctx context.Context, code *DeviceCode) (*AccessToken, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+	defer cancel()
+
+	form := url.Values{
+		"client_id":   {f.ClientID},
+		"device_code": {code.DeviceCode},
+		"grant_type":  {deviceGrantType},
+	}
+
+	for {
+		if err := f.wait(ctx, interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, ErrDeviceCodeExpired
+			}
+			return nil, err
+		}
+
+		var token struct {
+			AccessToken string `json:"access_token"`
+			Scope       string `json:"scope"`
+			Interval    int    `json:"interval"`
+			deviceFlowError
+		}
+		if err := f.post(ctx, "login/oauth/access_token", form, &token); err != nil {
+			return nil, fmt.Errorf("failed to poll for access token: %w", err)
+		}
+
+		switch token.Code {
+		case "":
+			return &AccessToken{Token: token.AccessToken, Scopes: splitScopes(token.Scope)}, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += slowDownIncrement
+			if token.Interval > 0 {
+				interval = time.Duration(token.Interval) * time.Second
+			}
+		case "expired_token":
+			return nil, ErrDeviceCodeExpired
+		case "access_denied":
+			return nil, ErrAccessDenied
+		default:
+			return nil, token.deviceFlowError
+		}
+	}
+}
+
+func (f *DeviceFlow) wait(ctx context.Context, d time.Duration) error {
+	if f.sleep != nil {
+		return f.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (f *DeviceFlow) post(ctx context.Context, path string, form url.Values, v any) error {
+	endpoint := f.BaseURL.ResolveReference(&url.URL{Path: path})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := f.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, endpoint)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// deviceFlowError is the error body GitHub returns, with a 200 status, from the device flow endpoints.
+type deviceFlowError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e deviceFlowError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+func splitScopes(scope string) []string {
+	var scopes []string
+	for _, s := range strings.Split(scope, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDeviceFlowServer serves the device flow endpoints, answering polls with the given responses in order.
+func newDeviceFlowServer(t *testing.T, pollResponses ...map[string]any) *url.URL {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+		assert.Equal(t, "repo read:org", r.PostForm.Get("scope"))
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "device-123",
+			"user_code":        "ABCD-1234",
+			"verification_uri": "https://github.com/login/device",
+			"expires_in":       900,
+			"interval":         5,
+		})
+	})
+	polls := 0
+	mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "device-123", r.PostForm.Get("device_code"))
+		assert.Equal(t, deviceGrantType, r.PostForm.Get("grant_type"))
+		require.Less(t, polls, len(pollResponses), "unexpected poll")
+		_ = json.NewEncoder(w).Encode(pollResponses[polls])
+		polls++
+	})
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	baseURL, err := url.Parse(ts.URL + "/")
+	require.NoError(t, err)
+	return baseURL
+}
+
+func Test_DeviceFlow(t *testing.T) {
+	tests := []struct {
+		name              string
+		pollResponses     []map[string]any
+		expectedToken     *AccessToken
+		expectedErr       error
+		expectedErrString string
+		expectedWaits     []time.Duration
+	}{
+		{
+			name: "waits for authorization",
+			pollResponses: []map[string]any{
+				{"error": "authorization_pending"},
+				{"access_token": "gho_abc", "token_type": "bearer", "scope": "repo,read:org"},
+			},
+			expectedToken: &AccessToken{Token: "gho_abc", Scopes: []string{"repo", "read:org"}},
+			expectedWaits: []time.Duration{5 * time.Second, 5 * time.Second},
+		},
+		{
+			name: "slows down when asked",
+			pollResponses: []map[string]any{
+				{"error": "slow_down"},
+				{"error": "slow_down", "interval": 20},
+				{"access_token": "gho_abc", "scope": "repo"},
+			},
+			expectedToken: &AccessToken{Token: "gho_abc", Scopes: []string{"repo"}},
+			expectedWaits: []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second},
+		},
+		{
+			name:          "expired code",
+			pollResponses: []map[string]any{{"error": "expired_token"}},
+			expectedErr:   ErrDeviceCodeExpired,
+			expectedWaits: []time.Duration{5 * time.Second},
+		},
+		{
+			name:          "denied",
+			pollResponses: []map[string]any{{"error": "access_denied"}},
+			expectedErr:   ErrAccessDenied,
+			expectedWaits: []time.Duration{5 * time.Second},
+		},
+		{
+			name:              "other errors",
+			pollResponses:     []map[string]any{{"error": "device_flow_disabled", "error_description": "Device Flow must be explicitly enabled for this App"}},
+			expectedErrString: "device_flow_disabled: Device Flow must be explicitly enabled for this App",
+			expectedWaits:     []time.Duration{5 * time.Second},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var waits []time.Duration
+			flow := &DeviceFlow{
+				BaseURL:  newDeviceFlowServer(t, tc.pollResponses...),
+				ClientID: "client-id",
+				Scopes:   []string{"repo", "read:org"},
+				sleep: func(_ context.Context, d time.Duration) error {
+					waits = append(waits, d)
+					return nil
+				},
+			}
+
+			code, err := flow.RequestCode(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "ABCD-1234", code.UserCode)
+			assert.Equal(t, "https://github.com/login/device", code.VerificationURI)
+
+			token, err := flow.PollToken(context.Background(), code)
+			assert.Equal(t, tc.expectedWaits, waits)
+			switch {
+			case tc.expectedErr != nil:
+				require.ErrorIs(t, err, tc.expectedErr)
+			case tc.expectedErrString != "":
+				require.EqualError(t, err, tc.expectedErrString)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedToken, token)
+			}
+		})
+	}
+}
+
+func Test_DeviceFlow_RequestCodeError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": "unauthorized_client", "error_description": "unknown client"})
+	}))
+	defer ts.Close()
+	baseURL, err := url.Parse(ts.URL + "/")
+	require.NoError(t, err)
+
+	_, err = (&DeviceFlow{BaseURL: baseURL, ClientID: "nope"}).RequestCode(context.Background())
+	require.EqualError(t, err, "failed to request device code: unauthorized_client: unknown client")
+}
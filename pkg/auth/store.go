@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// Credential is a token stored for a GitHub host.
+type Credential struct {
+	// Token is the OAuth token
+	Token string `json:"token"`
+	// User is the login of the user the token belongs to
+	User string `json:"user,omitempty"`
+	// Scopes are the OAuth scopes granted to the token
+	Scopes []string `json:"scopes,omitempty"`
+	// CreatedAt is when the token was stored
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// credentialFile is the on-disk format of the credential store.
+type credentialFile struct {
+	Hosts map[string]Credential `json:"hosts"`
+}
+
+// CredentialStore keeps tokens per GitHub host in a JSON file that only the current user can read.
+type CredentialStore struct {
+	path string
+}
+
+// DefaultCredentialStorePath returns the path of the credential file in the user's configuration directory.
+func DefaultCredentialStorePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return filepath.Join(dir, "github-mcp-server", "credentials.json"), nil
+}
+
+// NewCredentialStore creates a store backed by the file at path. The file is created on the first Set.
+func NewCredentialStore(path string) *CredentialStore {
+	return &CredentialStore{path: path}
+}
+
+// Path returns the path of the credential file.
+func (s *CredentialStore) Path() string {
+	return s.path
+}
+
+// Get returns the credential stored for host, or nil if there is none.
+func (s *CredentialStore) Get(host string) (*Credential, error) {
+	file, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	cred, ok := file.Hosts[host]
+	if !ok {
+		return nil, nil
+	}
+	return &cred, nil
+}
+
+// Set stores the credential for host, replacing any existing one.
+func (s *CredentialStore) Set(host string, cred Credential) error {
+	file, err := s.load()
+	if err != nil {
+		return err
+	}
+	file.Hosts[host] = cred
+	return s.save(file)
+}
+
+// Delete removes the credential for host, reporting whether there was one.
+func (s *CredentialStore) Delete(host string) (bool, error) {
+	file, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := file.Hosts[host]; !ok {
+		return false, nil
+	}
+	delete(file.Hosts, host)
+	return true, s.save(file)
+}
+
+func (s *CredentialStore) load() (credentialFile, error) {
+	file := credentialFile{Hosts: map[string]Credential{}}
+
+	info, err := os.Stat(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return file, fmt.Errorf("failed to read credential file: %w", err)
+	}
+	// Refuse to use tokens that other users could have read, like ssh does for private keys
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return file, fmt.Errorf("credential file %s is accessible by other users, restrict it with: chmod 600 %s", s.path, s.path)
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return file, fmt.Errorf("failed to read credential file: %w", err)
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("failed to parse credential file %s: %w", s.path, err)
+	}
+	if file.Hosts == nil {
+		file.Hosts = map[string]Credential{}
+	}
+	return file, nil
+}
+
+// save writes the file atomically, so a crash can't leave a truncated credential file behind.
+func (s *CredentialStore) save(file credentialFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create credential directory: %w", err)
+	}
+
+	// CreateTemp creates the file with 0600 permissions
+	tmp, err := os.CreateTemp(dir, ".credentials-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	return nil
+}
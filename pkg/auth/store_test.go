@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CredentialStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "credentials.json")
+	store := NewCredentialStore(path)
+
+	// A missing file is an empty store
+	cred, err := store.Get("github.com")
+	require.NoError(t, err)
+	assert.Nil(t, cred)
+
+	stored := Credential{Token: "gho_abc", User: "octocat", Scopes: []string{"repo"}, CreatedAt: time.Unix(1700000000, 0).UTC()}
+	require.NoError(t, store.Set("github.com", stored))
+	require.NoError(t, store.Set("ghe.example.com", Credential{Token: "gho_def"}))
+
+	cred, err = store.Get("github.com")
+	require.NoError(t, err)
+	assert.Equal(t, &stored, cred)
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	deleted, err := store.Delete("github.com")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = store.Delete("github.com")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	// Other hosts are untouched
+	cred, err = store.Get("ghe.example.com")
+	require.NoError(t, err)
+	require.NotNil(t, cred)
+	assert.Equal(t, "gho_def", cred.Token)
+}
+
+func Test_CredentialStore_RejectsReadableFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not enforced on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"hosts":{"github.com":{"token":"gho_abc"}}}`), 0o600))
+	require.NoError(t, os.Chmod(path, 0o644))
+
+	_, err := NewCredentialStore(path).Get("github.com")
+	require.ErrorContains(t, err, "accessible by other users")
+}
@@ -0,0 +1,280 @@
+// Package cassette records GitHub API traffic to files and replays it, so that sessions can be
+// reproduced without access to GitHub.
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	mcplog "github.com/github/github-mcp-server/pkg/log"
+)
+
+// Interaction is a request and its response, as stored in a cassette file.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body"`
+}
+
+// Body is stored as text if it is valid UTF-8, so that cassettes can be read and edited, and as
+// base64 otherwise.
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(map[string]string{"text": string(b)})
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var body struct {
+		Text   string `json:"text"`
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	if body.Base64 == "" {
+		*b = Body(body.Text)
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(body.Base64)
+	if err != nil {
+		return fmt.Errorf("failed to decode body: %w", err)
+	}
+	*b = decoded
+	return nil
+}
+
+// scrubbedHeaders are dropped from cassettes, as they hold credentials.
+var scrubbedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// unsafePathChars are replaced in the part of file names taken from the request path.
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Recorder is a transport that writes every request sent through it, together with its response,
+// to a cassette file in a directory. Files are numbered in the order responses arrive. Credentials
+// are scrubbed: Authorization and cookie headers are dropped, and tokens and other secrets in URLs,
+// headers and bodies are redacted with the patterns of log.Redact.
+type Recorder struct {
+	transport http.RoundTripper
+	dir       string
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewRecorder creates a recorder sending requests through transport and writing cassettes to dir,
+// after any cassettes already there.
+func NewRecorder(transport http.RoundTripper, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{transport: transport, dir: dir, next: len(files) + 1}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    mcplog.Redact(req.URL.String()),
+			Header: scrubHeader(req.Header),
+			Body:   scrubBody(reqBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+			Body:       scrubBody(respBody),
+		},
+	}
+	if err := r.write(req, interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) write(req *http.Request, interaction Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := strings.Trim(unsafePathChars.ReplaceAllString(req.URL.Path, "_"), "_")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	path := filepath.Join(r.dir, fmt.Sprintf("%06d-%s-%s.json", r.next, req.Method, name))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	r.next++
+	return nil
+}
+
+func scrubHeader(header http.Header) http.Header {
+	scrubbed := make(http.Header, len(header))
+	for key, values := range header {
+		for _, value := range values {
+			scrubbed.Add(key, mcplog.Redact(value))
+		}
+	}
+	for _, key := range scrubbedHeaders {
+		scrubbed.Del(key)
+	}
+	return scrubbed
+}
+
+func scrubBody(body []byte) Body {
+	if !utf8.Valid(body) {
+		return body
+	}
+	return Body(mcplog.Redact(string(body)))
+}
+
+// Player is a transport that answers requests with the responses recorded in a directory, without
+// sending them anywhere. Requests are matched by method, URL and body. If the same request was
+// recorded several times, its responses are replayed in the recorded order, and the last one is
+// repeated once they run out. Requests that were not recorded fail.
+type Player struct {
+	mu        sync.Mutex
+	responses map[string][]Response
+}
+
+// NewPlayer loads the cassettes in dir.
+func NewPlayer(dir string) (*Player, error) {
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no cassettes found in %s", dir)
+	}
+
+	p := &Player{responses: make(map[string][]Response)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", filepath.Base(file), err)
+		}
+		key := interactionKey(interaction.Request.Method, interaction.Request.URL, interaction.Request.Body)
+		p.responses[key] = append(p.responses[key], interaction.Response)
+	}
+	return p, nil
+}
+
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Recorded requests were scrubbed, so the live request is scrubbed the same way to match
+	key := interactionKey(req.Method, mcplog.Redact(req.URL.String()), scrubBody(body))
+
+	p.mu.Lock()
+	responses := p.responses[key]
+	if len(responses) == 0 {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL)
+	}
+	recorded := responses[0]
+	if len(responses) > 1 {
+		p.responses[key] = responses[1:]
+	}
+	p.mu.Unlock()
+
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+func interactionKey(method, url string, body []byte) string {
+	return method + " " + url + "\n" + string(body)
+}
+
+func cassetteFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cassettes: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,193 @@
+// Package confirmation asks the user to approve calls to selected tools before they run, with an MCP
+// elicitation request that summarizes what the call will change. Calls are refused when the user
+// doesn't approve them, or when the client can't ask.
+package confirmation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/dryrun"
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxValueLength bounds the characters of an argument shown in a summary.
+const maxValueLength = 200
+
+// rule is a parsed tool or tool:method pattern.
+type rule struct {
+	tool   string
+	method string
+}
+
+// Policy tells which tool calls need the user's approval.
+type Policy struct {
+	rules []rule
+}
+
+// New parses the patterns of the tools that need approval: a tool name or glob pattern in the syntax
+// of path.Match, such as delete_*, optionally followed by :method to only confirm calls with that
+// method argument, such as label_write:delete.
+func New(patterns []string) (*Policy, error) {
+	p := &Policy{}
+	for _, pattern := range patterns {
+		tool, method, _ := strings.Cut(strings.TrimSpace(pattern), ":")
+		if tool == "" {
+			return nil, fmt.Errorf("invalid confirmation pattern %q: a tool is required", pattern)
+		}
+		if _, err := path.Match(tool, ""); err != nil {
+			return nil, fmt.Errorf("invalid confirmation pattern %q: %w", pattern, err)
+		}
+		p.rules = append(p.rules, rule{tool: tool, method: method})
+	}
+	return p, nil
+}
+
+// Requires tells whether a call to the tool with the arguments needs the user's approval.
+func (p *Policy) Requires(tool string, args map[string]any) bool {
+	method, _ := args["method"].(string)
+	for _, r := range p.rules {
+		if matched, _ := path.Match(r.tool, tool); matched && (r.method == "" || r.method == method) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTool tells whether any call to the tool may need the user's approval, whatever its method.
+func (p *Policy) matchesTool(tool string) bool {
+	for _, r := range p.rules {
+		if matched, _ := path.Match(r.tool, tool); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware returns receiving middleware that asks the user to approve the calls that need it before
+// they are handled, except for dry runs. Calls that are not approved get an error result that tells why.
+func (p *Policy) Middleware(logger *slog.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			// Dry runs don't change anything, so they need no approval
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil || dryrun.IsDryRun(ctx) {
+				return next(ctx, method, req)
+			}
+			tool := call.Params.Name
+			var args map[string]any
+			if len(call.Params.Arguments) > 0 && json.Unmarshal(call.Params.Arguments, &args) != nil {
+				// The user can't be told what the call would change, nor can its method be matched
+				if !p.matchesTool(tool) {
+					return next(ctx, method, req)
+				}
+				refusal := fmt.Sprintf("the arguments of %s can't be read to ask for confirmation, so the call was refused", tool)
+				logger.InfoContext(ctx, "tool call not confirmed", "tool", tool, "reason", refusal)
+				return utils.NewToolResultError(refusal), nil
+			}
+			if !p.Requires(tool, args) {
+				return next(ctx, method, req)
+			}
+
+			if refusal := confirm(ctx, call.Session, tool, args); refusal != "" {
+				logger.InfoContext(ctx, "tool call not confirmed", "tool", tool, "reason", refusal)
+				return utils.NewToolResultError(refusal), nil
+			}
+			logger.InfoContext(ctx, "tool call confirmed", "tool", tool)
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// confirm asks the user to approve the call, and returns why it is refused, or an empty string if it
+// is approved.
+func confirm(ctx context.Context, session *mcp.ServerSession, tool string, args map[string]any) string {
+	if session == nil || session.InitializeParams() == nil || session.InitializeParams().Capabilities == nil ||
+		session.InitializeParams().Capabilities.Elicitation == nil {
+		return fmt.Sprintf("%s must be confirmed by the user, but the client doesn't support elicitation, so the call was refused", tool)
+	}
+
+	result, err := session.Elicit(ctx, &mcp.ElicitParams{
+		Message: Summary(tool, args),
+		// Nothing is asked for but the approval itself
+		RequestedSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+	})
+	if err != nil {
+		return fmt.Sprintf("%s must be confirmed by the user, but asking failed, so the call was refused: %v", tool, err)
+	}
+	switch result.Action {
+	case "accept":
+		return ""
+	case "decline":
+		return fmt.Sprintf("the user declined the call to %s", tool)
+	default:
+		return fmt.Sprintf("the user didn't confirm the call to %s", tool)
+	}
+}
+
+// Summary describes what a call to the tool with the arguments will change, for the user to approve:
+// the repository, then every other argument, with file contents and other long values shortened.
+func Summary(tool string, args map[string]any) string {
+	var b strings.Builder
+	owner, _ := args["owner"].(string)
+	repo, _ := args["repo"].(string)
+	if owner != "" && repo != "" {
+		fmt.Fprintf(&b, "Allow %s on %s/%s?\n", tool, owner, repo)
+	} else {
+		fmt.Fprintf(&b, "Allow %s?\n", tool)
+	}
+
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		if (key == "owner" || key == "repo") && owner != "" && repo != "" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n- %s: %s", key, summarizeValue(args[key]))
+	}
+	return b.String()
+}
+
+func summarizeValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return shorten(v)
+	case []any:
+		// Lists of files, as given to push_files, are summarized by their paths
+		var paths []string
+		for _, item := range v {
+			object, _ := item.(map[string]any)
+			filePath, ok := object["path"].(string)
+			if !ok {
+				paths = nil
+				break
+			}
+			paths = append(paths, filePath)
+		}
+		if len(paths) > 0 {
+			return shorten(strings.Join(paths, ", "))
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return shorten(string(data))
+}
+
+func shorten(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxValueLength {
+		return s
+	}
+	return fmt.Sprintf("%s… (%d characters)", string(runes[:maxValueLength]), len(runes))
+}
@@ -0,0 +1,158 @@
+package confirmation
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New(t *testing.T) {
+	_, err := New([]string{"merge_pull_request", "delete_*", "label_write:delete"})
+	require.NoError(t, err)
+
+	_, err = New([]string{":delete"})
+	assert.EqualError(t, err, `invalid confirmation pattern ":delete": a tool is required`)
+	_, err = New([]string{"delete_["})
+	assert.EqualError(t, err, `invalid confirmation pattern "delete_[": syntax error in pattern`)
+}
+
+func Test_Requires(t *testing.T) {
+	policy, err := New([]string{"merge_pull_request", "delete_*", "label_write:delete"})
+	require.NoError(t, err)
+
+	assert.True(t, policy.Requires("merge_pull_request", nil))
+	assert.True(t, policy.Requires("delete_file", map[string]any{"path": "README.md"}))
+	assert.True(t, policy.Requires("label_write", map[string]any{"method": "delete"}))
+	assert.False(t, policy.Requires("label_write", map[string]any{"method": "create"}))
+	assert.False(t, policy.Requires("get_me", nil))
+}
+
+func Test_Summary(t *testing.T) {
+	summary := Summary("push_files", map[string]any{
+		"owner":   "octo",
+		"repo":    "hello",
+		"branch":  "main",
+		"message": strings.Repeat("a", 250),
+		"files": []any{
+			map[string]any{"path": "a.go", "content": "package a"},
+			map[string]any{"path": "b/b.go", "content": "package b"},
+		},
+	})
+	assert.Equal(t, "Allow push_files on octo/hello?\n"+
+		"\n- branch: main"+
+		"\n- files: a.go, b/b.go"+
+		"\n- message: "+strings.Repeat("a", 200)+"… (250 characters)", summary)
+
+	assert.Equal(t, "Allow merge_pull_request?\n\n- owner: octo\n- pullNumber: 42", Summary("merge_pull_request", map[string]any{"owner": "octo", "pullNumber": 42}))
+}
+
+// callTool calls delete_file on a server that confirms it, from a client with the elicitation handler,
+// and tells whether the tool ran.
+func callTool(t *testing.T, elicitationHandler func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error)) (*mcp.CallToolResult, bool) {
+	t.Helper()
+	policy, err := New([]string{"delete_*"})
+	require.NoError(t, err)
+
+	ran := false
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(policy.Middleware(slog.New(slog.DiscardHandler)))
+	server.AddTool(&mcp.Tool{Name: "delete_file", InputSchema: &jsonschema.Schema{Type: "object"}},
+		func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ran = true
+			return utils.NewToolResultText("deleted"), nil
+		})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{ElicitationHandler: elicitationHandler})
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer clientSession.Close()
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "delete_file",
+		Arguments: map[string]any{"owner": "octo", "repo": "hello", "path": "README.md", "branch": "main"},
+	})
+	require.NoError(t, err)
+	return result, ran
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	return result.Content[0].(*mcp.TextContent).Text
+}
+
+func Test_Middleware(t *testing.T) {
+	t.Run("approved", func(t *testing.T) {
+		var message string
+		result, ran := callTool(t, func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			message = req.Params.Message
+			return &mcp.ElicitResult{Action: "accept"}, nil
+		})
+		assert.True(t, ran)
+		assert.False(t, result.IsError)
+		assert.Equal(t, "Allow delete_file on octo/hello?\n\n- branch: main\n- path: README.md", message)
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		result, ran := callTool(t, func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return &mcp.ElicitResult{Action: "decline"}, nil
+		})
+		assert.False(t, ran)
+		assert.True(t, result.IsError)
+		assert.Equal(t, "the user declined the call to delete_file", resultText(result))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		result, ran := callTool(t, func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return &mcp.ElicitResult{Action: "cancel"}, nil
+		})
+		assert.False(t, ran)
+		assert.Equal(t, "the user didn't confirm the call to delete_file", resultText(result))
+	})
+
+	t.Run("client without elicitation", func(t *testing.T) {
+		result, ran := callTool(t, nil)
+		assert.False(t, ran)
+		assert.True(t, result.IsError)
+		assert.Equal(t, "delete_file must be confirmed by the user, but the client doesn't support elicitation, so the call was refused", resultText(result))
+	})
+}
+
+func Test_Middleware_UnreadableArguments(t *testing.T) {
+	policy, err := New([]string{"label_write:delete"})
+	require.NoError(t, err)
+
+	ran := false
+	handler := policy.Middleware(slog.New(slog.DiscardHandler))(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		ran = true
+		return utils.NewToolResultText("done"), nil
+	})
+	call := func(tool string) *mcp.CallToolResult {
+		result, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: tool, Arguments: json.RawMessage(`["delete"]`)},
+		})
+		require.NoError(t, err)
+		return result.(*mcp.CallToolResult)
+	}
+
+	result := call("label_write")
+	assert.False(t, ran)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "the arguments of label_write can't be read to ask for confirmation, so the call was refused", resultText(result))
+
+	result = call("get_me")
+	assert.True(t, ran, "tools that are never confirmed run as usual")
+	assert.False(t, result.IsError)
+}
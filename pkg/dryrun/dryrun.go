@@ -0,0 +1,253 @@
+// Package dryrun lets write tools be called without changing anything on GitHub. In a dry run, a
+// write tool validates its arguments and sends the requests that only read, such as resolving a
+// branch to its SHA, as usual. Instead of sending the requests that would change data, it plans them
+// with clients whose transport is a Recorder, and returns them. Tools that don't plan their
+// operations can't be called as dry runs.
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ArgumentName is the argument of write tools that makes a single call a dry run.
+const ArgumentName = "dry_run"
+
+// ErrNotSent is the error of the requests that are not sent because of a dry run.
+var ErrNotSent = errors.New("request not sent in a dry run")
+
+// Parameter returns the schema of the ArgumentName argument, for the input schemas of the write tools
+// that plan their operations in dry runs.
+func Parameter() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Validate the call and return the GitHub API operations it would perform, without changing anything",
+	}
+}
+
+// Supported reports whether a tool can be called as a dry run, that is whether its input schema has
+// the ArgumentName argument.
+func Supported(tool *mcp.Tool) bool {
+	schema, ok := tool.InputSchema.(*jsonschema.Schema)
+	if !ok {
+		return false
+	}
+	_, ok = schema.Properties[ArgumentName]
+	return ok
+}
+
+type dryRunKey struct{}
+
+// ContextWithDryRun returns a context in which tool calls are dry runs.
+func ContextWithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether the context is that of a dry run.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// Requested reports whether a call to a write tool with the given arguments is a dry run, either
+// because its context is that of one or because its ArgumentName argument is true.
+func Requested(ctx context.Context, args map[string]any) bool {
+	dryRun, _ := args[ArgumentName].(bool)
+	return dryRun || IsDryRun(ctx)
+}
+
+// Reference stands in for a field of the result of an earlier operation of the plan, counting from 1,
+// in the operations that depend on it, such as the SHA of a tree in the commit that is created of it.
+func Reference(operation int, field string) string {
+	return fmt.Sprintf("(%s of operation %d)", field, operation)
+}
+
+// Operation is a request that a tool would send to change data on GitHub.
+type Operation struct {
+	// API is "rest" or "graphql"
+	API string `json:"api"`
+	// Method and URL are those of the REST request
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+	// Body is the body of the REST request, if any
+	Body json.RawMessage `json:"body,omitempty"`
+	// Query and Variables are those of the GraphQL mutation
+	Query     string          `json:"query,omitempty"`
+	Variables json.RawMessage `json:"variables,omitempty"`
+}
+
+// Recorder is the transport of the clients that plan the operations of a dry run. It records each
+// request as an operation, requests to a path ending in /graphql as GraphQL ones, and fails it with
+// ErrNotSent. It has no underlying transport, so nothing it gets can reach GitHub.
+type Recorder struct {
+	mu         sync.Mutex
+	operations []Operation
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	op := Operation{API: "rest", Method: req.Method, URL: req.URL.String()}
+	if strings.HasSuffix(req.URL.Path, "/graphql") {
+		var graphQL struct {
+			Query     string          `json:"query"`
+			Variables json.RawMessage `json:"variables"`
+		}
+		if err := json.Unmarshal(body, &graphQL); err != nil {
+			return nil, fmt.Errorf("failed to parse GraphQL request: %w", err)
+		}
+		op = Operation{API: "graphql", Query: graphQL.Query, Variables: graphQL.Variables}
+	} else if json.Valid(body) {
+		op.Body = body
+	}
+
+	r.mu.Lock()
+	r.operations = append(r.operations, op)
+	r.mu.Unlock()
+	return nil, ErrNotSent
+}
+
+// Operations returns the operations recorded so far.
+func (r *Recorder) Operations() []Operation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Operation{}, r.operations...)
+}
+
+// Result is the result of a dry run.
+type Result struct {
+	DryRun bool `json:"dry_run"`
+	// Operations are the GitHub API requests the call would send to change data, in order
+	Operations []Operation `json:"operations"`
+}
+
+// ToolResult returns the operations recorded so far as the result of a dry run.
+func (r *Recorder) ToolResult() (*mcp.CallToolResult, error) {
+	data, err := json.Marshal(Result{DryRun: true, Operations: r.Operations()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dry run result: %w", err)
+	}
+	return utils.NewToolResultText(string(data)), nil
+}
+
+// Transport refuses to send the requests that change data in the context of a dry run, see
+// ContextWithDryRun, so that a tool can't change anything by mistake when it plans its operations,
+// and sends all other requests through the underlying transport.
+type Transport struct {
+	transport http.RoundTripper
+}
+
+// NewTransport creates a transport sending the requests it doesn't refuse through transport.
+func NewTransport(transport http.RoundTripper) *Transport {
+	return &Transport{transport: transport}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !IsDryRun(req.Context()) || req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+		return t.transport.RoundTrip(req)
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The GraphQL API is read with POST requests too, only queries are sent
+	if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/graphql") && isGraphQLQuery(body) {
+		return t.transport.RoundTrip(req)
+	}
+
+	return nil, fmt.Errorf("%w: %s %s would change data", ErrNotSent, req.Method, req.URL.Redacted())
+}
+
+// isGraphQLQuery reports whether the body of a GraphQL request is a query, as opposed to a mutation or
+// subscription. Like the retries, it only lets through documents starting with a query, here after the
+// comments GraphQL ignores. Requests naming an operation are refused too, as the document may hold a
+// mutation after the query, and the GitHub clients never name one.
+func isGraphQLQuery(body []byte) bool {
+	var payload struct {
+		Query         string `json:"query"`
+		OperationName string `json:"operationName"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.OperationName != "" {
+		return false
+	}
+	query := skipIgnored(payload.Query)
+	return strings.HasPrefix(query, "{") || strings.HasPrefix(query, "query")
+}
+
+// skipIgnored drops the white space, commas and comments that GraphQL ignores at the start of a document.
+func skipIgnored(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n,\ufeff")
+		if !strings.HasPrefix(query, "#") {
+			return query
+		}
+		end := strings.IndexAny(query, "\r\n")
+		if end < 0 {
+			return ""
+		}
+		query = query[end:]
+	}
+}
+
+// readBody reads the body of the request and restores it, so that it can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// FindToolFunc returns the tool with the given name, if there is one.
+type FindToolFunc func(name string) (*mcp.Tool, bool)
+
+// Middleware returns receiving middleware that makes calls to write tools dry runs, all of them if
+// global is set, and otherwise those with the ArgumentName argument set to true. Calls to write tools
+// that don't support dry runs, see Supported, are refused rather than run.
+func Middleware(global bool, findTool FindToolFunc) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+			tool, ok := findTool(call.Params.Name)
+			if !ok || (tool.Annotations != nil && tool.Annotations.ReadOnlyHint) {
+				return next(ctx, method, req)
+			}
+			if !global {
+				var args map[string]any
+				_ = json.Unmarshal(call.Params.Arguments, &args)
+				if dryRun, _ := args[ArgumentName].(bool); !dryRun {
+					return next(ctx, method, req)
+				}
+			}
+
+			if !Supported(tool) {
+				return utils.NewToolResultError(fmt.Sprintf("%s can't be called as a dry run, as it doesn't plan its operations, so the call was refused", tool.Name)), nil
+			}
+			return next(ContextWithDryRun(ctx), method, req)
+		}
+	}
+}
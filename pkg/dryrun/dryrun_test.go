@@ -0,0 +1,138 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Recorder(t *testing.T) {
+	recorder := &Recorder{}
+	client := &http.Client{Transport: recorder}
+	send := func(method, url, body string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		_, err = client.Do(req) //nolint:bodyclose // there is no response
+		assert.ErrorIs(t, err, ErrNotSent)
+	}
+
+	send(http.MethodPost, "https://api.github.com/repos/octo/hello/git/trees", `{"base_tree":"abc"}`)
+	send(http.MethodDelete, "https://api.github.com/repos/octo/hello/labels/bug", "")
+	send(http.MethodPost, "https://api.github.com/graphql", `{"query":"mutation($input:AddCommentInput!){addComment(input:$input){clientMutationId}}","variables":{"input":{"body":"Hi"}}}`)
+
+	operations := recorder.Operations()
+	require.Len(t, operations, 3)
+	assert.Equal(t, Operation{API: "rest", Method: "POST", URL: "https://api.github.com/repos/octo/hello/git/trees", Body: json.RawMessage(`{"base_tree":"abc"}`)}, operations[0])
+	assert.Equal(t, Operation{API: "rest", Method: "DELETE", URL: "https://api.github.com/repos/octo/hello/labels/bug"}, operations[1])
+	assert.Equal(t, "graphql", operations[2].API)
+	assert.Contains(t, operations[2].Query, "addComment")
+	assert.JSONEq(t, `{"input":{"body":"Hi"}}`, string(operations[2].Variables))
+
+	result, err := recorder.ToolResult()
+	require.NoError(t, err)
+	var dryRun Result
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &dryRun))
+	assert.True(t, dryRun.DryRun)
+	assert.Equal(t, operations, dryRun.Operations)
+}
+
+func Test_Transport(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"login":"octo"}}}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport)}
+	send := func(ctx context.Context, method, path, body string) error {
+		req, err := http.NewRequestWithContext(ctx, method, server.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	// Outside of dry runs everything is sent
+	require.NoError(t, send(context.Background(), http.MethodPost, "/repos/octo/hello/issues", `{"title":"Bug"}`))
+
+	ctx := ContextWithDryRun(context.Background())
+	require.NoError(t, send(ctx, http.MethodGet, "/repos/octo/hello/git/ref/heads/main", ""))
+	require.NoError(t, send(ctx, http.MethodPost, "/graphql", `{"query":"query{viewer{login}}"}`))
+	assert.ErrorIs(t, send(ctx, http.MethodPatch, "/repos/octo/hello/git/refs/heads/main", `{"sha":"abc"}`), ErrNotSent)
+	assert.ErrorIs(t, send(ctx, http.MethodDelete, "/repos/octo/hello/labels/bug", ""), ErrNotSent)
+	assert.ErrorIs(t, send(ctx, http.MethodPost, "/graphql", `{"query":"mutation($input:AddCommentInput!){addComment(input:$input){clientMutationId}}"}`), ErrNotSent)
+	assert.ErrorIs(t, send(ctx, http.MethodPost, "/graphql", `{"query":"# add a comment\nmutation($input:AddCommentInput!){addComment(input:$input){clientMutationId}}"}`), ErrNotSent,
+		"mutations after comments are refused")
+	assert.ErrorIs(t, send(ctx, http.MethodPost, "/graphql", `{"query":"query{viewer{login}} mutation{addStar(input:{starrableId:\"1\"}){clientMutationId}}","operationName":"Star"}`), ErrNotSent,
+		"documents with a named operation are refused")
+	assert.ErrorIs(t, send(ctx, http.MethodPost, "/repos/octo/hello/issues", `{"query":"query","title":"Bug"}`), ErrNotSent,
+		"REST requests are refused even if their body has a query")
+	require.NoError(t, send(ctx, http.MethodPost, "/graphql", `{"query":"# the viewer\n{viewer{login}}"}`))
+
+	assert.Equal(t, []string{
+		"POST /repos/octo/hello/issues",
+		"GET /repos/octo/hello/git/ref/heads/main",
+		"POST /graphql",
+		"POST /graphql",
+	}, sent, "only reads are sent in dry runs")
+}
+
+func Test_Requested(t *testing.T) {
+	assert.False(t, Requested(context.Background(), map[string]any{"owner": "octo"}))
+	assert.True(t, Requested(context.Background(), map[string]any{ArgumentName: true}))
+	assert.True(t, Requested(ContextWithDryRun(context.Background()), map[string]any{}))
+}
+
+func Test_Middleware(t *testing.T) {
+	tools := map[string]*mcp.Tool{
+		"get_me":       {Name: "get_me", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}, InputSchema: &jsonschema.Schema{Type: "object"}},
+		"push_files":   {Name: "push_files", Annotations: &mcp.ToolAnnotations{}, InputSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{ArgumentName: Parameter()}}},
+		"delete_files": {Name: "delete_files", Annotations: &mcp.ToolAnnotations{}, InputSchema: &jsonschema.Schema{Type: "object"}},
+	}
+	findTool := func(name string) (*mcp.Tool, bool) {
+		tool, ok := tools[name]
+		return tool, ok
+	}
+	// The handler tells whether it was called in a dry run
+	handler := func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		if IsDryRun(ctx) {
+			return utils.NewToolResultText("planned"), nil
+		}
+		return utils.NewToolResultText("done"), nil
+	}
+	call := func(global bool, tool, arguments string) *mcp.CallToolResult {
+		result, err := Middleware(global, findTool)(handler)(context.Background(), "tools/call", &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: tool, Arguments: json.RawMessage(arguments)},
+		})
+		require.NoError(t, err)
+		return result.(*mcp.CallToolResult)
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	assert.Equal(t, "done", text(call(false, "push_files", `{"owner":"octo"}`)), "calls without dry_run run as usual")
+	assert.Equal(t, "planned", text(call(false, "push_files", `{"owner":"octo","dry_run":true}`)))
+	assert.Equal(t, "planned", text(call(true, "push_files", `{"owner":"octo"}`)), "all calls are dry runs with global set")
+	assert.Equal(t, "done", text(call(true, "get_me", `{}`)), "read-only tools are not dry runs")
+	assert.Equal(t, "done", text(call(false, "delete_files", `{}`)))
+
+	result := call(true, "delete_files", `{}`)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "delete_files can't be called as a dry run, as it doesn't plan its operations, so the call was refused", text(result))
+
+	result = call(false, "delete_files", `{"dry_run":true}`)
+	assert.True(t, result.IsError, "tools that don't plan their operations are refused rather than run")
+}
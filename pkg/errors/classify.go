@@ -0,0 +1,300 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/ratelimit"
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/google/go-github/v79/github"
+)
+
+// Code classifies why a GitHub API request failed. Codes are stable, so that clients can act on them.
+type Code string
+
+const (
+	// CodeNotFound means the resource doesn't exist, or the token can't see it
+	CodeNotFound Code = "not_found"
+	// CodeSSORequired means the organization enforces SAML single sign-on and the token isn't authorized for it
+	CodeSSORequired Code = "sso_required"
+	// CodeRateLimited means the primary rate limit is exhausted
+	CodeRateLimited Code = "rate_limited"
+	// CodeSecondaryRateLimited means a secondary rate limit was hit, e.g. by sending too many requests at once
+	CodeSecondaryRateLimited Code = "secondary_rate_limited"
+	// CodeInsufficientScopes means the classic token lacks the OAuth scopes the request needs
+	CodeInsufficientScopes Code = "insufficient_scopes"
+	// CodeInsufficientPermissions means the fine-grained token or GitHub App lacks the permissions the request needs
+	CodeInsufficientPermissions Code = "insufficient_permissions"
+	// CodeMoved means the resource moved, e.g. because the repository was renamed or transferred
+	CodeMoved Code = "moved"
+	// CodeAppSuspended means the GitHub App installation is suspended
+	CodeAppSuspended Code = "app_suspended"
+	// CodeBadCredentials means the token is invalid, expired or revoked
+	CodeBadCredentials Code = "bad_credentials"
+	// CodeForbidden means the request was refused for another reason, e.g. a branch protection rule
+	CodeForbidden Code = "forbidden"
+	// CodeValidationFailed means GitHub rejected the input of the request
+	CodeValidationFailed Code = "validation_failed"
+	// CodeConflict means the request conflicts with the state of the resource, e.g. a stale SHA
+	CodeConflict Code = "conflict"
+	// CodeServerError means GitHub failed to handle the request
+	CodeServerError Code = "server_error"
+	// CodeUnknown means the error could not be classified
+	CodeUnknown Code = "unknown"
+)
+
+// Classification is the code of an error, with a hint on how to resolve it.
+type Classification struct {
+	Code Code `json:"code"`
+	// Hint tells what the user or agent can do about the error (empty if there is no advice)
+	Hint string `json:"hint,omitempty"`
+}
+
+// ssoURLPattern extracts the authorization URL from an X-GitHub-SSO header, such as
+// "required; url=https://github.com/orgs/octo-org/sso?authorization_request=...".
+var ssoURLPattern = regexp.MustCompile(`url=(\S+)`)
+
+// graphQLScopesPattern extracts the scopes from GraphQL errors such as "Your token has not been granted the
+// required scopes to execute this query. The 'login' field requires one of the following scopes: ['read:org']".
+var graphQLScopesPattern = regexp.MustCompile(`requires one of the following scopes: \[([^\]]*)\]`)
+
+// ClassifyAPIError classifies an error returned by the go-github client. resp may be nil, in which case
+// the response is taken from err where possible.
+func ClassifyAPIError(resp *github.Response, err error) Classification {
+	var exhausted *ratelimit.ExhaustedError
+	var secondary *ratelimit.SecondaryLimitError
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var redirectErr *github.RedirectionError
+	var errResp *github.ErrorResponse
+
+	switch {
+	case errors.As(err, &exhausted):
+		return rateLimited(exhausted.Budget.Reset)
+	case errors.As(err, &secondary):
+		return secondaryRateLimited(time.Until(secondary.RetryAt))
+	case errors.As(err, &rateLimitErr):
+		return rateLimited(rateLimitErr.Rate.Reset.Time)
+	case errors.As(err, &abuseErr):
+		var retryAfter time.Duration
+		if abuseErr.RetryAfter != nil {
+			retryAfter = *abuseErr.RetryAfter
+		}
+		return secondaryRateLimited(retryAfter)
+	case errors.As(err, &redirectErr):
+		var location string
+		if redirectErr.Location != nil {
+			location = redirectErr.Location.String()
+		}
+		return moved(location)
+	}
+
+	var httpResp *http.Response
+	var message string
+	if resp != nil {
+		httpResp = resp.Response
+	}
+	if errors.As(err, &errResp) {
+		if httpResp == nil {
+			httpResp = errResp.Response
+		}
+		message = errResp.Message
+	}
+	if httpResp == nil {
+		return Classification{Code: CodeUnknown}
+	}
+	return classifyResponse(httpResp, message)
+}
+
+func classifyResponse(resp *http.Response, message string) Classification {
+	lowerMessage := strings.ToLower(message)
+
+	if sso := resp.Header.Get("X-GitHub-SSO"); strings.HasPrefix(sso, "required") {
+		hint := "The organization enforces SAML single sign-on. Authorize the token for the organization"
+		if match := ssoURLPattern.FindStringSubmatch(sso); match != nil {
+			hint += " at " + match[1]
+		}
+		return Classification{Code: CodeSSORequired, Hint: hint + ", then retry."}
+	}
+
+	switch status := resp.StatusCode; {
+	case status == http.StatusUnauthorized:
+		return Classification{
+			Code: CodeBadCredentials,
+			Hint: "The token is invalid, expired or revoked. Create a new token and restart the server with it.",
+		}
+	case (status == http.StatusForbidden || status == http.StatusTooManyRequests) && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return rateLimited(parseReset(resp))
+	case status == http.StatusTooManyRequests:
+		return secondaryRateLimited(parseRetryAfter(resp))
+	case status >= 300 && status < 400:
+		return moved(resp.Header.Get("Location"))
+	case status == http.StatusForbidden && strings.Contains(lowerMessage, "secondary rate limit"):
+		return secondaryRateLimited(parseRetryAfter(resp))
+	case status == http.StatusForbidden && strings.Contains(lowerMessage, "suspended"):
+		return Classification{
+			Code: CodeAppSuspended,
+			Hint: "The GitHub App installation is suspended. An owner of the account it is installed on has to unsuspend it.",
+		}
+	}
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound {
+		// GitHub answers 404 rather than 403 for private resources the token can't see
+		if classification, ok := missingScopes(resp); ok {
+			return classification
+		}
+	}
+
+	switch status := resp.StatusCode; {
+	case strings.Contains(lowerMessage, "resource not accessible by"):
+		hint := "The token or GitHub App lacks a permission the request needs. Grant it and retry."
+		if permissions := resp.Header.Get("X-Accepted-GitHub-Permissions"); permissions != "" {
+			hint = fmt.Sprintf("The token or GitHub App lacks a permission the request needs (accepted: %s). Grant it and retry.", permissions)
+		}
+		return Classification{Code: CodeInsufficientPermissions, Hint: hint}
+	case status == http.StatusNotFound:
+		return Classification{
+			Code: CodeNotFound,
+			Hint: "Check the owner, repository and number or name for typos. Private resources also return this error when the token can't access them.",
+		}
+	case status == http.StatusForbidden:
+		return Classification{Code: CodeForbidden, Hint: "GitHub refused the request. The error message tells why."}
+	case status == http.StatusConflict:
+		return Classification{Code: CodeConflict, Hint: "The resource changed in the meantime. Fetch its current state, e.g. the latest SHA, and retry."}
+	case status == http.StatusUnprocessableEntity:
+		return Classification{Code: CodeValidationFailed, Hint: "Correct the arguments the error message points out and retry."}
+	case status >= 500:
+		return Classification{Code: CodeServerError, Hint: "GitHub failed to handle the request. Retry later, and check https://www.githubstatus.com if it persists."}
+	}
+	return Classification{Code: CodeUnknown}
+}
+
+// missingScopes reports whether the response tells the classic token lacks the scopes the endpoint accepts.
+func missingScopes(resp *http.Response) (Classification, bool) {
+	accepted := splitScopes(resp.Header.Get("X-Accepted-OAuth-Scopes"))
+	granted := resp.Header.Values("X-OAuth-Scopes")
+	if len(accepted) == 0 || len(granted) == 0 {
+		// Without both headers this is not a classic token, or not a scope problem
+		return Classification{}, false
+	}
+
+	grantedScopes := scopes.Expand(splitScopes(strings.Join(granted, ",")))
+	for _, scope := range accepted {
+		if grantedScopes[scope] {
+			return Classification{}, false
+		}
+	}
+	return insufficientScopes(accepted), true
+}
+
+func splitScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// ClassifyGraphQLError classifies an error returned by the githubv4 client. GraphQL errors come with
+// a 200 response, so they are classified by their message.
+func ClassifyGraphQLError(err error) Classification {
+	var exhausted *ratelimit.ExhaustedError
+	var secondary *ratelimit.SecondaryLimitError
+	switch {
+	case err == nil:
+		return Classification{Code: CodeUnknown}
+	case errors.As(err, &exhausted):
+		return rateLimited(exhausted.Budget.Reset)
+	case errors.As(err, &secondary):
+		return secondaryRateLimited(time.Until(secondary.RetryAt))
+	}
+
+	message := err.Error()
+	lowerMessage := strings.ToLower(message)
+	switch {
+	case strings.Contains(lowerMessage, "saml enforcement") || strings.Contains(lowerMessage, "saml sso"):
+		return Classification{
+			Code: CodeSSORequired,
+			Hint: "The organization enforces SAML single sign-on. Authorize the token for the organization in its settings, then retry.",
+		}
+	case strings.Contains(lowerMessage, "secondary rate limit"):
+		return secondaryRateLimited(0)
+	case strings.Contains(lowerMessage, "rate limit"):
+		return rateLimited(time.Time{})
+	case graphQLScopesPattern.MatchString(message):
+		var scopes []string
+		for _, scope := range strings.Split(graphQLScopesPattern.FindStringSubmatch(message)[1], ",") {
+			scopes = append(scopes, strings.Trim(strings.TrimSpace(scope), `'"`))
+		}
+		return insufficientScopes(scopes)
+	case strings.Contains(lowerMessage, "resource not accessible by"):
+		return Classification{
+			Code: CodeInsufficientPermissions,
+			Hint: "The token or GitHub App lacks a permission the request needs. Grant it and retry.",
+		}
+	case strings.Contains(lowerMessage, "could not resolve to"):
+		return Classification{
+			Code: CodeNotFound,
+			Hint: "Check the owner, repository and number or name for typos. Private resources also return this error when the token can't access them.",
+		}
+	case strings.Contains(lowerMessage, "bad credentials"), strings.Contains(lowerMessage, "401 unauthorized"):
+		return Classification{
+			Code: CodeBadCredentials,
+			Hint: "The token is invalid, expired or revoked. Create a new token and restart the server with it.",
+		}
+	}
+	return Classification{Code: CodeUnknown}
+}
+
+func rateLimited(reset time.Time) Classification {
+	hint := "The GitHub API rate limit is exhausted. Wait until it resets before retrying"
+	if !reset.IsZero() {
+		hint += " at " + reset.UTC().Format(time.RFC3339)
+	}
+	return Classification{Code: CodeRateLimited, Hint: hint + ", and prefer fewer, more specific requests."}
+}
+
+func secondaryRateLimited(retryAfter time.Duration) Classification {
+	hint := "A GitHub API secondary rate limit was hit. Slow down and avoid concurrent requests"
+	if retryAfter > 0 {
+		hint += fmt.Sprintf(", and wait %s before retrying", retryAfter.Round(time.Second))
+	}
+	return Classification{Code: CodeSecondaryRateLimited, Hint: hint + "."}
+}
+
+func insufficientScopes(scopes []string) Classification {
+	return Classification{
+		Code: CodeInsufficientScopes,
+		Hint: fmt.Sprintf("The token lacks the OAuth scopes the request needs. Grant it one of: %s.", strings.Join(scopes, ", ")),
+	}
+}
+
+func moved(location string) Classification {
+	hint := "The resource moved, e.g. because the repository was renamed or transferred."
+	if location != "" {
+		hint = fmt.Sprintf("The resource moved to %s, e.g. because the repository was renamed or transferred. Retry with its new location.", location)
+	}
+	return Classification{Code: CodeMoved, Hint: hint}
+}
+
+func parseReset(resp *http.Response) time.Time {
+	var reset int64
+	if _, err := fmt.Sscan(resp.Header.Get("X-RateLimit-Reset"), &reset); err != nil {
+		return time.Time{}
+	}
+	return time.Unix(reset, 0)
+}
+
+func parseRetryAfter(resp *http.Response) time.Duration {
+	var seconds int64
+	if _, err := fmt.Sscan(resp.Header.Get("Retry-After"), &seconds); err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,208 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/ratelimit"
+	"github.com/google/go-github/v79/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func errorResponse(status int, message string, header map[string]string) *github.ErrorResponse {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	for key, value := range header {
+		resp.Header.Set(key, value)
+	}
+	return &github.ErrorResponse{Response: resp, Message: message}
+}
+
+func TestClassifyAPIError(t *testing.T) {
+	reset := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	retryAfter := 30 * time.Second
+	location, _ := url.Parse("https://api.github.com/repositories/42")
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode Code
+		expectedHint string
+	}{
+		{
+			name:         "not found",
+			err:          errorResponse(http.StatusNotFound, "Not Found", nil),
+			expectedCode: CodeNotFound,
+		},
+		{
+			name: "SAML SSO enforcement",
+			err: errorResponse(http.StatusForbidden, "Resource protected by organization SAML enforcement.", map[string]string{
+				"X-GitHub-SSO": "required; url=https://github.com/orgs/octo-org/sso?authorization_request=abc",
+			}),
+			expectedCode: CodeSSORequired,
+			expectedHint: "The organization enforces SAML single sign-on. Authorize the token for the organization at https://github.com/orgs/octo-org/sso?authorization_request=abc, then retry.",
+		},
+		{
+			name: "primary rate limit",
+			err: &github.RateLimitError{
+				Rate:     github.Rate{Limit: 5000, Reset: github.Timestamp{Time: reset}},
+				Response: &http.Response{StatusCode: http.StatusForbidden},
+			},
+			expectedCode: CodeRateLimited,
+			expectedHint: "The GitHub API rate limit is exhausted. Wait until it resets before retrying at 2025-01-02T15:04:05Z, and prefer fewer, more specific requests.",
+		},
+		{
+			name:         "primary rate limit from headers",
+			err:          errorResponse(http.StatusTooManyRequests, "API rate limit exceeded", map[string]string{"X-RateLimit-Remaining": "0"}),
+			expectedCode: CodeRateLimited,
+		},
+		{
+			name:         "secondary rate limit",
+			err:          &github.AbuseRateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}, RetryAfter: &retryAfter},
+			expectedCode: CodeSecondaryRateLimited,
+			expectedHint: "A GitHub API secondary rate limit was hit. Slow down and avoid concurrent requests, and wait 30s before retrying.",
+		},
+		{
+			name:         "rate limit budget exhausted by the server",
+			err:          &url.Error{Op: "Get", URL: "https://api.github.com/user", Err: &ratelimit.ExhaustedError{Budget: ratelimit.Budget{Resource: "core", Reset: reset}}},
+			expectedCode: CodeRateLimited,
+		},
+		{
+			name: "missing scopes",
+			err: errorResponse(http.StatusNotFound, "Not Found", map[string]string{
+				"X-OAuth-Scopes":          "repo, gist",
+				"X-Accepted-OAuth-Scopes": "read:org, admin:org",
+			}),
+			expectedCode: CodeInsufficientScopes,
+			expectedHint: "The token lacks the OAuth scopes the request needs. Grant it one of: read:org, admin:org.",
+		},
+		{
+			name: "implied scope",
+			err: errorResponse(http.StatusNotFound, "Not Found", map[string]string{
+				"X-OAuth-Scopes":          "admin:org",
+				"X-Accepted-OAuth-Scopes": "read:org",
+			}),
+			expectedCode: CodeNotFound,
+		},
+		{
+			name: "missing fine-grained permissions",
+			err: errorResponse(http.StatusForbidden, "Resource not accessible by personal access token", map[string]string{
+				"X-Accepted-GitHub-Permissions": "issues=write",
+			}),
+			expectedCode: CodeInsufficientPermissions,
+			expectedHint: "The token or GitHub App lacks a permission the request needs (accepted: issues=write). Grant it and retry.",
+		},
+		{
+			name:         "renamed repository",
+			err:          &github.RedirectionError{Response: &http.Response{StatusCode: http.StatusMovedPermanently}, StatusCode: http.StatusMovedPermanently, Location: location},
+			expectedCode: CodeMoved,
+			expectedHint: "The resource moved to https://api.github.com/repositories/42, e.g. because the repository was renamed or transferred. Retry with its new location.",
+		},
+		{
+			name:         "suspended app",
+			err:          errorResponse(http.StatusForbidden, "This installation has been suspended", nil),
+			expectedCode: CodeAppSuspended,
+		},
+		{
+			name:         "bad credentials",
+			err:          errorResponse(http.StatusUnauthorized, "Bad credentials", nil),
+			expectedCode: CodeBadCredentials,
+		},
+		{
+			name:         "validation failed",
+			err:          errorResponse(http.StatusUnprocessableEntity, "Validation Failed", nil),
+			expectedCode: CodeValidationFailed,
+		},
+		{
+			name:         "server error",
+			err:          errorResponse(http.StatusBadGateway, "Server Error", nil),
+			expectedCode: CodeServerError,
+		},
+		{
+			name:         "no response",
+			err:          fmt.Errorf("connection refused"),
+			expectedCode: CodeUnknown,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			classification := ClassifyAPIError(nil, tc.err)
+			assert.Equal(t, tc.expectedCode, classification.Code)
+			if tc.expectedHint != "" {
+				assert.Equal(t, tc.expectedHint, classification.Hint)
+			}
+		})
+	}
+}
+
+func TestClassifyGraphQLError(t *testing.T) {
+	tests := []struct {
+		name         string
+		message      string
+		expectedCode Code
+		expectedHint string
+	}{
+		{
+			name:         "not found",
+			message:      "Could not resolve to a Repository with the name 'octo-org/missing'.",
+			expectedCode: CodeNotFound,
+		},
+		{
+			name:         "missing scopes",
+			message:      "Your token has not been granted the required scopes to execute this query. The 'login' field requires one of the following scopes: ['read:org'], but your token has only been granted the: ['repo'] scopes.",
+			expectedCode: CodeInsufficientScopes,
+			expectedHint: "The token lacks the OAuth scopes the request needs. Grant it one of: read:org.",
+		},
+		{
+			name:         "SAML SSO enforcement",
+			message:      "Resource protected by organization SAML enforcement. You must grant your Personal Access token access to this organization.",
+			expectedCode: CodeSSORequired,
+		},
+		{
+			name:         "rate limit",
+			message:      "API rate limit exceeded for user ID 1.",
+			expectedCode: CodeRateLimited,
+		},
+		{
+			name:         "missing permissions",
+			message:      "Resource not accessible by integration",
+			expectedCode: CodeInsufficientPermissions,
+		},
+		{
+			name:         "other error",
+			message:      "Argument 'title' on InputObject 'CreateIssueInput' is required.",
+			expectedCode: CodeUnknown,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			classification := ClassifyGraphQLError(fmt.Errorf("%s", tc.message))
+			assert.Equal(t, tc.expectedCode, classification.Code)
+			if tc.expectedHint != "" {
+				assert.Equal(t, tc.expectedHint, classification.Hint)
+			}
+		})
+	}
+}
+
+func TestErrorResponseIncludesCodeAndHint(t *testing.T) {
+	err := errorResponse(http.StatusNotFound, "Not Found", nil)
+	result := NewGitHubAPIErrorResponse(context.Background(), "failed to get issue", &github.Response{Response: err.Response}, err)
+
+	require.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "failed to get issue: ")
+	assert.Contains(t, text, "\n\nError code: not_found\nHint: Check the owner, repository and number or name for typos.")
+
+	// Unclassified errors have a code too, but no hint
+	result = NewGitHubGraphQLErrorResponse(context.Background(), "failed to create issue", fmt.Errorf("something went wrong"))
+	assert.Equal(t, "failed to create issue: something went wrong\n\nError code: unknown", result.Content[0].(*mcp.TextContent).Text)
+}
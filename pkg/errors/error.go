@@ -10,17 +10,19 @@ import (
 )
 
 type GitHubAPIError struct {
-	Message  string           `json:"message"`
-	Response *github.Response `json:"-"`
-	Err      error            `json:"-"`
+	Message        string           `json:"message"`
+	Response       *github.Response `json:"-"`
+	Err            error            `json:"-"`
+	Classification Classification   `json:"-"`
 }
 
 // NewGitHubAPIError creates a new GitHubAPIError with the provided message, response, and error.
 func newGitHubAPIError(message string, resp *github.Response, err error) *GitHubAPIError {
 	return &GitHubAPIError{
-		Message:  message,
-		Response: resp,
-		Err:      err,
+		Message:        message,
+		Response:       resp,
+		Err:            err,
+		Classification: ClassifyAPIError(resp, err),
 	}
 }
 
@@ -29,14 +31,16 @@ func (e *GitHubAPIError) Error() string {
 }
 
 type GitHubGraphQLError struct {
-	Message string `json:"message"`
-	Err     error  `json:"-"`
+	Message        string         `json:"message"`
+	Err            error          `json:"-"`
+	Classification Classification `json:"-"`
 }
 
 func newGitHubGraphQLError(message string, err error) *GitHubGraphQLError {
 	return &GitHubGraphQLError{
-		Message: message,
-		Err:     err,
+		Message:        message,
+		Err:            err,
+		Classification: ClassifyGraphQLError(err),
 	}
 }
 
@@ -107,20 +111,32 @@ func addGitHubGraphQLErrorToContext(ctx context.Context, err *GitHubGraphQLError
 	return nil, fmt.Errorf("context does not contain GitHubCtxErrors")
 }
 
-// NewGitHubAPIErrorResponse returns an mcp.NewToolResultError and retains the error in the context for access via middleware
+// NewGitHubAPIErrorResponse returns an mcp.NewToolResultError and retains the error in the context for access via middleware.
+// The result tells the error code and, if there is one, a hint on how to resolve the error.
 func NewGitHubAPIErrorResponse(ctx context.Context, message string, resp *github.Response, err error) *mcp.CallToolResult {
 	apiErr := newGitHubAPIError(message, resp, err)
 	if ctx != nil {
 		_, _ = addGitHubAPIErrorToContext(ctx, apiErr) // Explicitly ignore error for graceful handling
 	}
-	return utils.NewToolResultErrorFromErr(message, err)
+	return newErrorResult(message, err, apiErr.Classification)
 }
 
-// NewGitHubGraphQLErrorResponse returns an mcp.NewToolResultError and retains the error in the context for access via middleware
+// NewGitHubGraphQLErrorResponse returns an mcp.NewToolResultError and retains the error in the context for access via middleware.
+// The result tells the error code and, if there is one, a hint on how to resolve the error.
 func NewGitHubGraphQLErrorResponse(ctx context.Context, message string, err error) *mcp.CallToolResult {
 	graphQLErr := newGitHubGraphQLError(message, err)
 	if ctx != nil {
 		_, _ = addGitHubGraphQLErrorToContext(ctx, graphQLErr) // Explicitly ignore error for graceful handling
 	}
-	return utils.NewToolResultErrorFromErr(message, err)
+	return newErrorResult(message, err, graphQLErr.Classification)
+}
+
+// newErrorResult reports the error with its code, which is unknown for errors that fit no other code,
+// and the hint of its classification, if there is one.
+func newErrorResult(message string, err error, classification Classification) *mcp.CallToolResult {
+	text := fmt.Sprintf("%s: %s\n\nError code: %s", message, err.Error(), classification.Code)
+	if classification.Hint != "" {
+		text += "\nHint: " + classification.Hint
+	}
+	return utils.NewToolResultError(text)
 }
@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetaKey is the key of the GitHub errors in the _meta of a tool result.
+const MetaKey = "github.com/errors"
+
+// ErrorInfo describes an error returned by the GitHub API while handling a tool call.
+type ErrorInfo struct {
+	// API is "rest" or "graphql"
+	API string `json:"api"`
+	// Message describes what the tool was doing
+	Message string `json:"message"`
+	// Error is the error returned by the API client
+	Error string `json:"error,omitempty"`
+	// Code classifies the error, see Code
+	Code Code `json:"code"`
+	// Hint tells how to resolve the error, if there is advice
+	Hint string `json:"hint,omitempty"`
+	// StatusCode is the HTTP status code of the response, if there was one
+	StatusCode int `json:"status_code,omitempty"`
+	// RequestID is the X-GitHub-Request-Id of the response, to quote when contacting GitHub support
+	RequestID string `json:"request_id,omitempty"`
+	// RateLimit is the rate limit state reported by the response
+	RateLimit *RateLimitInfo `json:"rate_limit,omitempty"`
+}
+
+// RateLimitInfo is the rate limit state reported by a GitHub API response.
+type RateLimitInfo struct {
+	Resource  string    `json:"resource,omitempty"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// Info describes the API error.
+func (e *GitHubAPIError) Info() ErrorInfo {
+	info := ErrorInfo{API: "rest", Message: e.Message, Code: e.Classification.Code, Hint: e.Classification.Hint}
+	if e.Err != nil {
+		info.Error = e.Err.Error()
+	}
+	if e.Response == nil || e.Response.Response == nil {
+		return info
+	}
+
+	info.StatusCode = e.Response.StatusCode
+	info.RequestID = e.Response.Header.Get("X-GitHub-Request-Id")
+	if e.Response.Rate.Limit > 0 {
+		info.RateLimit = &RateLimitInfo{
+			Resource:  e.Response.Header.Get("X-RateLimit-Resource"),
+			Limit:     e.Response.Rate.Limit,
+			Remaining: e.Response.Rate.Remaining,
+			Reset:     e.Response.Rate.Reset.Time,
+		}
+	}
+	return info
+}
+
+// Info describes the GraphQL error.
+func (e *GitHubGraphQLError) Info() ErrorInfo {
+	info := ErrorInfo{API: "graphql", Message: e.Message, Code: e.Classification.Code, Hint: e.Classification.Hint}
+	if e.Err != nil {
+		info.Error = e.Err.Error()
+	}
+	return info
+}
+
+// Middleware returns receiving middleware that collects the GitHub errors of every tool call, see
+// NewGitHubAPIErrorResponse and NewGitHubGraphQLErrorResponse, and logs them once the call completes.
+// If withMeta is set, they are also attached to the _meta of the tool result under MetaKey, so that
+// clients can tell what went wrong without parsing the error text.
+func Middleware(logger *slog.Logger, withMeta bool) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			// Each call gets its own errors, rather than resetting those of the context, which the
+			// calls handled concurrently may share
+			ctx = context.WithValue(ctx, GitHubErrorKey{}, &GitHubCtxErrors{})
+			result, err := next(ctx, method, req)
+
+			infos := collectErrorInfos(ctx)
+			for _, info := range infos {
+				attrs := []any{"tool", call.Params.Name, "api", info.API, "message", info.Message, "error", info.Error, "code", info.Code}
+				if info.StatusCode != 0 {
+					attrs = append(attrs, "status", info.StatusCode)
+				}
+				if info.RequestID != "" {
+					attrs = append(attrs, "request_id", info.RequestID)
+				}
+				if info.RateLimit != nil {
+					attrs = append(attrs,
+						"ratelimit_resource", info.RateLimit.Resource,
+						"ratelimit_remaining", info.RateLimit.Remaining,
+						"ratelimit_limit", info.RateLimit.Limit,
+						"ratelimit_reset", info.RateLimit.Reset)
+				}
+				logger.WarnContext(ctx, "GitHub API error", attrs...)
+			}
+
+			if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult != nil && withMeta && len(infos) > 0 {
+				if toolResult.Meta == nil {
+					toolResult.Meta = mcp.Meta{}
+				}
+				toolResult.Meta[MetaKey] = infos
+			}
+			return result, err
+		}
+	}
+}
+
+func collectErrorInfos(ctx context.Context) []ErrorInfo {
+	var infos []ErrorInfo
+	apiErrors, _ := GetGitHubAPIErrors(ctx)
+	for _, apiErr := range apiErrors {
+		infos = append(infos, apiErr.Info())
+	}
+	graphQLErrors, _ := GetGitHubGraphQLErrors(ctx)
+	for _, graphQLErr := range graphQLErrors {
+		infos = append(infos, graphQLErr.Info())
+	}
+	return infos
+}
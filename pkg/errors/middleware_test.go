@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v79/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	reset := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	resp := &github.Response{
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header: http.Header{
+				"X-Github-Request-Id":   []string{"ABCD:1234"},
+				"X-Ratelimit-Resource":  []string{"core"},
+				"X-Ratelimit-Remaining": []string{"0"},
+				"X-Ratelimit-Reset":     []string{strconv.FormatInt(reset.Unix(), 10)},
+			},
+		},
+		Rate: github.Rate{Limit: 5000, Remaining: 0, Reset: github.Timestamp{Time: reset}},
+	}
+
+	// A tool failing with both a REST and a GraphQL error
+	handler := func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		_ = NewGitHubGraphQLErrorResponse(ctx, "failed to get discussion", fmt.Errorf("not found"))
+		return NewGitHubAPIErrorResponse(ctx, "failed to get issue", resp, fmt.Errorf("rate limit exceeded")), nil
+	}
+	request := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "get_issue"}}
+
+	t.Run("errors are logged", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+		result, err := Middleware(logger, false)(handler)(context.Background(), "tools/call", request)
+		require.NoError(t, err)
+		assert.Nil(t, result.(*mcp.CallToolResult).Meta)
+
+		output := buf.String()
+		assert.Contains(t, output, `msg="GitHub API error" tool=get_issue api=rest message="failed to get issue" error="rate limit exceeded" code=rate_limited status=403 request_id=ABCD:1234 ratelimit_resource=core ratelimit_remaining=0 ratelimit_limit=5000`)
+		assert.Contains(t, output, `api=graphql message="failed to get discussion" error="not found" code=unknown`)
+	})
+
+	t.Run("errors are attached to the result", func(t *testing.T) {
+		logger := slog.New(slog.DiscardHandler)
+
+		result, err := Middleware(logger, true)(handler)(context.Background(), "tools/call", request)
+		require.NoError(t, err)
+
+		toolResult := result.(*mcp.CallToolResult)
+		assert.True(t, toolResult.IsError)
+		assert.Equal(t, []ErrorInfo{
+			{
+				API:        "rest",
+				Message:    "failed to get issue",
+				Error:      "rate limit exceeded",
+				Code:       CodeRateLimited,
+				Hint:       "The GitHub API rate limit is exhausted. Wait until it resets before retrying at 2025-01-02T15:04:05Z, and prefer fewer, more specific requests.",
+				StatusCode: http.StatusForbidden,
+				RequestID:  "ABCD:1234",
+				RateLimit:  &RateLimitInfo{Resource: "core", Limit: 5000, Remaining: 0, Reset: reset},
+			},
+			{
+				API:     "graphql",
+				Message: "failed to get discussion",
+				Error:   "not found",
+				Code:    CodeUnknown,
+			},
+		}, toolResult.Meta[MetaKey])
+	})
+
+	t.Run("successful calls are left alone", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		succeed := func(context.Context, string, mcp.Request) (mcp.Result, error) {
+			return &mcp.CallToolResult{}, nil
+		}
+
+		result, err := Middleware(logger, true)(succeed)(context.Background(), "tools/call", request)
+		require.NoError(t, err)
+		assert.Nil(t, result.(*mcp.CallToolResult).Meta)
+		assert.Empty(t, buf.String())
+	})
+	t.Run("concurrent calls keep their errors apart", func(t *testing.T) {
+		logger := slog.New(slog.DiscardHandler)
+		// Like the stdio server, all calls share a context seeded with errors
+		ctx := ContextWithGitHubErrors(context.Background())
+
+		// Both calls fail before either completes
+		var failed sync.WaitGroup
+		failed.Add(2)
+		fail := func(ctx context.Context, _ string, req mcp.Request) (mcp.Result, error) {
+			name := req.(*mcp.CallToolRequest).Params.Name
+			result := NewGitHubGraphQLErrorResponse(ctx, "failed to call "+name, fmt.Errorf("not found"))
+			failed.Done()
+			failed.Wait()
+			return result, nil
+		}
+
+		names := []string{"get_issue", "get_discussion"}
+		results := make([]mcp.Result, len(names))
+		var calls sync.WaitGroup
+		for i, name := range names {
+			calls.Add(1)
+			go func() {
+				defer calls.Done()
+				results[i], _ = Middleware(logger, true)(fail)(ctx, "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name}})
+			}()
+		}
+		calls.Wait()
+
+		for i, name := range names {
+			assert.Equal(t, []ErrorInfo{
+				{API: "graphql", Message: "failed to call " + name, Error: "not found", Code: CodeUnknown},
+			}, results[i].(*mcp.CallToolResult).Meta[MetaKey])
+		}
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/github/github-mcp-server/internal/profiler"
 	buffer "github.com/github/github-mcp-server/pkg/buffer"
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
@@ -270,6 +271,7 @@ func RunWorkflow(getClient GetClientFn, t translations.TranslationHelperFunc) (m
 						Type:        "object",
 						Description: "Inputs the workflow accepts",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner", "repo", "workflow_id", "ref"},
 			},
@@ -310,6 +312,13 @@ func RunWorkflow(getClient GetClientFn, t translations.TranslationHelperFunc) (m
 				Inputs: inputs,
 			}
 
+			// In a dry run, the request is planned rather than sent
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+				client = plan.client
+			}
+
 			var resp *github.Response
 			var workflowType string
 
@@ -321,6 +330,10 @@ func RunWorkflow(getClient GetClientFn, t translations.TranslationHelperFunc) (m
 				workflowType = "workflow_file"
 			}
 
+			if plan != nil {
+				plan.check(err)
+				return plan.result()
+			}
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to run workflow: %w", err)
 			}
@@ -860,6 +873,7 @@ func RerunWorkflowRun(getClient GetClientFn, t translations.TranslationHelperFun
 						Type:        "number",
 						Description: "The unique identifier of the workflow run",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner", "repo", "run_id"},
 			},
@@ -884,6 +898,13 @@ func RerunWorkflowRun(getClient GetClientFn, t translations.TranslationHelperFun
 				return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(client)
+				_, err := plan.client.Actions.RerunWorkflowByID(ctx, owner, repo, runID)
+				plan.check(err)
+				return plan.result()
+			}
+
 			resp, err := client.Actions.RerunWorkflowByID(ctx, owner, repo, runID)
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to rerun workflow run", resp, err), nil, nil
@@ -930,6 +951,7 @@ func RerunFailedJobs(getClient GetClientFn, t translations.TranslationHelperFunc
 						Type:        "number",
 						Description: "The unique identifier of the workflow run",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner", "repo", "run_id"},
 			},
@@ -954,6 +976,13 @@ func RerunFailedJobs(getClient GetClientFn, t translations.TranslationHelperFunc
 				return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(client)
+				_, err := plan.client.Actions.RerunFailedJobsByID(ctx, owner, repo, runID)
+				plan.check(err)
+				return plan.result()
+			}
+
 			resp, err := client.Actions.RerunFailedJobsByID(ctx, owner, repo, runID)
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to rerun failed jobs", resp, err), nil, nil
@@ -1000,6 +1029,7 @@ func CancelWorkflowRun(getClient GetClientFn, t translations.TranslationHelperFu
 						Type:        "number",
 						Description: "The unique identifier of the workflow run",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner", "repo", "run_id"},
 			},
@@ -1024,6 +1054,13 @@ func CancelWorkflowRun(getClient GetClientFn, t translations.TranslationHelperFu
 				return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(client)
+				_, err := plan.client.Actions.CancelWorkflowRunByID(ctx, owner, repo, runID)
+				plan.check(err)
+				return plan.result()
+			}
+
 			resp, err := client.Actions.CancelWorkflowRunByID(ctx, owner, repo, runID)
 			if err != nil {
 				if _, ok := err.(*github.AcceptedError); !ok {
@@ -1220,6 +1257,7 @@ func DeleteWorkflowRunLogs(getClient GetClientFn, t translations.TranslationHelp
 						Type:        "number",
 						Description: "The unique identifier of the workflow run",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner", "repo", "run_id"},
 			},
@@ -1244,6 +1282,13 @@ func DeleteWorkflowRunLogs(getClient GetClientFn, t translations.TranslationHelp
 				return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(client)
+				_, err := plan.client.Actions.DeleteWorkflowRunLogs(ctx, owner, repo, runID)
+				plan.check(err)
+				return plan.result()
+			}
+
 			resp, err := client.Actions.DeleteWorkflowRunLogs(ctx, owner, repo, runID)
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to delete workflow run logs", resp, err), nil, nil
@@ -0,0 +1,80 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/github/github-mcp-server/pkg/raw"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// CatalogTool describes a tool the server can provide, for reviewing and diffing the tool surface.
+type CatalogTool struct {
+	Name        string `json:"name"`
+	Toolset     string `json:"toolset"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// ReadOnly is the read-only annotation of the tool
+	ReadOnly bool `json:"read_only"`
+	// Default tells whether the tool is enabled when no toolsets or tools are configured
+	Default     bool `json:"default"`
+	InputSchema any  `json:"input_schema"`
+}
+
+// ToolCatalog returns every tool the server can provide, including the dynamic toolset discovery tools,
+// sorted by name. A tool in several toolsets is listed once for each. Titles and descriptions are translated
+// with t. No client is created, so no token is needed.
+func ToolCatalog(t translations.TranslationHelperFunc) []CatalogTool {
+	errNoClient := errors.New("the tool catalog does not call tools")
+	tsg := DefaultToolsetGroup(
+		false,
+		func(context.Context) (*github.Client, error) { return nil, errNoClient },
+		func(context.Context) (*githubv4.Client, error) { return nil, errNoClient },
+		func(context.Context) (*raw.Client, error) { return nil, errNoClient },
+		t,
+		5000,
+		FeatureFlags{},
+		nil,
+	)
+	// The dynamic toolset is kept out of the group, as the server does, so that it doesn't offer itself
+	dynamic := InitDynamicToolset(nil, tsg, t)
+
+	defaults := map[string]bool{}
+	for _, id := range GetDefaultToolsetIDs() {
+		defaults[id] = true
+	}
+
+	var catalog []CatalogTool
+	add := func(toolset string, tools []toolsets.ServerTool) {
+		for _, tool := range tools {
+			entry := CatalogTool{
+				Name:        tool.Tool.Name,
+				Toolset:     toolset,
+				Description: tool.Tool.Description,
+				Default:     defaults[toolset],
+				InputSchema: tool.Tool.InputSchema,
+			}
+			if tool.Tool.Annotations != nil {
+				entry.Title = tool.Tool.Annotations.Title
+				entry.ReadOnly = tool.Tool.Annotations.ReadOnlyHint
+			}
+			catalog = append(catalog, entry)
+		}
+	}
+	for name, toolset := range tsg.Toolsets {
+		add(name, toolset.GetAvailableTools())
+	}
+	add(dynamic.Name, dynamic.GetAvailableTools())
+
+	sort.Slice(catalog, func(i, j int) bool {
+		if catalog[i].Name != catalog[j].Name {
+			return catalog[i].Name < catalog[j].Name
+		}
+		return catalog[i].Toolset < catalog[j].Toolset
+	})
+	return catalog
+}
@@ -0,0 +1,75 @@
+package github
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ToolCatalog(t *testing.T) {
+	catalog := ToolCatalog(translations.NullTranslationHelper)
+
+	byName := map[string]CatalogTool{}
+	for i, tool := range catalog {
+		if i > 0 {
+			previous := catalog[i-1]
+			assert.Less(t, previous.Name+" "+previous.Toolset, tool.Name+" "+tool.Toolset, "catalog must be sorted without duplicates")
+		}
+		assert.NotEmpty(t, tool.Toolset, tool.Name)
+		assert.NotEmpty(t, tool.Description, tool.Name)
+		assert.NotNil(t, tool.InputSchema, tool.Name)
+		byName[tool.Name] = tool
+	}
+
+	getMe := byName["get_me"]
+	assert.Equal(t, "context", getMe.Toolset)
+	assert.True(t, getMe.ReadOnly)
+	assert.True(t, getMe.Default)
+
+	createGist := byName["create_gist"]
+	assert.Equal(t, "gists", createGist.Toolset)
+	assert.False(t, createGist.ReadOnly)
+	assert.False(t, createGist.Default)
+
+	// A tool in several toolsets is listed for each
+	var getLabelToolsets []string
+	for _, tool := range catalog {
+		if tool.Name == "get_label" {
+			getLabelToolsets = append(getLabelToolsets, tool.Toolset)
+		}
+	}
+	assert.Equal(t, []string{"issues", "labels"}, getLabelToolsets)
+
+	enableToolset := byName["enable_toolset"]
+	assert.Equal(t, "dynamic", enableToolset.Toolset)
+	assert.False(t, enableToolset.Default)
+}
+
+func Test_ToolCatalogTranslatesDescriptions(t *testing.T) {
+	translate := func(key, defaultValue string) string {
+		if key == "TOOL_GET_ME_DESCRIPTION" {
+			return "Overridden description"
+		}
+		return defaultValue
+	}
+
+	for _, tool := range ToolCatalog(translate) {
+		if tool.Name == "get_me" {
+			assert.Equal(t, "Overridden description", tool.Description)
+			return
+		}
+	}
+	t.Fatal("get_me not in the catalog")
+}
+
+func Test_ToolCatalogIsStable(t *testing.T) {
+	first, err := json.Marshal(ToolCatalog(translations.NullTranslationHelper))
+	require.NoError(t, err)
+	second, err := json.Marshal(ToolCatalog(translations.NullTranslationHelper))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(first), string(second))
+	assert.Equal(t, string(first), string(second))
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/github/github-mcp-server/pkg/dryrun"
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/google/go-github/v79/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/shurcooL/githubv4"
+)
+
+// dryRunPlan plans the operations of a write tool called as a dry run. Its clients build requests like
+// the ones the tool sends but record them instead of sending them, so the plan shows exactly what the
+// tool would send. Their calls fail with dryrun.ErrNotSent, which check expects.
+type dryRunPlan struct {
+	recorder  *dryrun.Recorder
+	client    *github.Client
+	gqlClient *githubv4.Client
+	err       error
+}
+
+// newDryRunPlan creates a plan whose REST client uses the URLs of client, if it isn't nil.
+func newDryRunPlan(client *github.Client) *dryRunPlan {
+	recorder := &dryrun.Recorder{}
+	httpClient := &http.Client{Transport: recorder}
+	plan := &dryRunPlan{
+		recorder:  recorder,
+		client:    github.NewClient(httpClient),
+		gqlClient: githubv4.NewClient(httpClient),
+	}
+	if client != nil {
+		plan.client.BaseURL = client.BaseURL
+		plan.client.UploadURL = client.UploadURL
+	}
+	return plan
+}
+
+// check keeps the first error of a planned call other than dryrun.ErrNotSent, such as an invalid
+// request that go-github refuses to build.
+func (p *dryRunPlan) check(err error) {
+	if err != nil && !errors.Is(err, dryrun.ErrNotSent) && p.err == nil {
+		p.err = err
+	}
+}
+
+// planned reports whether err is that of a call the plan recorded rather than sent. The plan may be nil,
+// so that a tool can share the code of a GraphQL mutation between dry runs and actual calls.
+func (p *dryRunPlan) planned(err error) bool {
+	return p != nil && errors.Is(err, dryrun.ErrNotSent)
+}
+
+// reference stands in for a field of the result of the last planned operation.
+func (p *dryRunPlan) reference(field string) string {
+	return dryrun.Reference(len(p.recorder.Operations()), field)
+}
+
+// toolResult returns the planned operations, or the error of a call that couldn't be planned.
+func (p *dryRunPlan) toolResult() (*mcp.CallToolResult, error) {
+	if p.err != nil {
+		return utils.NewToolResultErrorFromErr("failed to plan dry run", p.err), nil
+	}
+	return p.recorder.ToolResult()
+}
+
+// result is toolResult for tool handlers to return.
+func (p *dryRunPlan) result() (*mcp.CallToolResult, any, error) {
+	result, err := p.toolResult()
+	return result, nil, err
+}
@@ -0,0 +1,103 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/github/github-mcp-server/internal/githubv4mock"
+	"github.com/github/github-mcp-server/pkg/dryrun"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteToolsSupportDryRuns(t *testing.T) {
+	tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), nil, translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+	for _, toolset := range tsg.Toolsets {
+		for _, tool := range toolset.GetAvailableTools() {
+			if !tool.Tool.Annotations.ReadOnlyHint {
+				assert.True(t, dryrun.Supported(&tool.Tool), "%s should plan its operations in dry runs", tool.Tool.Name)
+			}
+		}
+	}
+}
+
+func Test_CreateGist_DryRun(t *testing.T) {
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.PostGists,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				t.Error("the gist must not be created in a dry run")
+				w.WriteHeader(http.StatusCreated)
+			}),
+		),
+	)
+	client := github.NewClient(mockedClient)
+	_, handler := CreateGist(stubGetClientFn(client), translations.NullTranslationHelper)
+
+	args := map[string]any{
+		"filename": "hello.go",
+		"content":  "package main",
+		"dry_run":  true,
+	}
+	request := createMCPRequest(args)
+	result, _, err := handler(context.Background(), &request, args)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getTextResult(t, result).Text)
+
+	var dryRun dryrun.Result
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &dryRun))
+	assert.True(t, dryRun.DryRun)
+	require.Len(t, dryRun.Operations, 1)
+	assert.Equal(t, "POST", dryRun.Operations[0].Method)
+	assert.Equal(t, client.BaseURL.String()+"gists", dryRun.Operations[0].URL)
+	assert.JSONEq(t, `{"description":"","files":{"hello.go":{"filename":"hello.go","content":"package main"}},"public":false}`, string(dryRun.Operations[0].Body))
+}
+
+func Test_LabelWrite_DryRun(t *testing.T) {
+	// Only the query resolving the repository is answered, the mutation must not be sent
+	mockedClient := githubv4mock.NewMockedHTTPClient(
+		githubv4mock.NewQueryMatcher(
+			struct {
+				Repository struct {
+					ID githubv4.ID
+				} `graphql:"repository(owner: $owner, name: $repo)"`
+			}{},
+			map[string]any{
+				"owner": githubv4.String("owner"),
+				"repo":  githubv4.String("repo"),
+			},
+			githubv4mock.DataResponse(map[string]any{
+				"repository": map[string]any{
+					"id": githubv4.ID("test-repo-id"),
+				},
+			}),
+		),
+	)
+	_, handler := LabelWrite(stubGetGQLClientFn(githubv4.NewClient(mockedClient)), translations.NullTranslationHelper)
+
+	args := map[string]any{
+		"method":  "create",
+		"owner":   "owner",
+		"repo":    "repo",
+		"name":    "new-label",
+		"color":   "f29513",
+		"dry_run": true,
+	}
+	request := createMCPRequest(args)
+	result, _, err := handler(context.Background(), &request, args)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getTextResult(t, result).Text)
+
+	var dryRun dryrun.Result
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &dryRun))
+	require.Len(t, dryRun.Operations, 1)
+	assert.Equal(t, "graphql", dryRun.Operations[0].API)
+	assert.Contains(t, dryRun.Operations[0].Query, "createLabel(input: $input)")
+	assert.JSONEq(t, `{"input":{"repositoryId":"test-repo-id","name":"new-label","color":"f29513"}}`, string(dryRun.Operations[0].Variables))
+}
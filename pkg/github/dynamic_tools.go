@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/github/github-mcp-server/pkg/toolsets"
 	"github.com/github/github-mcp-server/pkg/translations"
@@ -12,9 +13,16 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// ToolsetEnum returns the names of the toolsets in the group, sorted so that the schemas using them are stable.
 func ToolsetEnum(toolsetGroup *toolsets.ToolsetGroup) []any {
-	toolsetNames := make([]any, 0, len(toolsetGroup.Toolsets))
+	names := make([]string, 0, len(toolsetGroup.Toolsets))
 	for name := range toolsetGroup.Toolsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	toolsetNames := make([]any, 0, len(names))
+	for _, name := range names {
 		toolsetNames = append(toolsetNames, name)
 	}
 	return toolsetNames
@@ -61,9 +69,7 @@ func EnableToolset(s *mcp.Server, toolsetGroup *toolsets.ToolsetGroup, t transla
 			//
 			// Send notification to all initialized sessions
 			// s.sendNotificationToAllClients("notifications/tools/list_changed", nil)
-			for _, serverTool := range toolset.GetActiveTools() {
-				serverTool.RegisterFunc(s)
-			}
+			toolsetGroup.RegisterToolsetTools(s, toolset)
 
 			return utils.NewToolResultText(fmt.Sprintf("Toolset %s enabled", toolsetName)), nil, nil
 		})
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
 	"github.com/google/go-github/v79/github"
@@ -186,6 +187,7 @@ func CreateGist(getClient GetClientFn, t translations.TranslationHelperFunc) (mc
 					Description: "Whether the gist is public",
 					Default:     json.RawMessage(`false`),
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"filename", "content"},
 		},
@@ -229,6 +231,13 @@ func CreateGist(getClient GetClientFn, t translations.TranslationHelperFunc) (mc
 			return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 		}
 
+		if dryrun.Requested(ctx, args) {
+			plan := newDryRunPlan(client)
+			_, _, err := plan.client.Gists.Create(ctx, gist)
+			plan.check(err)
+			return plan.result()
+		}
+
 		createdGist, resp, err := client.Gists.Create(ctx, gist)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to create gist: %w", err)
@@ -287,6 +296,7 @@ func UpdateGist(getClient GetClientFn, t translations.TranslationHelperFunc) (mc
 					Type:        "string",
 					Description: "Content for the file",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"gist_id", "filename", "content"},
 		},
@@ -329,6 +339,13 @@ func UpdateGist(getClient GetClientFn, t translations.TranslationHelperFunc) (mc
 			return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 		}
 
+		if dryrun.Requested(ctx, args) {
+			plan := newDryRunPlan(client)
+			_, _, err := plan.client.Gists.Edit(ctx, gistID, gist)
+			plan.check(err)
+			return plan.result()
+		}
+
 		updatedGist, resp, err := client.Gists.Edit(ctx, gistID, gist)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to update gist: %w", err)
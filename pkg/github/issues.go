@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/lockdown"
 	"github.com/github/github-mcp-server/pkg/sanitize"
@@ -620,6 +621,7 @@ func AddIssueComment(getClient GetClientFn, t translations.TranslationHelperFunc
 						Type:        "string",
 						Description: "Comment content",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner", "repo", "issue_number", "body"},
 			},
@@ -650,6 +652,12 @@ func AddIssueComment(getClient GetClientFn, t translations.TranslationHelperFunc
 			if err != nil {
 				return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
 			}
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(client)
+				_, _, err := plan.client.Issues.CreateComment(ctx, owner, repo, issueNumber, comment)
+				plan.check(err)
+				return plan.result()
+			}
 			createdComment, resp, err := client.Issues.CreateComment(ctx, owner, repo, issueNumber, comment)
 			if err != nil {
 				return utils.NewToolResultErrorFromErr("failed to create comment", err), nil, nil
@@ -722,6 +730,7 @@ Options are:
 						Type:        "number",
 						Description: "The ID of the sub-issue to be prioritized before (either after_id OR before_id should be specified)",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"method", "owner", "repo", "issue_number", "sub_issue_id"},
 			},
@@ -766,17 +775,22 @@ Options are:
 				return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
 			}
 
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+			}
+
 			switch strings.ToLower(method) {
 			case "add":
-				result, err := AddSubIssue(ctx, client, owner, repo, issueNumber, subIssueID, replaceParent)
+				result, err := AddSubIssue(ctx, client, plan, owner, repo, issueNumber, subIssueID, replaceParent)
 				return result, nil, err
 			case "remove":
 				// Call the remove sub-issue function
-				result, err := RemoveSubIssue(ctx, client, owner, repo, issueNumber, subIssueID)
+				result, err := RemoveSubIssue(ctx, client, plan, owner, repo, issueNumber, subIssueID)
 				return result, nil, err
 			case "reprioritize":
 				// Call the reprioritize sub-issue function
-				result, err := ReprioritizeSubIssue(ctx, client, owner, repo, issueNumber, subIssueID, afterID, beforeID)
+				result, err := ReprioritizeSubIssue(ctx, client, plan, owner, repo, issueNumber, subIssueID, afterID, beforeID)
 				return result, nil, err
 			default:
 				return utils.NewToolResultError(fmt.Sprintf("unknown method: %s", method)), nil, nil
@@ -784,12 +798,18 @@ Options are:
 		}
 }
 
-func AddSubIssue(ctx context.Context, client *github.Client, owner string, repo string, issueNumber int, subIssueID int, replaceParent bool) (*mcp.CallToolResult, error) {
+func AddSubIssue(ctx context.Context, client *github.Client, plan *dryRunPlan, owner string, repo string, issueNumber int, subIssueID int, replaceParent bool) (*mcp.CallToolResult, error) {
 	subIssueRequest := github.SubIssueRequest{
 		SubIssueID:    int64(subIssueID),
 		ReplaceParent: github.Ptr(replaceParent),
 	}
 
+	if plan != nil {
+		_, _, err := plan.client.SubIssue.Add(ctx, owner, repo, int64(issueNumber), subIssueRequest)
+		plan.check(err)
+		return plan.toolResult()
+	}
+
 	subIssue, resp, err := client.SubIssue.Add(ctx, owner, repo, int64(issueNumber), subIssueRequest)
 	if err != nil {
 		return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -818,11 +838,17 @@ func AddSubIssue(ctx context.Context, client *github.Client, owner string, repo
 
 }
 
-func RemoveSubIssue(ctx context.Context, client *github.Client, owner string, repo string, issueNumber int, subIssueID int) (*mcp.CallToolResult, error) {
+func RemoveSubIssue(ctx context.Context, client *github.Client, plan *dryRunPlan, owner string, repo string, issueNumber int, subIssueID int) (*mcp.CallToolResult, error) {
 	subIssueRequest := github.SubIssueRequest{
 		SubIssueID: int64(subIssueID),
 	}
 
+	if plan != nil {
+		_, _, err := plan.client.SubIssue.Remove(ctx, owner, repo, int64(issueNumber), subIssueRequest)
+		plan.check(err)
+		return plan.toolResult()
+	}
+
 	subIssue, resp, err := client.SubIssue.Remove(ctx, owner, repo, int64(issueNumber), subIssueRequest)
 	if err != nil {
 		return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -849,7 +875,7 @@ func RemoveSubIssue(ctx context.Context, client *github.Client, owner string, re
 	return utils.NewToolResultText(string(r)), nil
 }
 
-func ReprioritizeSubIssue(ctx context.Context, client *github.Client, owner string, repo string, issueNumber int, subIssueID int, afterID int, beforeID int) (*mcp.CallToolResult, error) {
+func ReprioritizeSubIssue(ctx context.Context, client *github.Client, plan *dryRunPlan, owner string, repo string, issueNumber int, subIssueID int, afterID int, beforeID int) (*mcp.CallToolResult, error) {
 	// Validate that either after_id or before_id is specified, but not both
 	if afterID == 0 && beforeID == 0 {
 		return utils.NewToolResultError("either after_id or before_id must be specified"), nil
@@ -871,6 +897,12 @@ func ReprioritizeSubIssue(ctx context.Context, client *github.Client, owner stri
 		subIssueRequest.BeforeID = &beforeIDInt64
 	}
 
+	if plan != nil {
+		_, _, err := plan.client.SubIssue.Reprioritize(ctx, owner, repo, int64(issueNumber), subIssueRequest)
+		plan.check(err)
+		return plan.toolResult()
+	}
+
 	subIssue, resp, err := client.SubIssue.Reprioritize(ctx, owner, repo, int64(issueNumber), subIssueRequest)
 	if err != nil {
 		return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -1034,6 +1066,7 @@ Options are:
 						Type:        "number",
 						Description: "Issue number that this issue is a duplicate of. Only used when state_reason is 'duplicate'.",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"method", "owner", "repo"},
 			},
@@ -1121,16 +1154,21 @@ Options are:
 				return utils.NewToolResultErrorFromErr("failed to get GraphQL client", err), nil, nil
 			}
 
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+			}
+
 			switch method {
 			case "create":
-				result, err := CreateIssue(ctx, client, owner, repo, title, body, assignees, labels, milestoneNum, issueType)
+				result, err := CreateIssue(ctx, client, plan, owner, repo, title, body, assignees, labels, milestoneNum, issueType)
 				return result, nil, err
 			case "update":
 				issueNumber, err := RequiredInt(args, "issue_number")
 				if err != nil {
 					return utils.NewToolResultError(err.Error()), nil, nil
 				}
-				result, err := UpdateIssue(ctx, client, gqlClient, owner, repo, issueNumber, title, body, assignees, labels, milestoneNum, issueType, state, stateReason, duplicateOf)
+				result, err := UpdateIssue(ctx, client, gqlClient, plan, owner, repo, issueNumber, title, body, assignees, labels, milestoneNum, issueType, state, stateReason, duplicateOf)
 				return result, nil, err
 			default:
 				return utils.NewToolResultError("invalid method, must be either 'create' or 'update'"), nil, nil
@@ -1138,7 +1176,7 @@ Options are:
 		}
 }
 
-func CreateIssue(ctx context.Context, client *github.Client, owner string, repo string, title string, body string, assignees []string, labels []string, milestoneNum int, issueType string) (*mcp.CallToolResult, error) {
+func CreateIssue(ctx context.Context, client *github.Client, plan *dryRunPlan, owner string, repo string, title string, body string, assignees []string, labels []string, milestoneNum int, issueType string) (*mcp.CallToolResult, error) {
 	if title == "" {
 		return utils.NewToolResultError("missing required parameter: title"), nil
 	}
@@ -1159,6 +1197,12 @@ func CreateIssue(ctx context.Context, client *github.Client, owner string, repo
 		issueRequest.Type = github.Ptr(issueType)
 	}
 
+	if plan != nil {
+		_, _, err := plan.client.Issues.Create(ctx, owner, repo, issueRequest)
+		plan.check(err)
+		return plan.toolResult()
+	}
+
 	issue, resp, err := client.Issues.Create(ctx, owner, repo, issueRequest)
 	if err != nil {
 		return utils.NewToolResultErrorFromErr("failed to create issue", err), nil
@@ -1187,7 +1231,7 @@ func CreateIssue(ctx context.Context, client *github.Client, owner string, repo
 	return utils.NewToolResultText(string(r)), nil
 }
 
-func UpdateIssue(ctx context.Context, client *github.Client, gqlClient *githubv4.Client, owner string, repo string, issueNumber int, title string, body string, assignees []string, labels []string, milestoneNum int, issueType string, state string, stateReason string, duplicateOf int) (*mcp.CallToolResult, error) {
+func UpdateIssue(ctx context.Context, client *github.Client, gqlClient *githubv4.Client, plan *dryRunPlan, owner string, repo string, issueNumber int, title string, body string, assignees []string, labels []string, milestoneNum int, issueType string, state string, stateReason string, duplicateOf int) (*mcp.CallToolResult, error) {
 	// Create the issue request with only provided fields
 	issueRequest := &github.IssueRequest{}
 
@@ -1216,22 +1260,30 @@ func UpdateIssue(ctx context.Context, client *github.Client, gqlClient *githubv4
 		issueRequest.Type = github.Ptr(issueType)
 	}
 
-	updatedIssue, resp, err := client.Issues.Edit(ctx, owner, repo, issueNumber, issueRequest)
-	if err != nil {
-		return ghErrors.NewGitHubAPIErrorResponse(ctx,
-			"failed to update issue",
-			resp,
-			err,
-		), nil
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
+	// In a dry run, the changes are planned rather than made
+	var updatedIssue *github.Issue
+	if plan != nil {
+		_, _, err := plan.client.Issues.Edit(ctx, owner, repo, issueNumber, issueRequest)
+		plan.check(err)
+	} else {
+		issue, resp, err := client.Issues.Edit(ctx, owner, repo, issueNumber, issueRequest)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
+			return ghErrors.NewGitHubAPIErrorResponse(ctx,
+				"failed to update issue",
+				resp,
+				err,
+			), nil
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", err)
+			}
+			return utils.NewToolResultError(fmt.Sprintf("failed to update issue: %s", string(body))), nil
 		}
-		return utils.NewToolResultError(fmt.Sprintf("failed to update issue: %s", string(body))), nil
+		updatedIssue = issue
 	}
 
 	// Use GraphQL API for state updates
@@ -1246,6 +1298,9 @@ func UpdateIssue(ctx context.Context, client *github.Client, gqlClient *githubv4
 		if err != nil {
 			return ghErrors.NewGitHubGraphQLErrorResponse(ctx, "Failed to find issues", err), nil
 		}
+		if plan != nil {
+			gqlClient = plan.gqlClient
+		}
 
 		switch state {
 		case "open":
@@ -1264,7 +1319,7 @@ func UpdateIssue(ctx context.Context, client *github.Client, gqlClient *githubv4
 			err = gqlClient.Mutate(ctx, &mutation, githubv4.ReopenIssueInput{
 				IssueID: issueID,
 			}, nil)
-			if err != nil {
+			if err != nil && !plan.planned(err) {
 				return ghErrors.NewGitHubGraphQLErrorResponse(ctx, "Failed to reopen issue", err), nil
 			}
 		case "closed":
@@ -1292,12 +1347,16 @@ func UpdateIssue(ctx context.Context, client *github.Client, gqlClient *githubv4
 			}
 
 			err = gqlClient.Mutate(ctx, &mutation, closeInput, nil)
-			if err != nil {
+			if err != nil && !plan.planned(err) {
 				return ghErrors.NewGitHubGraphQLErrorResponse(ctx, "Failed to close issue", err), nil
 			}
 		}
 	}
 
+	if plan != nil {
+		return plan.toolResult()
+	}
+
 	// Return minimal response with just essential information
 	minimalResponse := MinimalResponse{
 		ID:  fmt.Sprintf("%d", updatedIssue.GetID()),
@@ -1600,6 +1659,7 @@ func AssignCopilotToIssue(getGQLClient GetGQLClientFn, t translations.Translatio
 						Type:        "number",
 						Description: "Issue number",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner", "repo", "issueNumber"},
 			},
@@ -1716,15 +1776,17 @@ func AssignCopilotToIssue(getGQLClient GetGQLClientFn, t translations.Translatio
 			}
 			actorIDs[len(getIssueQuery.Repository.Issue.Assignees.Nodes)] = copilotAssignee.ID
 
-			if err := client.Mutate(
-				ctx,
-				&assignCopilotMutation,
-				ReplaceActorsForAssignableInput{
-					AssignableID: getIssueQuery.Repository.Issue.ID,
-					ActorIDs:     actorIDs,
-				},
-				nil,
-			); err != nil {
+			input := ReplaceActorsForAssignableInput{
+				AssignableID: getIssueQuery.Repository.Issue.ID,
+				ActorIDs:     actorIDs,
+			}
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(nil)
+				plan.check(plan.gqlClient.Mutate(ctx, &assignCopilotMutation, input, nil))
+				return plan.result()
+			}
+
+			if err := client.Mutate(ctx, &assignCopilotMutation, input, nil); err != nil {
 				return nil, nil, fmt.Errorf("failed to replace actors for assignable: %w", err)
 			}
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
@@ -238,6 +239,7 @@ func LabelWrite(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 					Type:        "string",
 					Description: "Label description text. Optional for 'create' and 'update'.",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"method", "owner", "repo", "name"},
 		},
@@ -308,6 +310,12 @@ func LabelWrite(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 				} `graphql:"createLabel(input: $input)"`
 			}
 
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(nil)
+				plan.check(plan.gqlClient.Mutate(ctx, &mutation, input, nil))
+				return plan.result()
+			}
+
 			if err := client.Mutate(ctx, &mutation, input, nil); err != nil {
 				return ghErrors.NewGitHubGraphQLErrorResponse(ctx, "Failed to create label", err), nil, nil
 			}
@@ -351,6 +359,12 @@ func LabelWrite(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 				} `graphql:"updateLabel(input: $input)"`
 			}
 
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(nil)
+				plan.check(plan.gqlClient.Mutate(ctx, &mutation, input, nil))
+				return plan.result()
+			}
+
 			if err := client.Mutate(ctx, &mutation, input, nil); err != nil {
 				return ghErrors.NewGitHubGraphQLErrorResponse(ctx, "Failed to update label", err), nil, nil
 			}
@@ -374,6 +388,12 @@ func LabelWrite(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 				} `graphql:"deleteLabel(input: $input)"`
 			}
 
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(nil)
+				plan.check(plan.gqlClient.Mutate(ctx, &mutation, input, nil))
+				return plan.result()
+			}
+
 			if err := client.Mutate(ctx, &mutation, input, nil); err != nil {
 				return ghErrors.NewGitHubGraphQLErrorResponse(ctx, "Failed to delete label", err), nil, nil
 			}
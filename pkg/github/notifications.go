@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
@@ -177,6 +178,7 @@ func DismissNotification(getclient GetClientFn, t translations.TranslationHelper
 						Description: "The new state of the notification (read/done)",
 						Enum:        []any{"read", "done"},
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"threadID", "state"},
 			},
@@ -197,6 +199,13 @@ func DismissNotification(getclient GetClientFn, t translations.TranslationHelper
 				return utils.NewToolResultError(err.Error()), nil, nil
 			}
 
+			// In a dry run, the request is planned rather than sent
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+				client = plan.client
+			}
+
 			var resp *github.Response
 			switch state {
 			case "done":
@@ -213,6 +222,10 @@ func DismissNotification(getclient GetClientFn, t translations.TranslationHelper
 				return utils.NewToolResultError("Invalid state. Must be one of: read, done."), nil, nil
 			}
 
+			if plan != nil {
+				plan.check(err)
+				return plan.result()
+			}
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					fmt.Sprintf("failed to mark notification as %s", state),
@@ -258,6 +271,7 @@ func MarkAllNotificationsRead(getClient GetClientFn, t translations.TranslationH
 						Type:        "string",
 						Description: "Optional repository name. If provided with owner, only notifications for this repository are marked as read.",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 			},
 		},
@@ -295,12 +309,23 @@ func MarkAllNotificationsRead(getClient GetClientFn, t translations.TranslationH
 				Time: lastReadTime,
 			}
 
+			// In a dry run, the request is planned rather than sent
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+				client = plan.client
+			}
+
 			var resp *github.Response
 			if owner != "" && repo != "" {
 				resp, err = client.Activity.MarkRepositoryNotificationsRead(ctx, owner, repo, markReadOptions)
 			} else {
 				resp, err = client.Activity.MarkNotificationsRead(ctx, markReadOptions)
 			}
+			if plan != nil {
+				plan.check(err)
+				return plan.result()
+			}
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					"failed to mark all notifications as read",
@@ -408,6 +433,7 @@ func ManageNotificationSubscription(getClient GetClientFn, t translations.Transl
 						Description: "Action to perform: ignore, watch, or delete the notification subscription.",
 						Enum:        []any{NotificationActionIgnore, NotificationActionWatch, NotificationActionDelete},
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"notificationID", "action"},
 			},
@@ -427,6 +453,13 @@ func ManageNotificationSubscription(getClient GetClientFn, t translations.Transl
 				return utils.NewToolResultError(err.Error()), nil, nil
 			}
 
+			// In a dry run, the request is planned rather than sent
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+				client = plan.client
+			}
+
 			var (
 				resp   *github.Response
 				result any
@@ -446,6 +479,10 @@ func ManageNotificationSubscription(getClient GetClientFn, t translations.Transl
 				return utils.NewToolResultError("Invalid action. Must be one of: ignore, watch, delete."), nil, nil
 			}
 
+			if plan != nil {
+				plan.check(apiErr)
+				return plan.result()
+			}
 			if apiErr != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					fmt.Sprintf("failed to %s notification subscription", action),
@@ -504,6 +541,7 @@ func ManageRepositoryNotificationSubscription(getClient GetClientFn, t translati
 						Description: "Action to perform: ignore, watch, or delete the repository notification subscription.",
 						Enum:        []any{RepositorySubscriptionActionIgnore, RepositorySubscriptionActionWatch, RepositorySubscriptionActionDelete},
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner", "repo", "action"},
 			},
@@ -527,6 +565,13 @@ func ManageRepositoryNotificationSubscription(getClient GetClientFn, t translati
 				return utils.NewToolResultError(err.Error()), nil, nil
 			}
 
+			// In a dry run, the request is planned rather than sent
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+				client = plan.client
+			}
+
 			var (
 				resp   *github.Response
 				result any
@@ -546,6 +591,10 @@ func ManageRepositoryNotificationSubscription(getClient GetClientFn, t translati
 				return utils.NewToolResultError("Invalid action. Must be one of: ignore, watch, delete."), nil, nil
 			}
 
+			if plan != nil {
+				plan.check(apiErr)
+				return plan.result()
+			}
 			if apiErr != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					fmt.Sprintf("failed to %s repository subscription", action),
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
@@ -673,6 +674,7 @@ func AddProjectItem(getClient GetClientFn, t translations.TranslationHelperFunc)
 						Type:        "number",
 						Description: "The numeric ID of the issue or pull request to add to the project.",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner_type", "owner", "project_number", "item_type", "item_id"},
 			},
@@ -712,6 +714,13 @@ func AddProjectItem(getClient GetClientFn, t translations.TranslationHelperFunc)
 				Type: toNewProjectType(itemType),
 			}
 
+			// In a dry run, the request is planned rather than sent
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+				client = plan.client
+			}
+
 			var resp *github.Response
 			var addedItem *github.ProjectV2Item
 
@@ -721,6 +730,10 @@ func AddProjectItem(getClient GetClientFn, t translations.TranslationHelperFunc)
 				addedItem, resp, err = client.Projects.AddUserProjectItem(ctx, owner, projectNumber, newItem)
 			}
 
+			if plan != nil {
+				plan.check(err)
+				return plan.result()
+			}
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					ProjectAddFailedError,
@@ -778,6 +791,7 @@ func UpdateProjectItem(getClient GetClientFn, t translations.TranslationHelperFu
 						Type:        "object",
 						Description: "Object consisting of the ID of the project field to update and the new value for the field. To clear the field, set value to null. Example: {\"id\": 123456, \"value\": \"New Value\"}",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner_type", "owner", "project_number", "item_id", "updated_field"},
 			},
@@ -819,6 +833,13 @@ func UpdateProjectItem(getClient GetClientFn, t translations.TranslationHelperFu
 				return utils.NewToolResultError(err.Error()), nil, nil
 			}
 
+			// In a dry run, the request is planned rather than sent
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+				client = plan.client
+			}
+
 			var resp *github.Response
 			var updatedItem *github.ProjectV2Item
 
@@ -828,6 +849,10 @@ func UpdateProjectItem(getClient GetClientFn, t translations.TranslationHelperFu
 				updatedItem, resp, err = client.Projects.UpdateUserProjectItem(ctx, owner, projectNumber, itemID, updatePayload)
 			}
 
+			if plan != nil {
+				plan.check(err)
+				return plan.result()
+			}
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					ProjectUpdateFailedError,
@@ -881,6 +906,7 @@ func DeleteProjectItem(getClient GetClientFn, t translations.TranslationHelperFu
 						Type:        "number",
 						Description: "The internal project item ID to delete from the project (not the issue or pull request ID).",
 					},
+					dryrun.ArgumentName: dryrun.Parameter(),
 				},
 				Required: []string{"owner_type", "owner", "project_number", "item_id"},
 			},
@@ -906,6 +932,13 @@ func DeleteProjectItem(getClient GetClientFn, t translations.TranslationHelperFu
 				return utils.NewToolResultError(err.Error()), nil, nil
 			}
 
+			// In a dry run, the request is planned rather than sent
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(client)
+				client = plan.client
+			}
+
 			var resp *github.Response
 			if ownerType == "org" {
 				resp, err = client.Projects.DeleteOrganizationProjectItem(ctx, owner, projectNumber, itemID)
@@ -913,6 +946,10 @@ func DeleteProjectItem(getClient GetClientFn, t translations.TranslationHelperFu
 				resp, err = client.Projects.DeleteUserProjectItem(ctx, owner, projectNumber, itemID)
 			}
 
+			if plan != nil {
+				plan.check(err)
+				return plan.result()
+			}
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					ProjectDeleteFailedError,
@@ -13,6 +13,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/shurcooL/githubv4"
 
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/lockdown"
 	"github.com/github/github-mcp-server/pkg/sanitize"
@@ -421,6 +422,7 @@ func CreatePullRequest(getClient GetClientFn, t translations.TranslationHelperFu
 				Type:        "boolean",
 				Description: "Allow maintainer edits",
 			},
+			dryrun.ArgumentName: dryrun.Parameter(),
 		},
 		Required: []string{"owner", "repo", "title", "head", "base"},
 	}
@@ -488,6 +490,12 @@ func CreatePullRequest(getClient GetClientFn, t translations.TranslationHelperFu
 			if err != nil {
 				return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
 			}
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(client)
+				_, _, err := plan.client.PullRequests.Create(ctx, owner, repo, newPR)
+				plan.check(err)
+				return plan.result()
+			}
 			pr, resp, err := client.PullRequests.Create(ctx, owner, repo, newPR)
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -570,6 +578,7 @@ func UpdatePullRequest(getClient GetClientFn, getGQLClient GetGQLClientFn, t tra
 					Type: "string",
 				},
 			},
+			dryrun.ArgumentName: dryrun.Parameter(),
 		},
 		Required: []string{"owner", "repo", "pullNumber"},
 	}
@@ -655,8 +664,21 @@ func UpdatePullRequest(getClient GetClientFn, getGQLClient GetGQLClientFn, t tra
 				return utils.NewToolResultError("No update parameters provided."), nil, nil
 			}
 
+			// In a dry run, the changes are planned rather than made
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				client, err := getClient(ctx)
+				if err != nil {
+					return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
+				}
+				plan = newDryRunPlan(client)
+			}
+
 			// Handle REST API updates (title, body, state, base, maintainer_can_modify)
-			if restUpdateNeeded {
+			if restUpdateNeeded && plan != nil {
+				_, _, err := plan.client.PullRequests.Edit(ctx, owner, repo, pullNumber, update)
+				plan.check(err)
+			} else if restUpdateNeeded {
 				client, err := getClient(ctx)
 				if err != nil {
 					return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
@@ -709,6 +731,9 @@ func UpdatePullRequest(getClient GetClientFn, getGQLClient GetGQLClientFn, t tra
 				currentIsDraft := bool(prQuery.Repository.PullRequest.IsDraft)
 
 				if currentIsDraft != draftValue {
+					if plan != nil {
+						gqlClient = plan.gqlClient
+					}
 					if draftValue {
 						// Convert to draft
 						var mutation struct {
@@ -723,7 +748,7 @@ func UpdatePullRequest(getClient GetClientFn, getGQLClient GetGQLClientFn, t tra
 						err = gqlClient.Mutate(ctx, &mutation, githubv4.ConvertPullRequestToDraftInput{
 							PullRequestID: prQuery.Repository.PullRequest.ID,
 						}, nil)
-						if err != nil {
+						if err != nil && !plan.planned(err) {
 							return ghErrors.NewGitHubGraphQLErrorResponse(ctx, "Failed to convert pull request to draft", err), nil, nil
 						}
 					} else {
@@ -740,7 +765,7 @@ func UpdatePullRequest(getClient GetClientFn, getGQLClient GetGQLClientFn, t tra
 						err = gqlClient.Mutate(ctx, &mutation, githubv4.MarkPullRequestReadyForReviewInput{
 							PullRequestID: prQuery.Repository.PullRequest.ID,
 						}, nil)
-						if err != nil {
+						if err != nil && !plan.planned(err) {
 							return ghErrors.NewGitHubGraphQLErrorResponse(ctx, "Failed to mark pull request ready for review", err), nil, nil
 						}
 					}
@@ -748,7 +773,12 @@ func UpdatePullRequest(getClient GetClientFn, getGQLClient GetGQLClientFn, t tra
 			}
 
 			// Handle reviewer requests
-			if len(reviewers) > 0 {
+			if len(reviewers) > 0 && plan != nil {
+				_, _, err := plan.client.PullRequests.RequestReviewers(ctx, owner, repo, pullNumber, github.ReviewersRequest{
+					Reviewers: reviewers,
+				})
+				plan.check(err)
+			} else if len(reviewers) > 0 {
 				client, err := getClient(ctx)
 				if err != nil {
 					return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
@@ -781,6 +811,10 @@ func UpdatePullRequest(getClient GetClientFn, getGQLClient GetGQLClientFn, t tra
 				}
 			}
 
+			if plan != nil {
+				return plan.result()
+			}
+
 			// Get the final state of the PR to return
 			client, err := getClient(ctx)
 			if err != nil {
@@ -982,6 +1016,7 @@ func MergePullRequest(getClient GetClientFn, t translations.TranslationHelperFun
 				Description: "Merge method",
 				Enum:        []any{"merge", "squash", "rebase"},
 			},
+			dryrun.ArgumentName: dryrun.Parameter(),
 		},
 		Required: []string{"owner", "repo", "pullNumber"},
 	}
@@ -1030,6 +1065,12 @@ func MergePullRequest(getClient GetClientFn, t translations.TranslationHelperFun
 			if err != nil {
 				return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
 			}
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(client)
+				_, _, err := plan.client.PullRequests.Merge(ctx, owner, repo, pullNumber, commitMessage, options)
+				plan.check(err)
+				return plan.result()
+			}
 			result, resp, err := client.PullRequests.Merge(ctx, owner, repo, pullNumber, commitMessage, options)
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -1137,6 +1178,7 @@ func UpdatePullRequestBranch(getClient GetClientFn, t translations.TranslationHe
 				Type:        "string",
 				Description: "The expected SHA of the pull request's HEAD ref",
 			},
+			dryrun.ArgumentName: dryrun.Parameter(),
 		},
 		Required: []string{"owner", "repo", "pullNumber"},
 	}
@@ -1176,6 +1218,12 @@ func UpdatePullRequestBranch(getClient GetClientFn, t translations.TranslationHe
 			if err != nil {
 				return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
 			}
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(client)
+				_, _, err := plan.client.PullRequests.UpdateBranch(ctx, owner, repo, pullNumber, opts)
+				plan.check(err)
+				return plan.result()
+			}
 			result, resp, err := client.PullRequests.UpdateBranch(ctx, owner, repo, pullNumber, opts)
 			if err != nil {
 				// Check if it's an acceptedError. An acceptedError indicates that the update is in progress,
@@ -1255,6 +1303,7 @@ func PullRequestReviewWrite(getGQLClient GetGQLClientFn, t translations.Translat
 				Type:        "string",
 				Description: "SHA of commit to review",
 			},
+			dryrun.ArgumentName: dryrun.Parameter(),
 		},
 		Required: []string{"method", "owner", "repo", "pullNumber"},
 	}
@@ -1286,23 +1335,31 @@ Available methods:
 				return utils.NewToolResultError(fmt.Sprintf("failed to get GitHub GQL client: %v", err)), nil, nil
 			}
 
+			// In a dry run, the review is looked up as usual but the mutation is planned
+			var plan *dryRunPlan
+			if dryrun.Requested(ctx, args) {
+				plan = newDryRunPlan(nil)
+			}
+
+			var result *mcp.CallToolResult
 			switch params.Method {
 			case "create":
-				result, err := CreatePullRequestReview(ctx, client, params)
-				return result, nil, err
+				result, err = CreatePullRequestReview(ctx, client, plan, params)
 			case "submit_pending":
-				result, err := SubmitPendingPullRequestReview(ctx, client, params)
-				return result, nil, err
+				result, err = SubmitPendingPullRequestReview(ctx, client, plan, params)
 			case "delete_pending":
-				result, err := DeletePendingPullRequestReview(ctx, client, params)
-				return result, nil, err
+				result, err = DeletePendingPullRequestReview(ctx, client, plan, params)
 			default:
 				return utils.NewToolResultError(fmt.Sprintf("unknown method: %s", params.Method)), nil, nil
 			}
+			if plan != nil && err == nil && !result.IsError {
+				return plan.result()
+			}
+			return result, nil, err
 		}
 }
 
-func CreatePullRequestReview(ctx context.Context, client *githubv4.Client, params PullRequestReviewWriteParams) (*mcp.CallToolResult, error) {
+func CreatePullRequestReview(ctx context.Context, client *githubv4.Client, plan *dryRunPlan, params PullRequestReviewWriteParams) (*mcp.CallToolResult, error) {
 	var getPullRequestQuery struct {
 		Repository struct {
 			PullRequest struct {
@@ -1342,12 +1399,16 @@ func CreatePullRequestReview(ctx context.Context, client *githubv4.Client, param
 		addPullRequestReviewInput.Body = githubv4.NewString(githubv4.String(params.Body))
 	}
 
-	if err := client.Mutate(
+	mutationClient := client
+	if plan != nil {
+		mutationClient = plan.gqlClient
+	}
+	if err := mutationClient.Mutate(
 		ctx,
 		&addPullRequestReviewMutation,
 		addPullRequestReviewInput,
 		nil,
-	); err != nil {
+	); err != nil && !plan.planned(err) {
 		return utils.NewToolResultError(err.Error()), nil
 	}
 
@@ -1360,7 +1421,7 @@ func CreatePullRequestReview(ctx context.Context, client *githubv4.Client, param
 	return utils.NewToolResultText("pull request review submitted successfully"), nil
 }
 
-func SubmitPendingPullRequestReview(ctx context.Context, client *githubv4.Client, params PullRequestReviewWriteParams) (*mcp.CallToolResult, error) {
+func SubmitPendingPullRequestReview(ctx context.Context, client *githubv4.Client, plan *dryRunPlan, params PullRequestReviewWriteParams) (*mcp.CallToolResult, error) {
 	// First we'll get the current user
 	var getViewerQuery struct {
 		Viewer struct {
@@ -1423,7 +1484,11 @@ func SubmitPendingPullRequestReview(ctx context.Context, client *githubv4.Client
 		} `graphql:"submitPullRequestReview(input: $input)"`
 	}
 
-	if err := client.Mutate(
+	mutationClient := client
+	if plan != nil {
+		mutationClient = plan.gqlClient
+	}
+	if err := mutationClient.Mutate(
 		ctx,
 		&submitPullRequestReviewMutation,
 		githubv4.SubmitPullRequestReviewInput{
@@ -1432,7 +1497,7 @@ func SubmitPendingPullRequestReview(ctx context.Context, client *githubv4.Client
 			Body:                newGQLStringlikePtr[githubv4.String](&params.Body),
 		},
 		nil,
-	); err != nil {
+	); err != nil && !plan.planned(err) {
 		return ghErrors.NewGitHubGraphQLErrorResponse(ctx,
 			"failed to submit pull request review",
 			err,
@@ -1445,7 +1510,7 @@ func SubmitPendingPullRequestReview(ctx context.Context, client *githubv4.Client
 	return utils.NewToolResultText("pending pull request review successfully submitted"), nil
 }
 
-func DeletePendingPullRequestReview(ctx context.Context, client *githubv4.Client, params PullRequestReviewWriteParams) (*mcp.CallToolResult, error) {
+func DeletePendingPullRequestReview(ctx context.Context, client *githubv4.Client, plan *dryRunPlan, params PullRequestReviewWriteParams) (*mcp.CallToolResult, error) {
 	// First we'll get the current user
 	var getViewerQuery struct {
 		Viewer struct {
@@ -1508,14 +1573,18 @@ func DeletePendingPullRequestReview(ctx context.Context, client *githubv4.Client
 		} `graphql:"deletePullRequestReview(input: $input)"`
 	}
 
-	if err := client.Mutate(
+	mutationClient := client
+	if plan != nil {
+		mutationClient = plan.gqlClient
+	}
+	if err := mutationClient.Mutate(
 		ctx,
 		&deletePullRequestReviewMutation,
 		githubv4.DeletePullRequestReviewInput{
 			PullRequestReviewID: &review.ID,
 		},
 		nil,
-	); err != nil {
+	); err != nil && !plan.planned(err) {
 		return utils.NewToolResultError(err.Error()), nil
 	}
 
@@ -1582,6 +1651,7 @@ func AddCommentToPendingReview(getGQLClient GetGQLClientFn, t translations.Trans
 				Description: "For multi-line comments, the starting side of the diff that the comment applies to. LEFT indicates the previous state, RIGHT indicates the new state",
 				Enum:        []any{"LEFT", "RIGHT"},
 			},
+			dryrun.ArgumentName: dryrun.Parameter(),
 		},
 		Required: []string{"owner", "repo", "pullNumber", "path", "body", "subjectType"},
 	}
@@ -1679,21 +1749,23 @@ func AddCommentToPendingReview(getGQLClient GetGQLClientFn, t translations.Trans
 				} `graphql:"addPullRequestReviewThread(input: $input)"`
 			}
 
-			if err := client.Mutate(
-				ctx,
-				&addPullRequestReviewThreadMutation,
-				githubv4.AddPullRequestReviewThreadInput{
-					Path:                githubv4.String(params.Path),
-					Body:                githubv4.String(params.Body),
-					SubjectType:         newGQLStringlikePtr[githubv4.PullRequestReviewThreadSubjectType](&params.SubjectType),
-					Line:                newGQLIntPtr(params.Line),
-					Side:                newGQLStringlikePtr[githubv4.DiffSide](params.Side),
-					StartLine:           newGQLIntPtr(params.StartLine),
-					StartSide:           newGQLStringlikePtr[githubv4.DiffSide](params.StartSide),
-					PullRequestReviewID: &review.ID,
-				},
-				nil,
-			); err != nil {
+			input := githubv4.AddPullRequestReviewThreadInput{
+				Path:                githubv4.String(params.Path),
+				Body:                githubv4.String(params.Body),
+				SubjectType:         newGQLStringlikePtr[githubv4.PullRequestReviewThreadSubjectType](&params.SubjectType),
+				Line:                newGQLIntPtr(params.Line),
+				Side:                newGQLStringlikePtr[githubv4.DiffSide](params.Side),
+				StartLine:           newGQLIntPtr(params.StartLine),
+				StartSide:           newGQLStringlikePtr[githubv4.DiffSide](params.StartSide),
+				PullRequestReviewID: &review.ID,
+			}
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(nil)
+				plan.check(plan.gqlClient.Mutate(ctx, &addPullRequestReviewThreadMutation, input, nil))
+				return plan.result()
+			}
+
+			if err := client.Mutate(ctx, &addPullRequestReviewThreadMutation, input, nil); err != nil {
 				return utils.NewToolResultError(err.Error()), nil, nil
 			}
 
@@ -1731,6 +1803,7 @@ func RequestCopilotReview(getClient GetClientFn, t translations.TranslationHelpe
 				Type:        "number",
 				Description: "Pull request number",
 			},
+			dryrun.ArgumentName: dryrun.Parameter(),
 		},
 		Required: []string{"owner", "repo", "pullNumber"},
 	}
@@ -1765,15 +1838,23 @@ func RequestCopilotReview(getClient GetClientFn, t translations.TranslationHelpe
 				return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
 			}
 
+			reviewersRequest := github.ReviewersRequest{
+				// The login name of the copilot reviewer bot
+				Reviewers: []string{"copilot-pull-request-reviewer[bot]"},
+			}
+			if dryrun.Requested(ctx, args) {
+				plan := newDryRunPlan(client)
+				_, _, err := plan.client.PullRequests.RequestReviewers(ctx, owner, repo, pullNumber, reviewersRequest)
+				plan.check(err)
+				return plan.result()
+			}
+
 			_, resp, err := client.PullRequests.RequestReviewers(
 				ctx,
 				owner,
 				repo,
 				pullNumber,
-				github.ReviewersRequest{
-					// The login name of the copilot reviewer bot
-					Reviewers: []string{"copilot-pull-request-reviewer[bot]"},
-				},
+				reviewersRequest,
 			)
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
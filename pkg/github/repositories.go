@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/raw"
 	"github.com/github/github-mcp-server/pkg/translations"
@@ -347,6 +348,7 @@ func CreateOrUpdateFile(getClient GetClientFn, t translations.TranslationHelperF
 					Type:        "string",
 					Description: "Required if updating an existing file. The blob SHA of the file being replaced.",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"owner", "repo", "path", "content", "message", "branch"},
 		},
@@ -402,6 +404,12 @@ func CreateOrUpdateFile(getClient GetClientFn, t translations.TranslationHelperF
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 		}
+		if dryrun.Requested(ctx, args) {
+			plan := newDryRunPlan(client)
+			_, _, err := plan.client.Repositories.CreateFile(ctx, owner, repo, path, opts)
+			plan.check(err)
+			return plan.result()
+		}
 		fileContent, resp, err := client.Repositories.CreateFile(ctx, owner, repo, path, opts)
 		if err != nil {
 			return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -463,6 +471,7 @@ func CreateRepository(getClient GetClientFn, t translations.TranslationHelperFun
 					Type:        "boolean",
 					Description: "Initialize with README",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"name"},
 		},
@@ -501,6 +510,12 @@ func CreateRepository(getClient GetClientFn, t translations.TranslationHelperFun
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 		}
+		if dryrun.Requested(ctx, args) {
+			plan := newDryRunPlan(client)
+			_, _, err := plan.client.Repositories.Create(ctx, organization, repo)
+			plan.check(err)
+			return plan.result()
+		}
 		createdRepo, resp, err := client.Repositories.Create(ctx, organization, repo)
 		if err != nil {
 			return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -778,6 +793,7 @@ func ForkRepository(getClient GetClientFn, t translations.TranslationHelperFunc)
 					Type:        "string",
 					Description: "Organization to fork to",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"owner", "repo"},
 		},
@@ -806,6 +822,12 @@ func ForkRepository(getClient GetClientFn, t translations.TranslationHelperFunc)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 		}
+		if dryrun.Requested(ctx, args) {
+			plan := newDryRunPlan(client)
+			_, _, err := plan.client.Repositories.CreateFork(ctx, owner, repo, opts)
+			plan.check(err)
+			return plan.result()
+		}
 		forkedRepo, resp, err := client.Repositories.CreateFork(ctx, owner, repo, opts)
 		if err != nil {
 			// Check if it's an acceptedError. An acceptedError indicates that the update is in progress,
@@ -884,6 +906,7 @@ func DeleteFile(getClient GetClientFn, t translations.TranslationHelperFunc) (mc
 					Type:        "string",
 					Description: "Branch to delete the file from",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"owner", "repo", "path", "message", "branch"},
 		},
@@ -952,6 +975,10 @@ func DeleteFile(getClient GetClientFn, t translations.TranslationHelperFunc) (mc
 			},
 		}
 
+		if dryrun.Requested(ctx, args) {
+			return planCommitToBranch(ctx, client, owner, repo, ref, baseCommit, treeEntries, message)
+		}
+
 		// Create a new tree with the deletion
 		newTree, resp, err := client.Git.CreateTree(ctx, owner, repo, *baseCommit.Tree.SHA, treeEntries)
 		if err != nil {
@@ -1063,6 +1090,7 @@ func CreateBranch(getClient GetClientFn, t translations.TranslationHelperFunc) (
 					Type:        "string",
 					Description: "Source branch (defaults to repo default)",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"owner", "repo", "branch"},
 		},
@@ -1126,6 +1154,12 @@ func CreateBranch(getClient GetClientFn, t translations.TranslationHelperFunc) (
 			SHA: *ref.Object.SHA,
 		}
 
+		if dryrun.Requested(ctx, args) {
+			plan := newDryRunPlan(client)
+			_, _, err := plan.client.Git.CreateRef(ctx, owner, repo, newRef)
+			plan.check(err)
+			return plan.result()
+		}
 		createdRef, resp, err := client.Git.CreateRef(ctx, owner, repo, newRef)
 		if err != nil {
 			return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -1193,6 +1227,7 @@ func PushFiles(getClient GetClientFn, t translations.TranslationHelperFunc) (mcp
 					Type:        "string",
 					Description: "Commit message",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"owner", "repo", "branch", "files", "message"},
 		},
@@ -1277,6 +1312,10 @@ func PushFiles(getClient GetClientFn, t translations.TranslationHelperFunc) (mcp
 			})
 		}
 
+		if dryrun.Requested(ctx, args) {
+			return planCommitToBranch(ctx, client, owner, repo, ref, baseCommit, entries, message)
+		}
+
 		// Create a new tree with the file entries
 		newTree, resp, err := client.Git.CreateTree(ctx, owner, repo, *baseCommit.Tree.SHA, entries)
 		if err != nil {
@@ -1330,6 +1369,27 @@ func PushFiles(getClient GetClientFn, t translations.TranslationHelperFunc) (mcp
 	return tool, handler
 }
 
+// planCommitToBranch plans the operations of a dry run of a tool that commits tree entries on top of the
+// commit a branch points to: creating the tree, then the commit of it, then moving the branch to the commit.
+func planCommitToBranch(ctx context.Context, client *github.Client, owner, repo string, ref *github.Reference, baseCommit *github.Commit, entries []*github.TreeEntry, message string) (*mcp.CallToolResult, any, error) {
+	plan := newDryRunPlan(client)
+	_, _, err := plan.client.Git.CreateTree(ctx, owner, repo, *baseCommit.Tree.SHA, entries)
+	plan.check(err)
+	commit := github.Commit{
+		Message: github.Ptr(message),
+		Tree:    &github.Tree{SHA: github.Ptr(plan.reference("sha"))},
+		Parents: []*github.Commit{{SHA: baseCommit.SHA}},
+	}
+	_, _, err = plan.client.Git.CreateCommit(ctx, owner, repo, commit, nil)
+	plan.check(err)
+	_, _, err = plan.client.Git.UpdateRef(ctx, owner, repo, *ref.Ref, github.UpdateRef{
+		SHA:   plan.reference("sha"),
+		Force: github.Ptr(false),
+	})
+	plan.check(err)
+	return plan.result()
+}
+
 // ListTags creates a tool to list tags in a GitHub repository.
 func ListTags(getClient GetClientFn, t translations.TranslationHelperFunc) (mcp.Tool, mcp.ToolHandlerFor[map[string]any, any]) {
 	tool := mcp.Tool{
@@ -2006,6 +2066,7 @@ func StarRepository(getClient GetClientFn, t translations.TranslationHelperFunc)
 					Type:        "string",
 					Description: "Repository name",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"owner", "repo"},
 		},
@@ -2026,6 +2087,12 @@ func StarRepository(getClient GetClientFn, t translations.TranslationHelperFunc)
 			return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 		}
 
+		if dryrun.Requested(ctx, args) {
+			plan := newDryRunPlan(client)
+			_, err := plan.client.Activity.Star(ctx, owner, repo)
+			plan.check(err)
+			return plan.result()
+		}
 		resp, err := client.Activity.Star(ctx, owner, repo)
 		if err != nil {
 			return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -2070,6 +2137,7 @@ func UnstarRepository(getClient GetClientFn, t translations.TranslationHelperFun
 					Type:        "string",
 					Description: "Repository name",
 				},
+				dryrun.ArgumentName: dryrun.Parameter(),
 			},
 			Required: []string{"owner", "repo"},
 		},
@@ -2090,6 +2158,12 @@ func UnstarRepository(getClient GetClientFn, t translations.TranslationHelperFun
 			return nil, nil, fmt.Errorf("failed to get GitHub client: %w", err)
 		}
 
+		if dryrun.Requested(ctx, args) {
+			plan := newDryRunPlan(client)
+			_, err := plan.client.Activity.Unstar(ctx, owner, repo)
+			plan.check(err)
+			return plan.result()
+		}
 		resp, err := client.Activity.Unstar(ctx, owner, repo)
 		if err != nil {
 			return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+)
+
+// Classic OAuth scopes that tools declare in their requirements.
+// See https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/scopes-for-oauth-apps
+const (
+	ScopeRepo           = "repo"
+	ScopePublicRepo     = "public_repo"
+	ScopeSecurityEvents = "security_events"
+	ScopeNotifications  = "notifications"
+	ScopeReadOrg        = "read:org"
+	ScopeWriteOrg       = "write:org"
+	ScopeAdminOrg       = "admin:org"
+	ScopeGist           = "gist"
+	ScopeProject        = "project"
+	ScopeReadProject    = "read:project"
+)
+
+// permissionLevels orders the access levels of fine-grained permissions.
+var permissionLevels = map[string]int{
+	"read":  1,
+	"write": 2,
+	"admin": 3,
+}
+
+// Requirements of the tools, shared between tools that work on the same kind of resource.
+var (
+	requiresContentsRead     = toolsets.TokenRequirements{Permissions: map[string]string{"contents": "read"}}
+	requiresContentsWrite    = toolsets.TokenRequirements{Scopes: []string{ScopePublicRepo}, Permissions: map[string]string{"contents": "write"}}
+	requiresAdministration   = toolsets.TokenRequirements{Scopes: []string{ScopePublicRepo}, Permissions: map[string]string{"administration": "write"}}
+	requiresIssuesRead       = toolsets.TokenRequirements{Permissions: map[string]string{"issues": "read"}}
+	requiresIssuesWrite      = toolsets.TokenRequirements{Scopes: []string{ScopePublicRepo}, Permissions: map[string]string{"issues": "write"}}
+	requiresPullRequestsRead = toolsets.TokenRequirements{Permissions: map[string]string{"pull_requests": "read"}}
+	requiresPullRequestWrite = toolsets.TokenRequirements{Scopes: []string{ScopePublicRepo}, Permissions: map[string]string{"pull_requests": "write"}}
+	requiresPullRequestMerge = toolsets.TokenRequirements{Scopes: []string{ScopePublicRepo}, Permissions: map[string]string{"pull_requests": "write", "contents": "write"}}
+	requiresActionsRead      = toolsets.TokenRequirements{Permissions: map[string]string{"actions": "read"}}
+	requiresActionsWrite     = toolsets.TokenRequirements{Scopes: []string{ScopePublicRepo}, Permissions: map[string]string{"actions": "write"}}
+	requiresCodeScanning     = toolsets.TokenRequirements{Scopes: []string{ScopeSecurityEvents, ScopePublicRepo}, Permissions: map[string]string{"security_events": "read"}}
+	requiresSecretScanning   = toolsets.TokenRequirements{Scopes: []string{ScopeSecurityEvents, ScopePublicRepo}, Permissions: map[string]string{"secret_scanning_alerts": "read"}}
+	requiresDependabot       = toolsets.TokenRequirements{Scopes: []string{ScopeSecurityEvents, ScopePublicRepo}, Permissions: map[string]string{"vulnerability_alerts": "read"}}
+	requiresAdvisories       = toolsets.TokenRequirements{Permissions: map[string]string{"repository_advisories": "read"}}
+	requiresNotifications    = toolsets.TokenRequirements{Scopes: []string{ScopeNotifications}}
+	requiresDiscussionsRead  = toolsets.TokenRequirements{Permissions: map[string]string{"discussions": "read"}}
+	requiresOrgMembersRead   = toolsets.TokenRequirements{Scopes: []string{ScopeReadOrg}, Permissions: map[string]string{"members": "read"}}
+	requiresGistWrite        = toolsets.TokenRequirements{Scopes: []string{ScopeGist}}
+	requiresProjectsRead     = toolsets.TokenRequirements{Scopes: []string{ScopeReadProject}, Permissions: map[string]string{"organization_projects": "read"}}
+	requiresProjectsWrite    = toolsets.TokenRequirements{Scopes: []string{ScopeProject}, Permissions: map[string]string{"organization_projects": "write"}}
+	requiresStarring         = toolsets.TokenRequirements{Scopes: []string{ScopePublicRepo}}
+)
+
+// ParseOAuthScopes parses the value of the X-OAuth-Scopes response header.
+func ParseOAuthScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if trimmed := strings.TrimSpace(scope); trimmed != "" {
+			scopes = append(scopes, trimmed)
+		}
+	}
+	return scopes
+}
+
+// UnusableWithScopes returns a filter for ToolsetGroup.RemoveTools that removes the tools a classic token
+// with the granted scopes cannot use.
+func UnusableWithScopes(granted []string) func(tool toolsets.ServerTool) (bool, string) {
+	expanded := scopes.Expand(granted)
+	return func(tool toolsets.ServerTool) (bool, string) {
+		required := tool.Requirements.Scopes
+		if len(required) == 0 {
+			return false, ""
+		}
+		for _, scope := range required {
+			if expanded[scope] {
+				return false, ""
+			}
+		}
+		if len(required) == 1 {
+			return true, fmt.Sprintf("token lacks the %s scope", required[0])
+		}
+		return true, fmt.Sprintf("token lacks one of the %s scopes", strings.Join(required, ", "))
+	}
+}
+
+// UnusableWithPermissions returns a filter for ToolsetGroup.RemoveTools that removes the tools a token with
+// the granted fine-grained permissions (e.g. a GitHub App installation token) cannot use.
+func UnusableWithPermissions(granted map[string]string) func(tool toolsets.ServerTool) (bool, string) {
+	return func(tool toolsets.ServerTool) (bool, string) {
+		var missing []string
+		for permission, level := range tool.Requirements.Permissions {
+			if permissionLevels[granted[permission]] < permissionLevels[level] {
+				missing = append(missing, fmt.Sprintf("%s:%s", permission, level))
+			}
+		}
+		if len(missing) == 0 {
+			return false, ""
+		}
+		sort.Strings(missing)
+		return true, fmt.Sprintf("token lacks the %s permission", strings.Join(missing, ", "))
+	}
+}
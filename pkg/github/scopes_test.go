@@ -0,0 +1,148 @@
+package github
+
+import (
+	"net/http"
+	"testing"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseOAuthScopes(t *testing.T) {
+	assert.Equal(t, []string{"repo", "read:org"}, ParseOAuthScopes("repo, read:org"))
+	assert.Empty(t, ParseOAuthScopes(""))
+}
+
+func Test_UnusableWithScopes(t *testing.T) {
+	tool := func(scopes ...string) toolsets.ServerTool {
+		return toolsets.ServerTool{
+			Tool:         mcp.Tool{Name: "tool"},
+			Requirements: toolsets.TokenRequirements{Scopes: scopes},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		granted        []string
+		tool           toolsets.ServerTool
+		expectedRemove bool
+		expectedReason string
+	}{
+		{
+			name:    "no requirements",
+			granted: nil,
+			tool:    tool(),
+		},
+		{
+			name:    "directly granted",
+			granted: []string{ScopeGist},
+			tool:    tool(ScopeGist),
+		},
+		{
+			name:    "implied by a broader scope",
+			granted: []string{ScopeRepo},
+			tool:    tool(ScopeNotifications),
+		},
+		{
+			name:    "implied transitively",
+			granted: []string{ScopeAdminOrg},
+			tool:    tool(ScopeReadOrg),
+		},
+		{
+			name:    "any of the alternatives",
+			granted: []string{ScopePublicRepo},
+			tool:    tool(ScopeSecurityEvents, ScopePublicRepo),
+		},
+		{
+			name:           "missing",
+			granted:        []string{ScopePublicRepo},
+			tool:           tool(ScopeGist),
+			expectedRemove: true,
+			expectedReason: "token lacks the gist scope",
+		},
+		{
+			name:           "missing all alternatives",
+			granted:        []string{ScopeReadOrg},
+			tool:           tool(ScopeSecurityEvents, ScopePublicRepo),
+			expectedRemove: true,
+			expectedReason: "token lacks one of the security_events, public_repo scopes",
+		},
+		{
+			name:           "narrower scope does not imply broader one",
+			granted:        []string{ScopeReadProject},
+			tool:           tool(ScopeProject),
+			expectedRemove: true,
+			expectedReason: "token lacks the project scope",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			remove, reason := UnusableWithScopes(tc.granted)(tc.tool)
+			assert.Equal(t, tc.expectedRemove, remove)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
+
+func Test_UnusableWithScopesAgreesWithErrors(t *testing.T) {
+	// The tools a token can use and the errors telling it lacks a scope follow the same implications
+	classic := []string{
+		ScopeRepo, ScopePublicRepo, "repo:status", "repo_deployment", "repo:invite", ScopeSecurityEvents, ScopeNotifications,
+		ScopeAdminOrg, ScopeWriteOrg, ScopeReadOrg, ScopeGist, ScopeProject, ScopeReadProject,
+		"user", "read:user", "user:email", "write:packages", "read:packages", "write:discussion", "read:discussion",
+		"admin:repo_hook", "read:repo_hook", "admin:gpg_key", "read:gpg_key", "workflow",
+	}
+	for _, granted := range classic {
+		for _, required := range classic {
+			unusable, _ := UnusableWithScopes([]string{granted})(toolsets.ServerTool{Requirements: toolsets.TokenRequirements{Scopes: []string{required}}})
+
+			header := http.Header{}
+			header.Set("X-OAuth-Scopes", granted)
+			header.Set("X-Accepted-OAuth-Scopes", required)
+			classification := ghErrors.ClassifyAPIError(nil, &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound, Header: header}})
+			assert.Equal(t, unusable, classification.Code == ghErrors.CodeInsufficientScopes, "%s granted, %s required", granted, required)
+		}
+	}
+	assert.Equal(t, ghErrors.CodeNotFound, ghErrors.ClassifyAPIError(nil, &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{
+		"X-Oauth-Scopes":          []string{"repo"},
+		"X-Accepted-Oauth-Scopes": []string{"security_events"},
+	}}}).Code, "repo implies security_events")
+}
+
+func Test_UnusableWithPermissions(t *testing.T) {
+	unusable := UnusableWithPermissions(map[string]string{"contents": "write", "issues": "read"})
+	tool := func(permissions map[string]string) toolsets.ServerTool {
+		return toolsets.ServerTool{Requirements: toolsets.TokenRequirements{Permissions: permissions}}
+	}
+
+	remove, _ := unusable(tool(nil))
+	assert.False(t, remove)
+
+	// A write permission covers read access
+	remove, _ = unusable(tool(map[string]string{"contents": "read"}))
+	assert.False(t, remove)
+
+	remove, reason := unusable(tool(map[string]string{"issues": "write", "pull_requests": "read"}))
+	assert.True(t, remove)
+	assert.Equal(t, "token lacks the issues:write, pull_requests:read permission", reason)
+}
+
+func Test_DefaultToolsetGroupRequirements(t *testing.T) {
+	tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), nil, translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+
+	// A token with broad scopes can use every tool
+	removed := tsg.RemoveTools(UnusableWithScopes([]string{ScopeRepo, ScopeReadOrg, "read:packages", ScopeGist, ScopeProject}))
+	assert.Empty(t, removed)
+
+	// A token without scopes can still use the tools that only read public data
+	removed = tsg.RemoveTools(UnusableWithScopes(nil))
+	assert.Contains(t, removed, "create_or_update_file")
+	assert.Contains(t, removed, "list_notifications")
+	assert.NotContains(t, removed, "get_file_contents")
+	assert.NotContains(t, removed, "search_repositories")
+}
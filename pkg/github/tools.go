@@ -168,24 +168,24 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 	repos := toolsets.NewToolset(ToolsetMetadataRepos.ID, ToolsetMetadataRepos.Description).
 		AddReadTools(
 			toolsets.NewServerTool(SearchRepositories(getClient, t)),
-			toolsets.NewServerTool(GetFileContents(getClient, getRawClient, t)),
-			toolsets.NewServerTool(ListCommits(getClient, t)),
+			toolsets.NewServerTool(GetFileContents(getClient, getRawClient, t)).WithRequirements(requiresContentsRead),
+			toolsets.NewServerTool(ListCommits(getClient, t)).WithRequirements(requiresContentsRead),
 			toolsets.NewServerTool(SearchCode(getClient, t)),
-			toolsets.NewServerTool(GetCommit(getClient, t)),
-			toolsets.NewServerTool(ListBranches(getClient, t)),
-			toolsets.NewServerTool(ListTags(getClient, t)),
-			toolsets.NewServerTool(GetTag(getClient, t)),
-			toolsets.NewServerTool(ListReleases(getClient, t)),
-			toolsets.NewServerTool(GetLatestRelease(getClient, t)),
-			toolsets.NewServerTool(GetReleaseByTag(getClient, t)),
+			toolsets.NewServerTool(GetCommit(getClient, t)).WithRequirements(requiresContentsRead),
+			toolsets.NewServerTool(ListBranches(getClient, t)).WithRequirements(requiresContentsRead),
+			toolsets.NewServerTool(ListTags(getClient, t)).WithRequirements(requiresContentsRead),
+			toolsets.NewServerTool(GetTag(getClient, t)).WithRequirements(requiresContentsRead),
+			toolsets.NewServerTool(ListReleases(getClient, t)).WithRequirements(requiresContentsRead),
+			toolsets.NewServerTool(GetLatestRelease(getClient, t)).WithRequirements(requiresContentsRead),
+			toolsets.NewServerTool(GetReleaseByTag(getClient, t)).WithRequirements(requiresContentsRead),
 		).
 		AddWriteTools(
-			toolsets.NewServerTool(CreateOrUpdateFile(getClient, t)),
-			toolsets.NewServerTool(CreateRepository(getClient, t)),
-			toolsets.NewServerTool(ForkRepository(getClient, t)),
-			toolsets.NewServerTool(CreateBranch(getClient, t)),
-			toolsets.NewServerTool(PushFiles(getClient, t)),
-			toolsets.NewServerTool(DeleteFile(getClient, t)),
+			toolsets.NewServerTool(CreateOrUpdateFile(getClient, t)).WithRequirements(requiresContentsWrite),
+			toolsets.NewServerTool(CreateRepository(getClient, t)).WithRequirements(requiresAdministration),
+			toolsets.NewServerTool(ForkRepository(getClient, t)).WithRequirements(requiresAdministration),
+			toolsets.NewServerTool(CreateBranch(getClient, t)).WithRequirements(requiresContentsWrite),
+			toolsets.NewServerTool(PushFiles(getClient, t)).WithRequirements(requiresContentsWrite),
+			toolsets.NewServerTool(DeleteFile(getClient, t)).WithRequirements(requiresContentsWrite),
 		).
 		AddResourceTemplates(
 			toolsets.NewServerResourceTemplate(GetRepositoryResourceContent(getClient, getRawClient, t)),
@@ -196,21 +196,21 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 		)
 	git := toolsets.NewToolset(ToolsetMetadataGit.ID, ToolsetMetadataGit.Description).
 		AddReadTools(
-			toolsets.NewServerTool(GetRepositoryTree(getClient, t)),
+			toolsets.NewServerTool(GetRepositoryTree(getClient, t)).WithRequirements(requiresContentsRead),
 		)
 	issues := toolsets.NewToolset(ToolsetMetadataIssues.ID, ToolsetMetadataIssues.Description).
 		AddReadTools(
-			toolsets.NewServerTool(IssueRead(getClient, getGQLClient, cache, t, flags)),
+			toolsets.NewServerTool(IssueRead(getClient, getGQLClient, cache, t, flags)).WithRequirements(requiresIssuesRead),
 			toolsets.NewServerTool(SearchIssues(getClient, t)),
-			toolsets.NewServerTool(ListIssues(getGQLClient, t)),
+			toolsets.NewServerTool(ListIssues(getGQLClient, t)).WithRequirements(requiresIssuesRead),
 			toolsets.NewServerTool(ListIssueTypes(getClient, t)),
 			toolsets.NewServerTool(GetLabel(getGQLClient, t)),
 		).
 		AddWriteTools(
-			toolsets.NewServerTool(IssueWrite(getClient, getGQLClient, t)),
-			toolsets.NewServerTool(AddIssueComment(getClient, t)),
-			toolsets.NewServerTool(AssignCopilotToIssue(getGQLClient, t)),
-			toolsets.NewServerTool(SubIssueWrite(getClient, t)),
+			toolsets.NewServerTool(IssueWrite(getClient, getGQLClient, t)).WithRequirements(requiresIssuesWrite),
+			toolsets.NewServerTool(AddIssueComment(getClient, t)).WithRequirements(requiresIssuesWrite),
+			toolsets.NewServerTool(AssignCopilotToIssue(getGQLClient, t)).WithRequirements(requiresIssuesWrite),
+			toolsets.NewServerTool(SubIssueWrite(getClient, t)).WithRequirements(requiresIssuesWrite),
 		).AddPrompts(
 		toolsets.NewServerPrompt(AssignCodingAgentPrompt(t)),
 		toolsets.NewServerPrompt(IssueToFixWorkflowPrompt(t)),
@@ -225,82 +225,82 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 		)
 	pullRequests := toolsets.NewToolset(ToolsetMetadataPullRequests.ID, ToolsetMetadataPullRequests.Description).
 		AddReadTools(
-			toolsets.NewServerTool(PullRequestRead(getClient, cache, t, flags)),
-			toolsets.NewServerTool(ListPullRequests(getClient, t)),
+			toolsets.NewServerTool(PullRequestRead(getClient, cache, t, flags)).WithRequirements(requiresPullRequestsRead),
+			toolsets.NewServerTool(ListPullRequests(getClient, t)).WithRequirements(requiresPullRequestsRead),
 			toolsets.NewServerTool(SearchPullRequests(getClient, t)),
 		).
 		AddWriteTools(
-			toolsets.NewServerTool(MergePullRequest(getClient, t)),
-			toolsets.NewServerTool(UpdatePullRequestBranch(getClient, t)),
-			toolsets.NewServerTool(CreatePullRequest(getClient, t)),
-			toolsets.NewServerTool(UpdatePullRequest(getClient, getGQLClient, t)),
-			toolsets.NewServerTool(RequestCopilotReview(getClient, t)),
+			toolsets.NewServerTool(MergePullRequest(getClient, t)).WithRequirements(requiresPullRequestMerge),
+			toolsets.NewServerTool(UpdatePullRequestBranch(getClient, t)).WithRequirements(requiresPullRequestWrite),
+			toolsets.NewServerTool(CreatePullRequest(getClient, t)).WithRequirements(requiresPullRequestWrite),
+			toolsets.NewServerTool(UpdatePullRequest(getClient, getGQLClient, t)).WithRequirements(requiresPullRequestWrite),
+			toolsets.NewServerTool(RequestCopilotReview(getClient, t)).WithRequirements(requiresPullRequestWrite),
 			// Reviews
-			toolsets.NewServerTool(PullRequestReviewWrite(getGQLClient, t)),
-			toolsets.NewServerTool(AddCommentToPendingReview(getGQLClient, t)),
+			toolsets.NewServerTool(PullRequestReviewWrite(getGQLClient, t)).WithRequirements(requiresPullRequestWrite),
+			toolsets.NewServerTool(AddCommentToPendingReview(getGQLClient, t)).WithRequirements(requiresPullRequestWrite),
 		)
 	codeSecurity := toolsets.NewToolset(ToolsetMetadataCodeSecurity.ID, ToolsetMetadataCodeSecurity.Description).
 		AddReadTools(
-			toolsets.NewServerTool(GetCodeScanningAlert(getClient, t)),
-			toolsets.NewServerTool(ListCodeScanningAlerts(getClient, t)),
+			toolsets.NewServerTool(GetCodeScanningAlert(getClient, t)).WithRequirements(requiresCodeScanning),
+			toolsets.NewServerTool(ListCodeScanningAlerts(getClient, t)).WithRequirements(requiresCodeScanning),
 		)
 	secretProtection := toolsets.NewToolset(ToolsetMetadataSecretProtection.ID, ToolsetMetadataSecretProtection.Description).
 		AddReadTools(
-			toolsets.NewServerTool(GetSecretScanningAlert(getClient, t)),
-			toolsets.NewServerTool(ListSecretScanningAlerts(getClient, t)),
+			toolsets.NewServerTool(GetSecretScanningAlert(getClient, t)).WithRequirements(requiresSecretScanning),
+			toolsets.NewServerTool(ListSecretScanningAlerts(getClient, t)).WithRequirements(requiresSecretScanning),
 		)
 	dependabot := toolsets.NewToolset(ToolsetMetadataDependabot.ID, ToolsetMetadataDependabot.Description).
 		AddReadTools(
-			toolsets.NewServerTool(GetDependabotAlert(getClient, t)),
-			toolsets.NewServerTool(ListDependabotAlerts(getClient, t)),
+			toolsets.NewServerTool(GetDependabotAlert(getClient, t)).WithRequirements(requiresDependabot),
+			toolsets.NewServerTool(ListDependabotAlerts(getClient, t)).WithRequirements(requiresDependabot),
 		)
 
 	notifications := toolsets.NewToolset(ToolsetMetadataNotifications.ID, ToolsetMetadataNotifications.Description).
 		AddReadTools(
-			toolsets.NewServerTool(ListNotifications(getClient, t)),
-			toolsets.NewServerTool(GetNotificationDetails(getClient, t)),
+			toolsets.NewServerTool(ListNotifications(getClient, t)).WithRequirements(requiresNotifications),
+			toolsets.NewServerTool(GetNotificationDetails(getClient, t)).WithRequirements(requiresNotifications),
 		).
 		AddWriteTools(
-			toolsets.NewServerTool(DismissNotification(getClient, t)),
-			toolsets.NewServerTool(MarkAllNotificationsRead(getClient, t)),
-			toolsets.NewServerTool(ManageNotificationSubscription(getClient, t)),
-			toolsets.NewServerTool(ManageRepositoryNotificationSubscription(getClient, t)),
+			toolsets.NewServerTool(DismissNotification(getClient, t)).WithRequirements(requiresNotifications),
+			toolsets.NewServerTool(MarkAllNotificationsRead(getClient, t)).WithRequirements(requiresNotifications),
+			toolsets.NewServerTool(ManageNotificationSubscription(getClient, t)).WithRequirements(requiresNotifications),
+			toolsets.NewServerTool(ManageRepositoryNotificationSubscription(getClient, t)).WithRequirements(requiresNotifications),
 		)
 
 	discussions := toolsets.NewToolset(ToolsetMetadataDiscussions.ID, ToolsetMetadataDiscussions.Description).
 		AddReadTools(
-			toolsets.NewServerTool(ListDiscussions(getGQLClient, t)),
-			toolsets.NewServerTool(GetDiscussion(getGQLClient, t)),
-			toolsets.NewServerTool(GetDiscussionComments(getGQLClient, t)),
-			toolsets.NewServerTool(ListDiscussionCategories(getGQLClient, t)),
+			toolsets.NewServerTool(ListDiscussions(getGQLClient, t)).WithRequirements(requiresDiscussionsRead),
+			toolsets.NewServerTool(GetDiscussion(getGQLClient, t)).WithRequirements(requiresDiscussionsRead),
+			toolsets.NewServerTool(GetDiscussionComments(getGQLClient, t)).WithRequirements(requiresDiscussionsRead),
+			toolsets.NewServerTool(ListDiscussionCategories(getGQLClient, t)).WithRequirements(requiresDiscussionsRead),
 		)
 
 	actions := toolsets.NewToolset(ToolsetMetadataActions.ID, ToolsetMetadataActions.Description).
 		AddReadTools(
-			toolsets.NewServerTool(ListWorkflows(getClient, t)),
-			toolsets.NewServerTool(ListWorkflowRuns(getClient, t)),
-			toolsets.NewServerTool(GetWorkflowRun(getClient, t)),
-			toolsets.NewServerTool(GetWorkflowRunLogs(getClient, t)),
-			toolsets.NewServerTool(ListWorkflowJobs(getClient, t)),
-			toolsets.NewServerTool(GetJobLogs(getClient, t, contentWindowSize)),
-			toolsets.NewServerTool(ListWorkflowRunArtifacts(getClient, t)),
-			toolsets.NewServerTool(DownloadWorkflowRunArtifact(getClient, t)),
-			toolsets.NewServerTool(GetWorkflowRunUsage(getClient, t)),
+			toolsets.NewServerTool(ListWorkflows(getClient, t)).WithRequirements(requiresActionsRead),
+			toolsets.NewServerTool(ListWorkflowRuns(getClient, t)).WithRequirements(requiresActionsRead),
+			toolsets.NewServerTool(GetWorkflowRun(getClient, t)).WithRequirements(requiresActionsRead),
+			toolsets.NewServerTool(GetWorkflowRunLogs(getClient, t)).WithRequirements(requiresActionsRead),
+			toolsets.NewServerTool(ListWorkflowJobs(getClient, t)).WithRequirements(requiresActionsRead),
+			toolsets.NewServerTool(GetJobLogs(getClient, t, contentWindowSize)).WithRequirements(requiresActionsRead),
+			toolsets.NewServerTool(ListWorkflowRunArtifacts(getClient, t)).WithRequirements(requiresActionsRead),
+			toolsets.NewServerTool(DownloadWorkflowRunArtifact(getClient, t)).WithRequirements(requiresActionsRead),
+			toolsets.NewServerTool(GetWorkflowRunUsage(getClient, t)).WithRequirements(requiresActionsRead),
 		).
 		AddWriteTools(
-			toolsets.NewServerTool(RunWorkflow(getClient, t)),
-			toolsets.NewServerTool(RerunWorkflowRun(getClient, t)),
-			toolsets.NewServerTool(RerunFailedJobs(getClient, t)),
-			toolsets.NewServerTool(CancelWorkflowRun(getClient, t)),
-			toolsets.NewServerTool(DeleteWorkflowRunLogs(getClient, t)),
+			toolsets.NewServerTool(RunWorkflow(getClient, t)).WithRequirements(requiresActionsWrite),
+			toolsets.NewServerTool(RerunWorkflowRun(getClient, t)).WithRequirements(requiresActionsWrite),
+			toolsets.NewServerTool(RerunFailedJobs(getClient, t)).WithRequirements(requiresActionsWrite),
+			toolsets.NewServerTool(CancelWorkflowRun(getClient, t)).WithRequirements(requiresActionsWrite),
+			toolsets.NewServerTool(DeleteWorkflowRunLogs(getClient, t)).WithRequirements(requiresActionsWrite),
 		)
 
 	securityAdvisories := toolsets.NewToolset(ToolsetMetadataSecurityAdvisories.ID, ToolsetMetadataSecurityAdvisories.Description).
 		AddReadTools(
 			toolsets.NewServerTool(ListGlobalSecurityAdvisories(getClient, t)),
 			toolsets.NewServerTool(GetGlobalSecurityAdvisory(getClient, t)),
-			toolsets.NewServerTool(ListRepositorySecurityAdvisories(getClient, t)),
-			toolsets.NewServerTool(ListOrgRepositorySecurityAdvisories(getClient, t)),
+			toolsets.NewServerTool(ListRepositorySecurityAdvisories(getClient, t)).WithRequirements(requiresAdvisories),
+			toolsets.NewServerTool(ListOrgRepositorySecurityAdvisories(getClient, t)).WithRequirements(requiresAdvisories),
 		)
 
 	// // Keep experiments alive so the system doesn't error out when it's always enabled
@@ -309,8 +309,8 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 	contextTools := toolsets.NewToolset(ToolsetMetadataContext.ID, ToolsetMetadataContext.Description).
 		AddReadTools(
 			toolsets.NewServerTool(GetMe(getClient, t)),
-			toolsets.NewServerTool(GetTeams(getClient, getGQLClient, t)),
-			toolsets.NewServerTool(GetTeamMembers(getGQLClient, t)),
+			toolsets.NewServerTool(GetTeams(getClient, getGQLClient, t)).WithRequirements(requiresOrgMembersRead),
+			toolsets.NewServerTool(GetTeamMembers(getGQLClient, t)).WithRequirements(requiresOrgMembersRead),
 		)
 
 	gists := toolsets.NewToolset(ToolsetMetadataGists.ID, ToolsetMetadataGists.Description).
@@ -319,31 +319,31 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 			toolsets.NewServerTool(GetGist(getClient, t)),
 		).
 		AddWriteTools(
-			toolsets.NewServerTool(CreateGist(getClient, t)),
-			toolsets.NewServerTool(UpdateGist(getClient, t)),
+			toolsets.NewServerTool(CreateGist(getClient, t)).WithRequirements(requiresGistWrite),
+			toolsets.NewServerTool(UpdateGist(getClient, t)).WithRequirements(requiresGistWrite),
 		)
 
 	projects := toolsets.NewToolset(ToolsetMetadataProjects.ID, ToolsetMetadataProjects.Description).
 		AddReadTools(
-			toolsets.NewServerTool(ListProjects(getClient, t)),
-			toolsets.NewServerTool(GetProject(getClient, t)),
-			toolsets.NewServerTool(ListProjectFields(getClient, t)),
-			toolsets.NewServerTool(GetProjectField(getClient, t)),
-			toolsets.NewServerTool(ListProjectItems(getClient, t)),
-			toolsets.NewServerTool(GetProjectItem(getClient, t)),
+			toolsets.NewServerTool(ListProjects(getClient, t)).WithRequirements(requiresProjectsRead),
+			toolsets.NewServerTool(GetProject(getClient, t)).WithRequirements(requiresProjectsRead),
+			toolsets.NewServerTool(ListProjectFields(getClient, t)).WithRequirements(requiresProjectsRead),
+			toolsets.NewServerTool(GetProjectField(getClient, t)).WithRequirements(requiresProjectsRead),
+			toolsets.NewServerTool(ListProjectItems(getClient, t)).WithRequirements(requiresProjectsRead),
+			toolsets.NewServerTool(GetProjectItem(getClient, t)).WithRequirements(requiresProjectsRead),
 		).
 		AddWriteTools(
-			toolsets.NewServerTool(AddProjectItem(getClient, t)),
-			toolsets.NewServerTool(DeleteProjectItem(getClient, t)),
-			toolsets.NewServerTool(UpdateProjectItem(getClient, t)),
+			toolsets.NewServerTool(AddProjectItem(getClient, t)).WithRequirements(requiresProjectsWrite),
+			toolsets.NewServerTool(DeleteProjectItem(getClient, t)).WithRequirements(requiresProjectsWrite),
+			toolsets.NewServerTool(UpdateProjectItem(getClient, t)).WithRequirements(requiresProjectsWrite),
 		)
 	stargazers := toolsets.NewToolset(ToolsetMetadataStargazers.ID, ToolsetMetadataStargazers.Description).
 		AddReadTools(
 			toolsets.NewServerTool(ListStarredRepositories(getClient, t)),
 		).
 		AddWriteTools(
-			toolsets.NewServerTool(StarRepository(getClient, t)),
-			toolsets.NewServerTool(UnstarRepository(getClient, t)),
+			toolsets.NewServerTool(StarRepository(getClient, t)).WithRequirements(requiresStarring),
+			toolsets.NewServerTool(UnstarRepository(getClient, t)).WithRequirements(requiresStarring),
 		)
 	labels := toolsets.NewToolset(ToolsetLabels.ID, ToolsetLabels.Description).
 		AddReadTools(
@@ -354,7 +354,7 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 		).
 		AddWriteTools(
 			// create or update
-			toolsets.NewServerTool(LabelWrite(getGQLClient, t)),
+			toolsets.NewServerTool(LabelWrite(getGQLClient, t)).WithRequirements(requiresIssuesWrite),
 		)
 
 	// Add toolsets to the group
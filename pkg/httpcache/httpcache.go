@@ -0,0 +1,205 @@
+// Package httpcache provides an HTTP transport that caches GitHub API responses and revalidates them
+// with conditional requests, which don't count against the rate limit when nothing has changed.
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// fromCacheHeader is set on responses served from the cache.
+const fromCacheHeader = "X-From-Cache"
+
+// shaPattern matches a full commit SHA (SHA-1 or SHA-256).
+var shaPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// immutablePathPattern matches REST API paths whose response is fully determined by the SHA in them.
+var immutablePathPattern = regexp.MustCompile(`/repos/[^/]+/[^/]+/(git/(blobs|trees|commits|tags)|commits)/([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// Result tells how a cacheable request was served.
+type Result string
+
+const (
+	// ResultHit is a response served from the cache without a request
+	ResultHit Result = "hit"
+	// ResultRevalidated is a response served from the cache after GitHub confirmed it was unchanged
+	ResultRevalidated Result = "revalidated"
+	// ResultMiss is a request that was not in the cache or had changed
+	ResultMiss Result = "miss"
+)
+
+// Transport caches the responses to GET requests that carry an ETag or Last-Modified header, and sends
+// conditional requests for them later on. A 304 Not Modified response is answered with the cached
+// response. Responses for content addressed by a commit SHA never change, so they are served from the
+// cache without asking GitHub at all.
+//
+// Responses are cached per URL, Accept header and Authorization header, so that clients with different
+// tokens never see each other's responses.
+type Transport struct {
+	transport http.RoundTripper
+	store     Store
+	logger    *slog.Logger
+	rawURL    *url.URL
+	observe   func(Result)
+}
+
+// Option configures a Transport at construction time.
+type Option func(*Transport)
+
+// WithLogger sets the logger used for cache diagnostics.
+func WithLogger(logger *slog.Logger) Option {
+	return func(t *Transport) {
+		t.logger = logger
+	}
+}
+
+// WithRawURL sets the base URL of the raw content API, so that raw content at a commit SHA is
+// treated as immutable.
+func WithRawURL(rawURL *url.URL) Option {
+	return func(t *Transport) {
+		t.rawURL = rawURL
+	}
+}
+
+// WithObserver sets a function that is told how every cacheable request was served, e.g. to count
+// cache hits.
+func WithObserver(observe func(Result)) Option {
+	return func(t *Transport) {
+		t.observe = observe
+	}
+}
+
+// NewTransport creates a caching transport storing responses in store and sending requests through transport.
+func NewTransport(transport http.RoundTripper, store Store, opts ...Option) *Transport {
+	t := &Transport{
+		transport: transport,
+		store:     store,
+		logger:    slog.New(slog.DiscardHandler),
+		observe:   func(Result) {},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !cacheable(req) {
+		return t.transport.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry, err := t.store.Get(key)
+	if err != nil {
+		t.logger.Debug("failed to read cached response", "url", req.URL.Redacted(), "error", err)
+	}
+
+	if entry != nil && entry.Immutable {
+		t.observe(ResultHit)
+		return entry.response(req, nil), nil
+	}
+
+	if entry != nil {
+		// Ask GitHub whether the cached response is still current
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry != nil && resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		t.observe(ResultRevalidated)
+		return entry.response(req, resp.Header), nil
+	}
+
+	t.observe(ResultMiss)
+	if !storable(resp) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = t.store.Set(key, &Entry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		Immutable:  t.immutable(req),
+	})
+	if err != nil {
+		t.logger.Debug("failed to cache response", "url", req.URL.Redacted(), "error", err)
+	}
+	return resp, nil
+}
+
+// cacheable reports whether the response to the request may be served from the cache.
+func cacheable(req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	// Leave requests the caller manages itself alone
+	for _, header := range []string{"Range", "If-None-Match", "If-Modified-Since"} {
+		if req.Header.Get(header) != "" {
+			return false
+		}
+	}
+	return !strings.Contains(req.Header.Get("Cache-Control"), "no-cache")
+}
+
+// storable reports whether the response can be revalidated later, and may be stored.
+func storable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	if strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return false
+	}
+	return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// immutable reports whether the request addresses content by commit SHA, whose response never changes.
+func (t *Transport) immutable(req *http.Request) bool {
+	if immutablePathPattern.MatchString(req.URL.Path) {
+		return true
+	}
+	if strings.Contains(req.URL.Path, "/contents/") && shaPattern.MatchString(req.URL.Query().Get("ref")) {
+		return true
+	}
+
+	// Raw content URLs have the form {rawURL}/{owner}/{repo}/{ref}/{path}
+	if t.rawURL == nil || req.URL.Host != t.rawURL.Host || !strings.HasPrefix(req.URL.Path, t.rawURL.Path) {
+		return false
+	}
+	segments := strings.SplitN(strings.TrimPrefix(req.URL.Path[len(t.rawURL.Path):], "/"), "/", 4)
+	return len(segments) == 4 && shaPattern.MatchString(segments[2])
+}
+
+// cacheKey identifies the cached response to a request. The Authorization header is part of the key,
+// hashed so that tokens never end up in the store.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\n%s\n%s\n", req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization"))
+	return hex.EncodeToString(h.Sum(nil))
+}
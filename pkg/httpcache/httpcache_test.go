@@ -0,0 +1,179 @@
+package httpcache
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSHA = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+
+func get(t *testing.T, client *http.Client, url, token string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func Test_Transport_Revalidates(t *testing.T) {
+	var requests atomic.Int32
+	var version atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		etag := fmt.Sprintf(`"v%d"`, version.Load())
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(int(10-n)))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte("body " + etag))
+	}))
+	defer ts.Close()
+
+	var results []Result
+	transport := NewTransport(http.DefaultTransport, NewMemoryStore(1<<20), WithObserver(func(result Result) {
+		results = append(results, result)
+	}))
+	client := &http.Client{Transport: transport}
+
+	resp, body := get(t, client, ts.URL+"/repos/owner/repo/issues/1", "token")
+	assert.Equal(t, `body "v0"`, body)
+	assert.Empty(t, resp.Header.Get(fromCacheHeader))
+
+	// Unchanged: served from the cache, with the headers of the 304 response
+	resp, body = get(t, client, ts.URL+"/repos/owner/repo/issues/1", "token")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `body "v0"`, body)
+	assert.Equal(t, "1", resp.Header.Get(fromCacheHeader))
+	assert.Equal(t, "8", resp.Header.Get("X-RateLimit-Remaining"))
+
+	// Changed: the new response replaces the cached one
+	version.Store(1)
+	_, body = get(t, client, ts.URL+"/repos/owner/repo/issues/1", "token")
+	assert.Equal(t, `body "v1"`, body)
+	_, body = get(t, client, ts.URL+"/repos/owner/repo/issues/1", "token")
+	assert.Equal(t, `body "v1"`, body)
+
+	assert.Equal(t, int32(4), requests.Load())
+	assert.Equal(t, []Result{ResultMiss, ResultRevalidated, ResultMiss, ResultRevalidated}, results)
+}
+
+func Test_Transport_SeparatesTokens(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"etag"`)
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport, NewMemoryStore(1<<20))}
+
+	_, body := get(t, client, ts.URL+"/user", "one")
+	assert.Equal(t, "Bearer one", body)
+	_, body = get(t, client, ts.URL+"/user", "two")
+	assert.Equal(t, "Bearer two", body)
+}
+
+func Test_Transport_Immutable(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		immutable bool
+	}{
+		{name: "git tree", path: "/repos/owner/repo/git/trees/" + testSHA, immutable: true},
+		{name: "git blob", path: "/repos/owner/repo/git/blobs/" + testSHA, immutable: true},
+		{name: "commit", path: "/repos/owner/repo/commits/" + testSHA, immutable: true},
+		{name: "contents at a commit", path: "/repos/owner/repo/contents/README.md?ref=" + testSHA, immutable: true},
+		{name: "raw content at a commit", path: "/raw/owner/repo/" + testSHA + "/README.md", immutable: true},
+		{name: "commit status", path: "/repos/owner/repo/commits/" + testSHA + "/status", immutable: false},
+		{name: "contents at a branch", path: "/repos/owner/repo/contents/README.md?ref=main", immutable: false},
+		{name: "raw content at a branch", path: "/raw/owner/repo/main/README.md", immutable: false},
+		{name: "branch", path: "/repos/owner/repo/git/trees/main", immutable: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if r.Header.Get("If-None-Match") != "" {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("ETag", `"etag"`)
+				_, _ = w.Write([]byte("content"))
+			}))
+			defer ts.Close()
+
+			rawURL, err := url.Parse(ts.URL + "/raw/")
+			require.NoError(t, err)
+			client := &http.Client{Transport: NewTransport(http.DefaultTransport, NewMemoryStore(1<<20), WithRawURL(rawURL))}
+
+			get(t, client, ts.URL+tc.path, "token")
+			_, body := get(t, client, ts.URL+tc.path, "token")
+			assert.Equal(t, "content", body)
+
+			if tc.immutable {
+				assert.Equal(t, int32(1), requests.Load())
+			} else {
+				assert.Equal(t, int32(2), requests.Load())
+			}
+		})
+	}
+}
+
+func Test_Transport_PassesThrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		status  int
+		headers map[string]string
+	}{
+		{name: "mutations", method: http.MethodPost, status: http.StatusOK},
+		{name: "errors", method: http.MethodGet, status: http.StatusNotFound},
+		{name: "responses without validators", method: http.MethodGet, status: http.StatusOK, headers: map[string]string{"ETag": ""}},
+		{name: "responses that must not be stored", method: http.MethodGet, status: http.StatusOK, headers: map[string]string{"Cache-Control": "no-store"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Empty(t, r.Header.Get("If-None-Match"))
+				w.Header().Set("ETag", `"etag"`)
+				for name, value := range tc.headers {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte("content"))
+			}))
+			defer ts.Close()
+
+			client := &http.Client{Transport: NewTransport(http.DefaultTransport, NewMemoryStore(1<<20))}
+			for range 2 {
+				req, err := http.NewRequest(tc.method, ts.URL+"/repos/owner/repo/issues", strings.NewReader(""))
+				require.NoError(t, err)
+				resp, err := client.Do(req)
+				require.NoError(t, err)
+				_ = resp.Body.Close()
+				assert.Equal(t, tc.status, resp.StatusCode)
+				assert.Empty(t, resp.Header.Get(fromCacheHeader))
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package httpcache
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	// Immutable is set for content addressed by commit SHA, which is served without revalidation
+	Immutable bool `json:"immutable,omitempty"`
+}
+
+// size approximates the memory used by the entry.
+func (e *Entry) size() int64 {
+	size := int64(len(e.Body))
+	for name, values := range e.Header {
+		size += int64(len(name))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+	return size
+}
+
+// response builds the response to req from the entry, with the headers of a revalidation response
+// (e.g. the current rate limit) taking precedence over the cached ones.
+func (e *Entry) response(req *http.Request, revalidated http.Header) *http.Response {
+	header := e.Header.Clone()
+	for name, values := range revalidated {
+		header[name] = values
+	}
+	header.Set(fromCacheHeader, "1")
+
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// Store stores cached responses by key.
+type Store interface {
+	// Get returns the entry stored under key, or nil if there is none.
+	Get(key string) (*Entry, error)
+	// Set stores entry under key, replacing any existing entry.
+	Set(key string, entry *Entry) error
+}
+
+// MemoryStore keeps entries in memory, evicting the least recently used ones beyond its size limit.
+type MemoryStore struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type memoryItem struct {
+	key   string
+	entry *Entry
+}
+
+// NewMemoryStore creates a store keeping at most maxBytes of responses in memory.
+func NewMemoryStore(maxBytes int64) *MemoryStore {
+	return &MemoryStore{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (s *MemoryStore) Get(key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*memoryItem).entry, nil
+}
+
+func (s *MemoryStore) Set(key string, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+
+	size := entry.size()
+	if size > s.maxBytes {
+		// Caching it would evict everything else
+		return nil
+	}
+	s.entries[key] = s.order.PushFront(&memoryItem{key: key, entry: entry})
+	s.size += size
+
+	for s.size > s.maxBytes {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+func (s *MemoryStore) remove(element *list.Element) {
+	item := element.Value.(*memoryItem)
+	s.order.Remove(element)
+	delete(s.entries, item.key)
+	s.size -= item.entry.size()
+}
+
+// DiskStore keeps entries as files in a directory, so that they survive restarts. The files are
+// only readable by the current user, as they may hold the contents of private repositories.
+type DiskStore struct {
+	dir string
+}
+
+// NewDiskStore creates a store keeping entries in dir, which is created when the first entry is stored.
+func NewDiskStore(dir string) *DiskStore {
+	return &DiskStore{dir: dir}
+}
+
+func (s *DiskStore) Get(key string) (*Entry, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse cache entry: %w", err)
+	}
+	return &entry, nil
+}
+
+func (s *DiskStore) Set(key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so that readers never see a partial entry
+	tmp, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+func (s *DiskStore) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+// TieredStore looks entries up in each of its stores in turn, copying entries found in a later store
+// into the earlier ones. Entries are stored in all of them.
+type TieredStore struct {
+	stores []Store
+}
+
+// NewTieredStore creates a store over stores, fastest first (e.g. a MemoryStore in front of a DiskStore).
+func NewTieredStore(stores ...Store) *TieredStore {
+	return &TieredStore{stores: stores}
+}
+
+func (s *TieredStore) Get(key string) (*Entry, error) {
+	for i, store := range s.stores {
+		entry, err := store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			continue
+		}
+		for _, earlier := range s.stores[:i] {
+			// The entry was found, so failing to copy it is not worth failing the lookup for
+			_ = earlier.Set(key, entry)
+		}
+		return entry, nil
+	}
+	return nil, nil
+}
+
+func (s *TieredStore) Set(key string, entry *Entry) error {
+	var errs []error
+	for _, store := range s.stores {
+		errs = append(errs, store.Set(key, entry))
+	}
+	return errors.Join(errs...)
+}
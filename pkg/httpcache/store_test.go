@@ -0,0 +1,91 @@
+package httpcache
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEntry(body string) *Entry {
+	return &Entry{StatusCode: http.StatusOK, Header: http.Header{}, Body: []byte(body)}
+}
+
+func Test_MemoryStore(t *testing.T) {
+	store := NewMemoryStore(10)
+
+	require.NoError(t, store.Set("a", newEntry("aaaa")))
+	require.NoError(t, store.Set("b", newEntry("bbbb")))
+
+	// Reading a makes b the least recently used entry
+	entry, err := store.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "aaaa", string(entry.Body))
+
+	require.NoError(t, store.Set("c", newEntry("cccc")))
+
+	entry, err = store.Get("b")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+	for _, key := range []string{"a", "c"} {
+		entry, err = store.Get(key)
+		require.NoError(t, err)
+		assert.NotNil(t, entry, key)
+	}
+
+	// Entries larger than the store are not kept
+	require.NoError(t, store.Set("d", newEntry("ddddddddddd")))
+	entry, err = store.Get("d")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
+
+func Test_DiskStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	store := NewDiskStore(dir)
+
+	entry, err := store.Get("key")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	stored := &Entry{StatusCode: http.StatusOK, Header: http.Header{"Etag": {`"etag"`}}, Body: []byte("content"), Immutable: true}
+	require.NoError(t, store.Set("key", stored))
+
+	entry, err = NewDiskStore(dir).Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, stored, entry)
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, "key.json"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		info, err = os.Stat(dir)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	}
+}
+
+func Test_TieredStore(t *testing.T) {
+	memory := NewMemoryStore(1 << 20)
+	disk := NewDiskStore(t.TempDir())
+	require.NoError(t, disk.Set("key", newEntry("content")))
+
+	store := NewTieredStore(memory, disk)
+	entry, err := store.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(entry.Body))
+
+	// The entry found on disk is now also kept in memory
+	entry, err = memory.Get("key")
+	require.NoError(t, err)
+	assert.NotNil(t, entry)
+
+	require.NoError(t, store.Set("other", newEntry("other")))
+	entry, err = disk.Get("other")
+	require.NoError(t, err)
+	assert.NotNil(t, entry)
+}
@@ -102,6 +102,11 @@ func TestRepoAccessCacheEvictsAfterTTL(t *testing.T) {
 	require.True(t, info.HasPushAccess)
 	require.EqualValues(t, 1, transport.CallCount())
 
+	_, err = cache.getRepoAccessInfo(ctx, testUser, testOwner, testRepo)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, transport.CallCount())
+	require.Equal(t, CacheStats{Hits: 1, Misses: 1}, cache.Stats())
+
 	time.Sleep(20 * time.Millisecond)
 
 	info, err = cache.getRepoAccessInfo(ctx, testUser, testOwner, testRepo)
@@ -109,4 +114,5 @@ func TestRepoAccessCacheEvictsAfterTTL(t *testing.T) {
 	require.Equal(t, testUser, info.ViewerLogin)
 	require.True(t, info.HasPushAccess)
 	require.EqualValues(t, 2, transport.CallCount())
+	require.Equal(t, CacheStats{Hits: 1, Misses: 2, Evictions: 1}, cache.Stats())
 }
@@ -2,18 +2,29 @@ package log
 
 import (
 	"io"
+	"strings"
 
 	"log/slog"
 )
 
+// redactionWindow is the length of the end of the data that is held back from the log until more data
+// comes, as a secret in it may continue in the next chunk. It is at least that of the longest token,
+// a fine-grained personal access token.
+const redactionWindow = 100
+
 // IOLogger is a wrapper around io.Reader and io.Writer that can be used
-// to log the data being read and written from the underlying streams
+// to log the data being read and written from the underlying streams.
+// Secrets in the data are redacted from the log, see Redact.
 type IOLogger struct {
 	io.ReadWriteCloser
 
 	reader io.Reader
 	writer io.Writer
 	logger *slog.Logger
+
+	// received and sent hold back the ends of the streams, see redactionWindow
+	received redactedStream
+	sent     redactedStream
 }
 
 // NewIOLogger creates a new IOLogger instance
@@ -31,8 +42,15 @@ func (l *IOLogger) Read(p []byte) (n int, err error) {
 		return 0, io.EOF
 	}
 	n, err = l.reader.Read(p)
-	if n > 0 {
-		l.logger.Info("[stdin]: received bytes", "count", n, "data", string(p[:n]))
+	if n > 0 || err != nil {
+		data := l.received.next(p[:n])
+		if err != nil {
+			// Nothing more is coming
+			data += l.received.flush()
+		}
+		if n > 0 || data != "" {
+			l.logger.Info("[stdin]: received bytes", "count", n, "data", data)
+		}
 	}
 	return n, err
 }
@@ -42,11 +60,15 @@ func (l *IOLogger) Write(p []byte) (n int, err error) {
 	if l.writer == nil {
 		return 0, io.ErrClosedPipe
 	}
-	l.logger.Info("[stdout]: sending bytes", "count", len(p), "data", string(p))
+	l.logger.Info("[stdout]: sending bytes", "count", len(p), "data", l.sent.next(p))
 	return l.writer.Write(p)
 }
 
 func (l *IOLogger) Close() error {
+	if data := l.sent.flush(); data != "" {
+		l.logger.Info("[stdout]: sending bytes", "count", 0, "data", data)
+	}
+
 	var errReader, errWriter error
 	if closer, ok := l.reader.(io.Closer); ok {
 		errReader = closer.Close()
@@ -59,3 +81,46 @@ func (l *IOLogger) Close() error {
 	}
 	return errWriter
 }
+
+// redactedStream redacts the data of a stream that comes in chunks. A secret may be split between
+// two chunks, where neither half would be redacted, so the end of each chunk is only logged with
+// the next one.
+type redactedStream struct {
+	pending string
+}
+
+// next returns the redacted data that can be logged once chunk came. It holds back the last
+// redactionWindow bytes and any secret reaching into them, unless the data ends with a line break,
+// which ends messages and which no secret spans.
+func (s *redactedStream) next(chunk []byte) string {
+	data := s.pending + string(chunk)
+	end := len(data)
+	if !strings.HasSuffix(data, "\n") {
+		end = secretStart(data, max(0, len(data)-redactionWindow))
+	}
+	s.pending = data[end:]
+	return Redact(data[:end])
+}
+
+// flush returns the redacted data that is held back.
+func (s *redactedStream) flush() string {
+	data := s.pending
+	s.pending = ""
+	return Redact(data)
+}
+
+// secretStart moves end back to the start of the secret that data[:end] would split, if there is one.
+func secretStart(data string, end int) int {
+	for moved := true; moved; {
+		moved = false
+		for _, pattern := range secretPatterns {
+			for _, match := range pattern.FindAllStringIndex(data, -1) {
+				if match[0] < end && end <= match[1] {
+					end = match[0]
+					moved = true
+				}
+			}
+		}
+	}
+	return end
+}
@@ -0,0 +1,118 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+)
+
+// redacted replaces secrets in log output.
+const redacted = "[REDACTED]"
+
+// secretPatterns match secrets. If a pattern has a "keep" group, the text it matches is kept, so
+// that the log still tells what kind of secret was there (e.g. "ghp_[REDACTED]").
+var secretPatterns = []*regexp.Regexp{
+	// GitHub tokens: personal access tokens (classic and fine-grained), OAuth, user-to-server,
+	// server-to-server (e.g. GitHub App installations) and refresh tokens
+	regexp.MustCompile(`\b(?P<keep>gh[pousr]_)[A-Za-z0-9]{30,}`),
+	regexp.MustCompile(`\b(?P<keep>github_pat_)[A-Za-z0-9_]{30,}`),
+	// Legacy GitHub App installation tokens
+	regexp.MustCompile(`\b(?P<keep>v1\.)[0-9a-f]{40}\b`),
+	// Authorization headers, in HTTP dumps and in JSON, where header values may be arrays
+	regexp.MustCompile(`(?i)(?P<keep>\bauthorization["']?\s*[:=]\s*\[?["']?(?:(?:bearer|token|basic)\s+)?)[^\s"',}]+`),
+	// JSON values of keys that hold secrets
+	regexp.MustCompile(`(?i)(?P<keep>"[a-z_-]*(?:password|passwd|secret|token|private_key|api_key|apikey)"\s*:\s*")(?:[^"\\]|\\.)+`),
+	// JSON Web Tokens, such as GitHub App JWTs
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+`),
+	// PEM encoded private keys
+	regexp.MustCompile(`(?P<keep>-----BEGIN [A-Z ]*PRIVATE KEY-----)[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	// Other services' credentials that commonly end up in repositories
+	regexp.MustCompile(`\b(?P<keep>AKIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\b(?P<keep>xox[abprs]-)[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`\b(?P<keep>npm_)[A-Za-z0-9]{36}\b`),
+}
+
+// Redact masks GitHub tokens, Authorization headers and other secrets in s.
+func Redact(s string) string {
+	for _, pattern := range secretPatterns {
+		keep := pattern.SubexpIndex("keep")
+		s = pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if keep < 0 {
+				return redacted
+			}
+			groups := pattern.FindStringSubmatch(match)
+			return groups[keep] + redacted
+		})
+	}
+	return s
+}
+
+// RedactingHandler is a slog.Handler that masks secrets in the message and attributes of every
+// record before passing it on.
+type RedactingHandler struct {
+	handler slog.Handler
+}
+
+// NewRedactingHandler creates a handler redacting records before they reach handler.
+func NewRedactingHandler(handler slog.Handler) *RedactingHandler {
+	return &RedactingHandler{handler: handler}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redactedRecord := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redactedRecord.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.handler.Handle(ctx, redactedRecord)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redactedAttrs[i] = redactAttr(attr)
+	}
+	return &RedactingHandler{handler: h.handler.WithAttrs(redactedAttrs)}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{handler: h.handler.WithGroup(name)}
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redactedGroup := make([]any, len(group))
+		for i, member := range group {
+			redactedGroup[i] = redactAttr(member)
+		}
+		return slog.Group(attr.Key, redactedGroup...)
+	case slog.KindAny:
+		// Errors and other values are logged as text, which may contain secrets (e.g. a URL with a token)
+		switch v := value.Any().(type) {
+		case error:
+			return slog.String(attr.Key, Redact(v.Error()))
+		case fmt.Stringer:
+			return slog.String(attr.Key, Redact(v.String()))
+		case []byte:
+			return slog.String(attr.Key, Redact(string(v)))
+		default:
+			// Maps, structs and the like (e.g. http.Header) are logged as JSON instead
+			if data, err := json.Marshal(v); err == nil {
+				return slog.String(attr.Key, Redact(string(data)))
+			}
+			return slog.String(attr.Key, Redact(fmt.Sprintf("%+v", v)))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
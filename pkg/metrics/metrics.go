@@ -0,0 +1,198 @@
+// Package metrics collects Prometheus metrics about tool calls, GitHub API requests and the lockdown cache.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/httpcache"
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "github_mcp"
+
+// unknownTool labels calls to tools the server doesn't have, so that clients can't create new series.
+const unknownTool = "unknown"
+
+// Metrics holds the collectors of the server. It is safe to share between MCP servers (e.g. one per
+// HTTP session), which then report into the same series.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	toolCalls        *prometheus.CounterVec
+	toolCallErrors   *prometheus.CounterVec
+	toolCallDuration *prometheus.HistogramVec
+	apiRequests      *prometheus.CounterVec
+	apiRetries       prometheus.Counter
+	cacheRequests    *prometheus.CounterVec
+
+	lockdownOnce sync.Once
+}
+
+// New creates the metrics of the server, together with the Go runtime and process metrics.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tool_calls_total",
+			Help:      "Number of tool calls.",
+		}, []string{"tool", "toolset"}),
+		toolCallErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tool_call_errors_total",
+			Help:      "Number of tool calls that returned an error.",
+		}, []string{"tool", "toolset"}),
+		toolCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "tool_call_duration_seconds",
+			Help:      "Duration of tool calls.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"tool", "toolset"}),
+		apiRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "github_api_requests_total",
+			Help:      "Number of requests sent to the GitHub API, by API and response status code.",
+		}, []string{"api", "code"}),
+		apiRetries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "github_api_retries_total",
+			Help:      "Number of GitHub API requests retried after a transient failure.",
+		}),
+		cacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "response_cache",
+			Name:      "requests_total",
+			Help:      "Number of cacheable GitHub API requests, by how they were served: hit, revalidated or miss.",
+		}, []string{"result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.toolCalls,
+		m.toolCallErrors,
+		m.toolCallDuration,
+		m.apiRequests,
+		m.apiRetries,
+		m.cacheRequests,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ToolsetFunc returns the toolset of a tool, and whether the tool exists.
+type ToolsetFunc func(tool string) (string, bool)
+
+// Middleware returns receiving middleware that counts and times tool calls. A call counts as an
+// error if it fails or its result is a tool error.
+func (m *Metrics) Middleware(toolset ToolsetFunc) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			tool := call.Params.Name
+			toolsetName, known := toolset(tool)
+			if !known {
+				tool, toolsetName = unknownTool, ""
+			}
+
+			start := time.Now()
+			result, err := next(ctx, method, req)
+			m.toolCallDuration.WithLabelValues(tool, toolsetName).Observe(time.Since(start).Seconds())
+			m.toolCalls.WithLabelValues(tool, toolsetName).Inc()
+
+			toolResult, _ := result.(*mcp.CallToolResult)
+			if err != nil || (toolResult != nil && toolResult.IsError) {
+				m.toolCallErrors.WithLabelValues(tool, toolsetName).Inc()
+			}
+			return result, err
+		}
+	}
+}
+
+// Transport returns a transport counting the requests sent through transport by API ("rest",
+// "graphql" or "raw", going by rawURL) and response status code. Requests that fail without a
+// response are counted with the code "error".
+func (m *Metrics) Transport(transport http.RoundTripper, rawURL *url.URL) http.RoundTripper {
+	return &countingTransport{transport: transport, requests: m.apiRequests, rawURL: rawURL}
+}
+
+// ObserveRetry counts a retry of a GitHub API request, see retry.Transport.
+func (m *Metrics) ObserveRetry() {
+	m.apiRetries.Inc()
+}
+
+// ObserveCacheResult counts a cacheable request by how the response cache served it, see
+// httpcache.WithObserver.
+func (m *Metrics) ObserveCacheResult(result httpcache.Result) {
+	m.cacheRequests.WithLabelValues(string(result)).Inc()
+}
+
+// RegisterLockdownCache exposes the activity counters of the lockdown repo access cache. The cache
+// is a singleton, so only the first call has an effect.
+func (m *Metrics) RegisterLockdownCache(cache *lockdown.RepoAccessCache) {
+	m.lockdownOnce.Do(func() {
+		counter := func(name, help string, value func(lockdown.CacheStats) int64) prometheus.Collector {
+			return prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "lockdown_cache",
+				Name:      name,
+				Help:      help,
+			}, func() float64 {
+				return float64(value(cache.Stats()))
+			})
+		}
+		m.registry.MustRegister(
+			counter("hits_total", "Number of repo access lookups answered from the lockdown cache.",
+				func(s lockdown.CacheStats) int64 { return s.Hits }),
+			counter("misses_total", "Number of repo access lookups that had to query GitHub.",
+				func(s lockdown.CacheStats) int64 { return s.Misses }),
+			counter("evictions_total", "Number of lockdown cache entries that expired.",
+				func(s lockdown.CacheStats) int64 { return s.Evictions }),
+		)
+	})
+}
+
+type countingTransport struct {
+	transport http.RoundTripper
+	requests  *prometheus.CounterVec
+	rawURL    *url.URL
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	t.requests.WithLabelValues(t.api(req), code).Inc()
+	return resp, err
+}
+
+func (t *countingTransport) api(req *http.Request) string {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		return "graphql"
+	case t.rawURL != nil && req.URL.Host == t.rawURL.Host && strings.HasPrefix(req.URL.Path, t.rawURL.Path):
+		return "raw"
+	default:
+		return "rest"
+	}
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/httpcache"
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Middleware(t *testing.T) {
+	m := New()
+	toolsets := map[string]string{"get_issue": "issues", "create_issue": "issues"}
+	middleware := m.Middleware(func(tool string) (string, bool) {
+		toolset, ok := toolsets[tool]
+		return toolset, ok
+	})
+
+	call := func(tool string, handler mcp.MethodHandler) {
+		_, _ = middleware(handler)(context.Background(), "tools/call", &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: tool},
+		})
+	}
+	succeed := func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{}, nil
+	}
+	failTool := func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{IsError: true}, nil
+	}
+	failCall := func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return nil, errors.New("boom")
+	}
+
+	call("get_issue", succeed)
+	call("get_issue", failTool)
+	call("create_issue", failCall)
+	call("no_such_tool", failCall)
+
+	// Other methods are not counted
+	_, err := middleware(succeed)(context.Background(), "tools/list", &mcp.ListToolsRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.toolCalls.WithLabelValues("get_issue", "issues")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.toolCallErrors.WithLabelValues("get_issue", "issues")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.toolCalls.WithLabelValues("create_issue", "issues")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.toolCallErrors.WithLabelValues("create_issue", "issues")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.toolCalls.WithLabelValues(unknownTool, "")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.toolCalls))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.toolCallDuration))
+}
+
+func Test_Transport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/repo/issues/404" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	m := New()
+	rawURL, err := url.Parse(ts.URL + "/raw/")
+	require.NoError(t, err)
+	client := &http.Client{Transport: m.Transport(http.DefaultTransport, rawURL)}
+
+	for _, path := range []string{"/repos/owner/repo/issues/1", "/repos/owner/repo/issues/404", "/graphql", "/raw/owner/repo/main/README.md"} {
+		resp, err := client.Get(ts.URL + path)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+	_, err = client.Get("http://127.0.0.1:0/user")
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.apiRequests.WithLabelValues("rest", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.apiRequests.WithLabelValues("rest", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.apiRequests.WithLabelValues("graphql", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.apiRequests.WithLabelValues("raw", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.apiRequests.WithLabelValues("rest", "error")))
+}
+
+func Test_ObserveRetry(t *testing.T) {
+	m := New()
+	m.ObserveRetry()
+	m.ObserveRetry()
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.apiRetries))
+}
+
+func Test_ObserveCacheResult(t *testing.T) {
+	m := New()
+	m.ObserveCacheResult(httpcache.ResultHit)
+	m.ObserveCacheResult(httpcache.ResultMiss)
+	m.ObserveCacheResult(httpcache.ResultMiss)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheRequests.WithLabelValues("hit")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.cacheRequests.WithLabelValues("miss")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.cacheRequests.WithLabelValues("revalidated")))
+}
+
+func Test_Handler(t *testing.T) {
+	m := New()
+	cache := lockdown.GetInstance(nil, lockdown.WithCacheName("metrics-test"))
+	m.RegisterLockdownCache(cache)
+	m.RegisterLockdownCache(cache)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, string(body), "github_mcp_lockdown_cache_hits_total 0")
+	assert.Contains(t, string(body), "github_mcp_lockdown_cache_misses_total 0")
+	assert.Contains(t, string(body), "go_goroutines")
+}
@@ -0,0 +1,90 @@
+// Package netconfig builds the HTTP transport used to connect to GitHub, with support for private
+// certificate authorities, explicit proxies and client certificates.
+package netconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Config configures the connections to GitHub. The zero value connects like http.DefaultTransport,
+// taking the proxy from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+type Config struct {
+	// CACertFile is a PEM file of CA certificates to trust in addition to the system's
+	CACertFile string
+	// ProxyURL is the proxy to send requests through, instead of the one from the environment
+	ProxyURL string
+	// NoProxy lists the hosts not to send through ProxyURL, in the format of NO_PROXY
+	NoProxy string
+	// ClientCertFile and ClientKeyFile are a PEM certificate and key to present to servers and
+	// HTTPS proxies that require client certificates
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// IsZero reports whether the config leaves the defaults unchanged.
+func (c Config) IsZero() bool {
+	return c == Config{}
+}
+
+// Transport creates a transport applying the config to http.DefaultTransport.
+func (c Config) Transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.IsZero() {
+		return transport, nil
+	}
+
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q, expected e.g. http://proxy.example.com:3128", c.ProxyURL)
+		}
+		proxyFunc := (&httpproxy.Config{
+			HTTPProxy:  c.ProxyURL,
+			HTTPSProxy: c.ProxyURL,
+			NoProxy:    c.NoProxy,
+		}).ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	} else if c.NoProxy != "" {
+		return nil, fmt.Errorf("no proxy hosts given without a proxy URL")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CACertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM encoded certificates found in %s", c.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case c.ClientCertFile != "" && c.ClientKeyFile != "":
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case c.ClientCertFile != "" || c.ClientKeyFile != "":
+		return nil, fmt.Errorf("a client certificate needs both a certificate and a key file")
+	}
+
+	// The TLS config also applies to the connection to an HTTPS proxy
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -0,0 +1,146 @@
+package netconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "file.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return path
+}
+
+// newClientCertificate creates a self-signed client certificate, returning its files and certificate.
+func newClientCertificate(t *testing.T) (string, string, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "github-mcp-server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return writePEM(t, "CERTIFICATE", der), writePEM(t, "EC PRIVATE KEY", keyDER), cert
+}
+
+func get(t *testing.T, transport http.RoundTripper, url string) error {
+	t.Helper()
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+	return err
+}
+
+func Test_CACertificates(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer ts.Close()
+
+	// The test server's certificate is not trusted by default
+	transport, err := Config{}.Transport()
+	require.NoError(t, err)
+	require.Error(t, get(t, transport, ts.URL))
+
+	transport, err = Config{CACertFile: writePEM(t, "CERTIFICATE", ts.Certificate().Raw)}.Transport()
+	require.NoError(t, err)
+	require.NoError(t, get(t, transport, ts.URL))
+}
+
+func Test_ClientCertificate(t *testing.T) {
+	certFile, keyFile, clientCert := newClientCertificate(t)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	ts.StartTLS()
+	defer ts.Close()
+	caFile := writePEM(t, "CERTIFICATE", ts.Certificate().Raw)
+
+	transport, err := Config{CACertFile: caFile}.Transport()
+	require.NoError(t, err)
+	require.Error(t, get(t, transport, ts.URL))
+
+	transport, err = Config{CACertFile: caFile, ClientCertFile: certFile, ClientKeyFile: keyFile}.Transport()
+	require.NoError(t, err)
+	require.NoError(t, get(t, transport, ts.URL))
+}
+
+func Test_Proxy(t *testing.T) {
+	transport, err := Config{ProxyURL: "http://proxy.example.com:3128", NoProxy: "ghes.internal,.corp.example.com"}.Transport()
+	require.NoError(t, err)
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://api.github.com/user", expected: "http://proxy.example.com:3128"},
+		{url: "https://ghes.internal/api/v3/user"},
+		{url: "https://raw.corp.example.com/_ping"},
+	}
+	for _, tc := range tests {
+		req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+		require.NoError(t, err)
+		proxyURL, err := transport.Proxy(req)
+		require.NoError(t, err)
+		if tc.expected == "" {
+			assert.Nil(t, proxyURL, tc.url)
+		} else {
+			require.NotNil(t, proxyURL, tc.url)
+			assert.Equal(t, tc.expected, proxyURL.String())
+		}
+	}
+}
+
+func Test_InvalidConfig(t *testing.T) {
+	certFile, _, _ := newClientCertificate(t)
+	notPEM := filepath.Join(t.TempDir(), "not.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0600))
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "proxy URL without scheme", config: Config{ProxyURL: "proxy.example.com"}},
+		{name: "no proxy without proxy", config: Config{NoProxy: "ghes.internal"}},
+		{name: "missing CA file", config: Config{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{name: "CA file without certificates", config: Config{CACertFile: notPEM}},
+		{name: "client certificate without key", config: Config{ClientCertFile: certFile}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.config.Transport()
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+// Package ratelimit provides an HTTP transport that keeps GitHub API requests within the rate limits.
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetaKey is the key of the rate limit budgets in the _meta of a tool result.
+const MetaKey = "github.com/rate_limit"
+
+const (
+	// DefaultMaxRetries is how often a request hitting a secondary rate limit is retried.
+	DefaultMaxRetries = 2
+	// DefaultMaxWait is the longest a request waits for a secondary rate limit to pass before failing.
+	DefaultMaxWait = time.Minute
+	// secondaryLimitWait is how long to wait after a secondary rate limit that doesn't say how long to wait for.
+	// See https://docs.github.com/en/rest/using-the-rest-api/best-practices-for-using-the-rest-api#handle-rate-limit-errors-appropriately
+	secondaryLimitWait = time.Minute
+	// lowBudgetFraction is the fraction of a budget below which a warning is logged.
+	lowBudgetFraction = 0.1
+)
+
+// Budget is the rate limit budget of a resource, as last reported by GitHub.
+type Budget struct {
+	// Resource is the rate limit resource (e.g. "core", "search" or "graphql")
+	Resource string `json:"resource"`
+	// Limit is the number of requests (or GraphQL points) allowed per window
+	Limit int `json:"limit"`
+	// Remaining is the number of requests left in the current window
+	Remaining int `json:"remaining"`
+	// Used is the number of requests made in the current window
+	Used int `json:"used"`
+	// Reset is when the current window ends
+	Reset time.Time `json:"reset"`
+}
+
+// ExhaustedError is returned instead of sending a request when the budget of its resource is used up.
+type ExhaustedError struct {
+	Budget Budget
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit for %s exhausted (%d requests), resets at %s",
+		e.Budget.Resource, e.Budget.Limit, e.Budget.Reset.Format(time.RFC3339))
+}
+
+// SecondaryLimitError is returned when GitHub asks us to back off for longer than we are willing to wait.
+type SecondaryLimitError struct {
+	// RetryAt is when requests may be sent again
+	RetryAt time.Time
+}
+
+func (e *SecondaryLimitError) Error() string {
+	return fmt.Sprintf("GitHub API secondary rate limit exceeded, retry after %s", e.RetryAt.Format(time.RFC3339))
+}
+
+// Transport tracks the rate limit budget reported in GitHub API responses. It fails requests whose budget
+// is used up without sending them, and waits out and retries secondary rate limits. A Transport tracks the
+// budget of a single token; when a request carries a different token, the known budgets are discarded.
+type Transport struct {
+	transport http.RoundTripper
+	logger    *slog.Logger
+
+	// MaxRetries is how often a request hitting a secondary rate limit is retried
+	MaxRetries int
+	// MaxWait is the longest a request waits for a secondary rate limit to pass
+	MaxWait time.Duration
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu       sync.Mutex
+	identity [sha256.Size]byte
+	budgets  map[string]Budget
+	// host is the API host the budgets were reported by
+	host string
+	// pausedUntil is set when GitHub asks us to back off after a secondary rate limit
+	pausedUntil time.Time
+	// warned records the resources a low budget warning was logged for, by the reset of that budget
+	warned map[string]time.Time
+}
+
+// NewTransport creates a rate limit aware transport sending requests through transport.
+func NewTransport(transport http.RoundTripper, logger *slog.Logger) *Transport {
+	return &Transport{
+		transport:  transport,
+		logger:     logger,
+		MaxRetries: DefaultMaxRetries,
+		MaxWait:    DefaultMaxWait,
+		now:        time.Now,
+		sleep:      sleepContext,
+		budgets:    make(map[string]Budget),
+		warned:     make(map[string]time.Time),
+	}
+}
+
+// Budgets returns the last known budget of each resource, ordered by resource.
+func (t *Transport) Budgets() []Budget {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	budgets := make([]Budget, 0, len(t.budgets))
+	for _, budget := range t.budgets {
+		budgets = append(budgets, budget)
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Resource < budgets[j].Resource })
+	return budgets
+}
+
+// Middleware returns receiving middleware that reports the budgets once a tool call completes: they
+// are attached to the _meta of the tool result under MetaKey, so that agents can pace themselves, and
+// logged at debug level.
+func (t *Transport) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			result, err := next(ctx, method, req)
+			budgets := t.Budgets()
+			if len(budgets) == 0 {
+				return result, err
+			}
+
+			attrs := []any{"tool", call.Params.Name}
+			for _, budget := range budgets {
+				attrs = append(attrs, budget.Resource, fmt.Sprintf("%d/%d", budget.Remaining, budget.Limit))
+			}
+			t.logger.DebugContext(ctx, "GitHub API rate limit budgets", attrs...)
+
+			if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult != nil {
+				if toolResult.Meta == nil {
+					toolResult.Meta = mcp.Meta{}
+				}
+				toolResult.Meta[MetaKey] = budgets
+			}
+			return result, err
+		}
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource := requestResource(req)
+
+	for attempt := 0; ; attempt++ {
+		if err := t.beforeRequest(req, resource); err != nil {
+			return nil, err
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			// The body was consumed by the previous attempt
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body for retry: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.transport.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		t.updateBudget(req, resp, resource)
+
+		retryAt, limited := t.secondaryLimit(resp)
+		if !limited {
+			return resp, nil
+		}
+
+		t.mu.Lock()
+		if retryAt.After(t.pausedUntil) {
+			t.pausedUntil = retryAt
+		}
+		t.mu.Unlock()
+
+		canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if !canRetry || attempt >= t.MaxRetries || retryAt.Sub(t.now()) > t.MaxWait {
+			return resp, nil
+		}
+
+		t.logger.Warn("secondary rate limit exceeded, retrying", "method", req.Method, "url", req.URL.Redacted(), "retryAt", retryAt, "attempt", attempt+1)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
+
+// beforeRequest fails the request if its budget is used up, and waits if a secondary rate limit is in effect.
+func (t *Transport) beforeRequest(req *http.Request, resource string) error {
+	t.mu.Lock()
+	identity := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	if identity != t.identity {
+		t.identity = identity
+		t.budgets = make(map[string]Budget)
+		t.warned = make(map[string]time.Time)
+		t.pausedUntil = time.Time{}
+	}
+	budget, known := t.budgets[resource]
+	sameHost := req.URL.Host == t.host
+	pausedUntil := t.pausedUntil
+	t.mu.Unlock()
+
+	now := t.now()
+	if known && sameHost && budget.Remaining <= 0 && now.Before(budget.Reset) {
+		return &ExhaustedError{Budget: budget}
+	}
+
+	if wait := pausedUntil.Sub(now); wait > 0 {
+		if wait > t.MaxWait {
+			return &SecondaryLimitError{RetryAt: pausedUntil}
+		}
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateBudget records the budget reported in the X-RateLimit-* headers of the response.
+func (t *Transport) updateBudget(req *http.Request, resp *http.Response, resource string) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		// Not an API response, or rate limiting is disabled (e.g. on some GHES instances)
+		return
+	}
+	limit, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	used, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Used"))
+	reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if r := resp.Header.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+
+	budget := Budget{
+		Resource:  resource,
+		Limit:     limit,
+		Remaining: remaining,
+		Used:      used,
+		Reset:     time.Unix(reset, 0),
+	}
+
+	t.mu.Lock()
+	t.budgets[resource] = budget
+	t.host = req.URL.Host
+	warn := limit > 0 && float64(remaining) < float64(limit)*lowBudgetFraction && !t.warned[resource].Equal(budget.Reset)
+	if warn {
+		t.warned[resource] = budget.Reset
+	}
+	t.mu.Unlock()
+
+	if warn {
+		t.logger.Warn("GitHub API rate limit budget running low", "resource", resource, "remaining", remaining, "limit", limit, "reset", budget.Reset)
+	}
+}
+
+// secondaryLimit reports whether the response is a secondary rate limit, and when to retry.
+// See https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api#exceeding-the-rate-limit
+func (t *Transport) secondaryLimit(resp *http.Response) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+
+	now := t.now()
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second), true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return date, true
+		}
+	}
+
+	// A primary rate limit: the budget is used up, which is reported to the caller as is
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return time.Time{}, false
+	}
+
+	// Other 403s (e.g. missing permissions) are not rate limits, GitHub mentions secondary limits in the body
+	if resp.StatusCode == http.StatusForbidden && !mentionsSecondaryLimit(resp) {
+		return time.Time{}, false
+	}
+	return now.Add(secondaryLimitWait), true
+}
+
+// mentionsSecondaryLimit checks the body of the response for a secondary rate limit message,
+// leaving the body readable for the caller.
+func mentionsSecondaryLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit"))
+}
+
+// requestResource guesses the rate limit resource a request counts against, before its response tells.
+func requestResource(req *http.Request) string {
+	path := req.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	case strings.Contains(path, "/search/code"):
+		return "code_search"
+	case strings.Contains(path, "/search/"):
+		return "search"
+	default:
+		return "core"
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
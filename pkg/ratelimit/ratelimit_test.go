@@ -0,0 +1,279 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Unix(1700000000, 0)
+
+// newTestTransport returns a transport with a fixed clock that records how long it sleeps.
+func newTestTransport() (*Transport, *[]time.Duration) {
+	var sleeps []time.Duration
+	transport := NewTransport(http.DefaultTransport, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	transport.now = func() time.Time { return now }
+	transport.sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return transport, &sleeps
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, resource string, remaining int) {
+	w.Header().Set("X-RateLimit-Limit", "5000")
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Used", strconv.Itoa(5000-remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+	w.Header().Set("X-RateLimit-Resource", resource)
+}
+
+func get(t *testing.T, client *http.Client, url string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := client.Do(req)
+	if err == nil {
+		t.Cleanup(func() { _ = resp.Body.Close() })
+	}
+	return resp, err
+}
+
+func Test_Transport_TracksBudgets(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			setRateLimitHeaders(w, "graphql", 4000)
+			return
+		}
+		setRateLimitHeaders(w, "core", 4999)
+	}))
+	defer ts.Close()
+
+	transport, _ := newTestTransport()
+	client := &http.Client{Transport: transport}
+
+	_, err := get(t, client, ts.URL+"/repos/owner/repo")
+	require.NoError(t, err)
+	_, err = get(t, client, ts.URL+"/graphql")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Budget{
+		{Resource: "core", Limit: 5000, Remaining: 4999, Used: 1, Reset: now.Add(time.Hour)},
+		{Resource: "graphql", Limit: 5000, Remaining: 4000, Used: 1000, Reset: now.Add(time.Hour)},
+	}, transport.Budgets())
+}
+
+func Test_Transport_Middleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		setRateLimitHeaders(w, "core", 4999)
+	}))
+	defer ts.Close()
+
+	transport, _ := newTestTransport()
+	client := &http.Client{Transport: transport}
+	handler := transport.Middleware()(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		_, err := get(t, client, ts.URL+"/repos/owner/repo")
+		require.NoError(t, err)
+		return &mcp.CallToolResult{}, nil
+	})
+
+	result, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "get_me"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Budget{
+		{Resource: "core", Limit: 5000, Remaining: 4999, Used: 1, Reset: now.Add(time.Hour)},
+	}, result.(*mcp.CallToolResult).Meta[MetaKey])
+}
+
+func Test_Transport_FailsFastWhenExhausted(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		resource := "core"
+		if strings.HasPrefix(r.URL.Path, "/search/") {
+			resource = "search"
+		}
+		setRateLimitHeaders(w, resource, 0)
+	}))
+	defer ts.Close()
+
+	transport, _ := newTestTransport()
+	client := &http.Client{Transport: transport}
+
+	// The first request learns that the budget is used up
+	_, err := get(t, client, ts.URL+"/repos/owner/repo")
+	require.NoError(t, err)
+
+	_, err = get(t, client, ts.URL+"/repos/owner/repo/issues")
+	var exhausted *ExhaustedError
+	require.ErrorAs(t, err, &exhausted)
+	assert.Equal(t, "core", exhausted.Budget.Resource)
+	assert.Equal(t, now.Add(time.Hour), exhausted.Budget.Reset)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Other resources have their own budget
+	_, err = get(t, client, ts.URL+"/search/issues")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// Once the window has reset, requests are sent again
+	transport.now = func() time.Time { return now.Add(2 * time.Hour) }
+	_, err = get(t, client, ts.URL+"/repos/owner/repo/issues")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func Test_Transport_ForgetsBudgetsOfOtherTokens(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		setRateLimitHeaders(w, "core", 0)
+	}))
+	defer ts.Close()
+
+	transport, _ := newTestTransport()
+	client := &http.Client{Transport: transport}
+
+	_, err := get(t, client, ts.URL+"/user")
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/user", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer other-token")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+}
+
+func Test_Transport_SecondaryRateLimits(t *testing.T) {
+	t.Run("waits and retries", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, `{"title":"bug"}`, string(body))
+			if requests.Add(1) == 1 {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer ts.Close()
+
+		transport, sleeps := newTestTransport()
+		resp, err := (&http.Client{Transport: transport}).Post(ts.URL+"/repos/owner/repo/issues", "application/json", strings.NewReader(`{"title":"bug"}`))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, int32(2), requests.Load())
+		assert.Equal(t, []time.Duration{30 * time.Second}, *sleeps)
+	})
+
+	t.Run("waits a minute when GitHub does not say how long", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`))
+			}
+		}))
+		defer ts.Close()
+
+		transport, sleeps := newTestTransport()
+		resp, err := get(t, &http.Client{Transport: transport}, ts.URL+"/search/code")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []time.Duration{time.Minute}, *sleeps)
+	})
+
+	t.Run("fails fast when the wait is too long", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Retry-After", "600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer ts.Close()
+
+		transport, sleeps := newTestTransport()
+		client := &http.Client{Transport: transport}
+
+		// The limited response is handed to the caller
+		resp, err := get(t, client, ts.URL+"/user")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+		// Later requests are not sent until the limit has passed
+		_, err = get(t, client, ts.URL+"/user")
+		var secondary *SecondaryLimitError
+		require.ErrorAs(t, err, &secondary)
+		assert.Equal(t, now.Add(10*time.Minute), secondary.RetryAt)
+		assert.Equal(t, int32(1), requests.Load())
+		assert.Empty(t, *sleeps)
+	})
+
+	t.Run("gives up after the maximum retries", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer ts.Close()
+
+		transport, _ := newTestTransport()
+		resp, err := get(t, &http.Client{Transport: transport}, ts.URL+"/user")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, int32(DefaultMaxRetries+1), requests.Load())
+	})
+
+	t.Run("other forbidden responses are passed through", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+		}))
+		defer ts.Close()
+
+		transport, sleeps := newTestTransport()
+		resp, err := get(t, &http.Client{Transport: transport}, ts.URL+"/user")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "Resource not accessible")
+		assert.Equal(t, int32(1), requests.Load())
+		assert.Empty(t, *sleeps)
+	})
+
+	t.Run("stops waiting when the request is cancelled", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer ts.Close()
+
+		transport := NewTransport(http.DefaultTransport, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/user", nil)
+		require.NoError(t, err)
+
+		_, err = (&http.Client{Transport: transport}).Do(req)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
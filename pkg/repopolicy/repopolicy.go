@@ -0,0 +1,320 @@
+// Package repopolicy confines tool calls to the repositories allowed by a policy. Calls are checked
+// against the repositories and owners their arguments and search queries name before they are
+// handled, and the items of other repositories are filtered out of their results.
+package repopolicy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ReadOnlySuffix marks an allowed pattern whose repositories can only be read, e.g. my-org/prod:read-only.
+const ReadOnlySuffix = ":read-only"
+
+// Access is what the policy allows for a repository or owner.
+type Access int
+
+const (
+	// AccessNone denies all calls
+	AccessNone Access = iota
+	// AccessRead allows calls to read-only tools
+	AccessRead
+	// AccessWrite allows all calls
+	AccessWrite
+)
+
+// rule is a parsed owner/repo pattern.
+type rule struct {
+	owner    string
+	repo     string
+	readOnly bool
+}
+
+func (r rule) matchesOwner(owner string) bool {
+	matched, _ := path.Match(r.owner, owner)
+	return matched
+}
+
+func (r rule) matchesRepo(owner, repo string) bool {
+	matched, _ := path.Match(r.repo, repo)
+	return matched && r.matchesOwner(owner)
+}
+
+// Policy tells which repositories tools can access. Repositories matching a denied pattern can't be
+// accessed. If there are allowed patterns, only repositories matching one of them can be accessed,
+// and only read if any of the matching patterns is read-only. Matching ignores case.
+type Policy struct {
+	allow []rule
+	deny  []rule
+}
+
+// New parses the allowed and denied owner/repo patterns, which use the syntax of path.Match, where *
+// matches any sequence of characters other than /. Allowed patterns can end with ReadOnlySuffix.
+func New(allow, deny []string) (*Policy, error) {
+	p := &Policy{}
+	for _, pattern := range allow {
+		r, err := parseRule(pattern)
+		if err != nil {
+			return nil, err
+		}
+		p.allow = append(p.allow, r)
+	}
+	for _, pattern := range deny {
+		r, err := parseRule(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if r.readOnly {
+			return nil, fmt.Errorf("invalid repository pattern %q: %s only applies to allowed repositories", pattern, ReadOnlySuffix)
+		}
+		p.deny = append(p.deny, r)
+	}
+	return p, nil
+}
+
+func parseRule(pattern string) (rule, error) {
+	trimmed, readOnly := strings.CutSuffix(strings.ToLower(strings.TrimSpace(pattern)), ReadOnlySuffix)
+	owner, repo, ok := strings.Cut(trimmed, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return rule{}, fmt.Errorf("invalid repository pattern %q: must be owner/repo", pattern)
+	}
+	if _, err := path.Match(trimmed, ""); err != nil {
+		return rule{}, fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+	}
+	return rule{owner: owner, repo: repo, readOnly: readOnly}, nil
+}
+
+// RepoAccess returns what the policy allows for the repository owner/repo.
+func (p *Policy) RepoAccess(owner, repo string) Access {
+	owner, repo = strings.ToLower(owner), strings.ToLower(repo)
+	for _, r := range p.deny {
+		if r.matchesRepo(owner, repo) {
+			return AccessNone
+		}
+	}
+	if len(p.allow) == 0 {
+		return AccessWrite
+	}
+
+	access := AccessNone
+	for _, r := range p.allow {
+		if !r.matchesRepo(owner, repo) {
+			continue
+		}
+		if r.readOnly {
+			return AccessRead
+		}
+		access = AccessWrite
+	}
+	return access
+}
+
+// OwnerAccess returns what the policy allows for calls that name an owner but no repository, which is
+// the most it allows for any of the repositories of the owner.
+func (p *Policy) OwnerAccess(owner string) Access {
+	owner = strings.ToLower(owner)
+	for _, r := range p.deny {
+		if r.repo == "*" && r.matchesOwner(owner) {
+			return AccessNone
+		}
+	}
+	if len(p.allow) == 0 {
+		return AccessWrite
+	}
+
+	access := AccessNone
+	for _, r := range p.allow {
+		if !r.matchesOwner(owner) {
+			continue
+		}
+		if !r.readOnly {
+			return AccessWrite
+		}
+		access = AccessRead
+	}
+	return access
+}
+
+// IsWriteToolFunc reports whether a tool can change data, and thus needs write access.
+type IsWriteToolFunc func(tool string) bool
+
+// Middleware returns receiving middleware that checks every tool call against the policy before it is
+// handled, and filters the items of repositories that can't be accessed out of its result. Denied
+// calls get an error result that tells why.
+func (p *Policy) Middleware(isWriteTool IsWriteToolFunc, logger *slog.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			if denial := p.check(call.Params.Name, call.Params.Arguments, isWriteTool(call.Params.Name)); denial != "" {
+				logger.InfoContext(ctx, "tool call denied by repository policy", "tool", call.Params.Name, "reason", denial)
+				return utils.NewToolResultError(denial), nil
+			}
+
+			result, err := next(ctx, method, req)
+			if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult != nil && !toolResult.IsError {
+				if filtered := p.filterResult(toolResult); filtered > 0 {
+					logger.DebugContext(ctx, "items filtered by repository policy", "tool", call.Params.Name, "count", filtered)
+				}
+			}
+			return result, err
+		}
+	}
+}
+
+// qualifierPattern matches the repo:, org: and user: qualifiers of search queries, but not their
+// negations, which exclude rather than select.
+var qualifierPattern = regexp.MustCompile(`(?:^|[\s(])(repo|org|user):"?([^\s")]+)`)
+
+// check returns why the call is denied, or an empty string if it is allowed.
+func (p *Policy) check(tool string, arguments json.RawMessage, write bool) string {
+	// Arguments that can't be checked could name any repository
+	var args map[string]any
+	if len(arguments) > 0 && json.Unmarshal(arguments, &args) != nil {
+		return fmt.Sprintf("the arguments of %s can't be checked against the repository policy, so the call was denied", tool)
+	}
+	stringArg := func(name string) string {
+		value, _ := args[name].(string)
+		return value
+	}
+
+	var repos [][2]string
+	var owners []string
+	if owner, repo := stringArg("owner"), stringArg("repo"); owner != "" && repo != "" {
+		repos = append(repos, [2]string{owner, repo})
+	} else if owner != "" {
+		owners = append(owners, owner)
+	}
+	for _, name := range []string{"org", "organization"} {
+		if org := stringArg(name); org != "" {
+			owners = append(owners, org)
+		}
+	}
+	for _, match := range qualifierPattern.FindAllStringSubmatch(stringArg("query"), -1) {
+		if match[1] != "repo" {
+			owners = append(owners, match[2])
+			continue
+		}
+		if owner, repo, ok := strings.Cut(match[2], "/"); ok {
+			repos = append(repos, [2]string{owner, repo})
+		}
+	}
+
+	for _, repo := range repos {
+		switch access := p.RepoAccess(repo[0], repo[1]); {
+		case access == AccessNone:
+			return fmt.Sprintf("access to %s/%s is denied by the repository policy", repo[0], repo[1])
+		case access == AccessRead && write:
+			return fmt.Sprintf("%s/%s is read-only under the repository policy, so %s can't be used on it", repo[0], repo[1], tool)
+		}
+	}
+	for _, owner := range owners {
+		switch access := p.OwnerAccess(owner); {
+		case access == AccessNone:
+			return fmt.Sprintf("access to %s is denied by the repository policy", owner)
+		case access == AccessRead && write:
+			return fmt.Sprintf("the repositories of %s are read-only under the repository policy, so %s can't be used on them", owner, tool)
+		}
+	}
+	return ""
+}
+
+// filterResult removes the items of repositories that can't be accessed from the JSON results of a
+// call: the "items" of search results, whose "total_count" is reduced accordingly, and the elements of
+// lists. Items tell their repository with a
+// "repository" object, their own "full_name" if they are repositories, or a "repository_url". It returns
+// the number of items removed.
+func (p *Policy) filterResult(result *mcp.CallToolResult) int {
+	removed := 0
+	for _, content := range result.Content {
+		text, ok := content.(*mcp.TextContent)
+		if !ok {
+			continue
+		}
+		var value any
+		decoder := json.NewDecoder(strings.NewReader(text.Text))
+		// Keep numbers as they are, IDs can exceed the precision of float64
+		decoder.UseNumber()
+		if decoder.Decode(&value) != nil {
+			continue
+		}
+
+		var n int
+		switch v := value.(type) {
+		case []any:
+			value, n = p.filterItems(v)
+		case map[string]any:
+			items, ok := v["items"].([]any)
+			if !ok {
+				continue
+			}
+			v["items"], n = p.filterItems(items)
+			// The count of a search would otherwise tell how many items were hidden
+			if total, ok := v["total_count"].(json.Number); ok && n > 0 {
+				if count, err := total.Int64(); err == nil {
+					v["total_count"] = max(count-int64(n), 0)
+				}
+			}
+		}
+		if n == 0 {
+			continue
+		}
+
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if encoder.Encode(value) != nil {
+			continue
+		}
+		text.Text = strings.TrimSuffix(buf.String(), "\n")
+		removed += n
+	}
+	return removed
+}
+
+func (p *Policy) filterItems(items []any) ([]any, int) {
+	kept := make([]any, 0, len(items))
+	for _, item := range items {
+		object, ok := item.(map[string]any)
+		if !ok {
+			kept = append(kept, item)
+			continue
+		}
+		if owner, repo, ok := itemRepo(object); ok && p.RepoAccess(owner, repo) == AccessNone {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept, len(items) - len(kept)
+}
+
+func itemRepo(item map[string]any) (string, string, bool) {
+	var fullName string
+	if repository, ok := item["repository"].(map[string]any); ok {
+		fullName, _ = repository["full_name"].(string)
+	} else if name, ok := item["full_name"].(string); ok {
+		fullName = name
+	} else if url, ok := item["repository_url"].(string); ok {
+		if i := strings.LastIndex(url, "/repos/"); i >= 0 {
+			fullName = url[i+len("/repos/"):]
+		}
+	}
+
+	owner, repo, ok := strings.Cut(fullName, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", false
+	}
+	return owner, repo, true
+}
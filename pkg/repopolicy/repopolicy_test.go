@@ -0,0 +1,168 @@
+package repopolicy
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New(t *testing.T) {
+	_, err := New([]string{"my-org/*", "Other/Repo" + ReadOnlySuffix}, []string{"my-org/secret-*"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		allow         []string
+		deny          []string
+		expectedError string
+	}{
+		{name: "no repo", allow: []string{"my-org"}, expectedError: `invalid repository pattern "my-org": must be owner/repo`},
+		{name: "nested path", allow: []string{"my-org/repo/sub"}, expectedError: `invalid repository pattern "my-org/repo/sub": must be owner/repo`},
+		{name: "empty owner", deny: []string{"/repo"}, expectedError: `invalid repository pattern "/repo": must be owner/repo`},
+		{name: "malformed", allow: []string{"my-org/[repo"}, expectedError: `invalid repository pattern "my-org/[repo": syntax error in pattern`},
+		{name: "read-only denial", deny: []string{"my-org/*:read-only"}, expectedError: `invalid repository pattern "my-org/*:read-only": :read-only only applies to allowed repositories`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.allow, tc.deny)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func Test_Access(t *testing.T) {
+	policy, err := New([]string{"my-org/*", "my-org/prod" + ReadOnlySuffix, "partner/docs" + ReadOnlySuffix}, []string{"my-org/secret-*", "banned/*"})
+	require.NoError(t, err)
+
+	assert.Equal(t, AccessWrite, policy.RepoAccess("my-org", "app"))
+	assert.Equal(t, AccessWrite, policy.RepoAccess("My-Org", "App"), "matching ignores case")
+	assert.Equal(t, AccessRead, policy.RepoAccess("my-org", "prod"), "read-only wins over other allowed patterns")
+	assert.Equal(t, AccessNone, policy.RepoAccess("my-org", "secret-keys"), "denied patterns win over allowed ones")
+	assert.Equal(t, AccessNone, policy.RepoAccess("octo", "hello"), "repositories that are not allowed are denied")
+
+	assert.Equal(t, AccessWrite, policy.OwnerAccess("my-org"))
+	assert.Equal(t, AccessRead, policy.OwnerAccess("partner"))
+	assert.Equal(t, AccessNone, policy.OwnerAccess("octo"))
+	assert.Equal(t, AccessNone, policy.OwnerAccess("banned"))
+
+	denyOnly, err := New(nil, []string{"my-org/secret-*"})
+	require.NoError(t, err)
+	assert.Equal(t, AccessWrite, denyOnly.RepoAccess("octo", "hello"), "without allowed patterns everything not denied is allowed")
+	assert.Equal(t, AccessWrite, denyOnly.OwnerAccess("my-org"), "an owner is only denied by a pattern for all of its repositories")
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}
+}
+
+func Test_Middleware(t *testing.T) {
+	policy, err := New([]string{"my-org/*", "my-org/prod" + ReadOnlySuffix}, []string{"my-org/secret"})
+	require.NoError(t, err)
+
+	middleware := policy.Middleware(func(tool string) bool { return tool == "issue_write" }, slog.New(slog.DiscardHandler))
+	call := func(tool, arguments, response string) (*mcp.CallToolResult, bool) {
+		handled := false
+		result, err := middleware(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+			handled = true
+			return textResult(response), nil
+		})(context.Background(), "tools/call", &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: tool, Arguments: json.RawMessage(arguments)},
+		})
+		require.NoError(t, err)
+		return result.(*mcp.CallToolResult), handled
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	tests := []struct {
+		name           string
+		tool           string
+		arguments      string
+		expectedDenial string
+	}{
+		{name: "allowed repository", tool: "issue_write", arguments: `{"owner":"my-org","repo":"app"}`},
+		{name: "read of read-only repository", tool: "issue_read", arguments: `{"owner":"my-org","repo":"prod"}`},
+		{name: "no repository", tool: "get_me", arguments: `{}`},
+		{name: "search without qualifiers", tool: "search_code", arguments: `{"query":"func main"}`},
+		{name: "negated qualifier", tool: "search_code", arguments: `{"query":"func main -repo:octo/hello"}`},
+		{
+			name:           "repository not allowed",
+			tool:           "issue_read",
+			arguments:      `{"owner":"octo","repo":"hello"}`,
+			expectedDenial: "access to octo/hello is denied by the repository policy",
+		},
+		{
+			name:           "denied repository",
+			tool:           "issue_read",
+			arguments:      `{"owner":"my-org","repo":"secret"}`,
+			expectedDenial: "access to my-org/secret is denied by the repository policy",
+		},
+		{
+			name:           "write to read-only repository",
+			tool:           "issue_write",
+			arguments:      `{"owner":"my-org","repo":"prod"}`,
+			expectedDenial: "my-org/prod is read-only under the repository policy, so issue_write can't be used on it",
+		},
+		{
+			name:           "owner not allowed",
+			tool:           "list_org_repository_security_advisories",
+			arguments:      `{"org":"octo"}`,
+			expectedDenial: "access to octo is denied by the repository policy",
+		},
+		{
+			name:           "repo qualifier",
+			tool:           "search_issues",
+			arguments:      `{"query":"is:open (repo:my-org/app OR repo:octo/hello)"}`,
+			expectedDenial: "access to octo/hello is denied by the repository policy",
+		},
+		{
+			name:           "user qualifier",
+			tool:           "search_code",
+			arguments:      `{"query":"user:\"octo\" func main"}`,
+			expectedDenial: "access to octo is denied by the repository policy",
+		},
+		{
+			name:           "arguments that can't be checked",
+			tool:           "issue_read",
+			arguments:      `["my-org","secret"]`,
+			expectedDenial: "the arguments of issue_read can't be checked against the repository policy, so the call was denied",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, handled := call(tc.tool, tc.arguments, `{}`)
+			if tc.expectedDenial == "" {
+				assert.True(t, handled)
+				assert.False(t, result.IsError)
+				return
+			}
+			assert.False(t, handled, "denied calls must not be handled")
+			assert.True(t, result.IsError)
+			assert.Equal(t, tc.expectedDenial, text(result))
+		})
+	}
+
+	t.Run("search results are filtered", func(t *testing.T) {
+		result, _ := call("search_issues", `{"query":"bug"}`, `{"total_count":3,"items":[`+
+			`{"id":12345678901234567,"repository_url":"https://api.github.com/repos/my-org/app"},`+
+			`{"id":2,"repository_url":"https://ghes.example.com/api/v3/repos/octo/hello"},`+
+			`{"id":3,"repository_url":"https://api.github.com/repos/my-org/secret"}]}`)
+		assert.JSONEq(t, `{"total_count":1,"items":[{"id":12345678901234567,"repository_url":"https://api.github.com/repos/my-org/app"}]}`, text(result))
+
+		result, _ = call("search_code", `{"query":"main"}`, `{"items":[{"path":"a.go","repository":{"full_name":"octo/hello"}},{"path":"b.go","repository":{"full_name":"my-org/prod"}}]}`)
+		assert.JSONEq(t, `{"items":[{"path":"b.go","repository":{"full_name":"my-org/prod"}}]}`, text(result))
+
+		result, _ = call("list_starred_repositories", `{}`, `[{"full_name":"octo/hello"},{"full_name":"my-org/app"},{"name":"unknown"}]`)
+		assert.JSONEq(t, `[{"full_name":"my-org/app"},{"name":"unknown"}]`, text(result))
+
+		response := `{"items": [{"full_name": "my-org/app"}]}`
+		result, _ = call("search_repositories", `{"query":"app"}`, response)
+		assert.Equal(t, response, text(result), "results without items to filter are left as they are")
+	})
+}
@@ -0,0 +1,186 @@
+// Package retry provides an HTTP transport that retries GitHub API requests failing for transient reasons.
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is how often a request is retried before its last result is returned.
+	DefaultMaxRetries = 3
+	// DefaultBaseDelay is the wait before the first retry, which doubles with every further retry.
+	DefaultBaseDelay = 500 * time.Millisecond
+	// DefaultMaxDelay caps the wait between retries.
+	DefaultMaxDelay = 10 * time.Second
+)
+
+// Transport retries requests that fail with a 5xx response, a reset connection or a timeout, waiting
+// exponentially longer (with jitter) between attempts. Only requests that are safe to repeat are
+// retried: GET, HEAD and OPTIONS requests and GraphQL queries. Other requests are only retried when
+// RetryMutations is set, as a mutation may have taken effect before the failure.
+type Transport struct {
+	transport http.RoundTripper
+	logger    *slog.Logger
+
+	// MaxRetries is how often a request is retried
+	MaxRetries int
+	// BaseDelay is the wait before the first retry
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between retries
+	MaxDelay time.Duration
+	// RetryMutations also retries requests that may change data
+	RetryMutations bool
+	// OnRetry is called before every retry, e.g. to count retries
+	OnRetry func()
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewTransport creates a retrying transport sending requests through transport.
+func NewTransport(transport http.RoundTripper, logger *slog.Logger) *Transport {
+	return &Transport{
+		transport:  transport,
+		logger:     logger,
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+		sleep:      sleepContext,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.retryable(req) {
+		return t.transport.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			// The body was consumed by the previous attempt
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body for retry: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.transport.RoundTrip(req)
+		if attempt >= t.MaxRetries || !transient(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		attrs := []any{"method", req.Method, "url", req.URL.Redacted(), "attempt", attempt + 1, "wait", wait}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		} else {
+			attrs = append(attrs, "status", resp.StatusCode)
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		if t.OnRetry != nil {
+			t.OnRetry()
+		}
+		t.logger.Warn("retrying GitHub API request after transient failure", attrs...)
+
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether the request may be sent more than once.
+func (t *Transport) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// The body can't be sent again
+		return false
+	}
+	if t.RetryMutations {
+		return true
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return strings.HasSuffix(req.URL.Path, "/graphql") && isGraphQLQuery(req)
+	default:
+		return false
+	}
+}
+
+// isGraphQLQuery reports whether the GraphQL request is a query, as opposed to a mutation or subscription.
+func isGraphQLQuery(req *http.Request) bool {
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	defer func() { _ = body.Close() }()
+
+	var payload struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(body).Decode(&payload); err != nil {
+		return false
+	}
+	query := strings.TrimSpace(payload.Query)
+	return strings.HasPrefix(query, "{") || strings.HasPrefix(query, "query")
+}
+
+// transient reports whether the request failed for a reason that may go away when it is sent again.
+func transient(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if req.Context().Err() != nil {
+			// The caller gave up
+			return false
+		}
+		var netErr net.Error
+		return errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, io.EOF) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			(errors.As(err, &netErr) && netErr.Timeout())
+	}
+
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the wait before retry number attempt+1: the base delay doubled for every earlier
+// retry, capped at the maximum delay, and then randomly shortened by up to half so that clients
+// failing at the same time don't retry in lockstep.
+func (t *Transport) backoff(attempt int) time.Duration {
+	wait := t.BaseDelay << attempt
+	if wait > t.MaxDelay || wait <= 0 {
+		wait = t.MaxDelay
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,207 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer fails the first failures requests with fail, and then answers with "ok".
+func flakyServer(t *testing.T, failures int32, fail func(w http.ResponseWriter)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			body, _ := io.ReadAll(r.Body)
+			if r.Method == http.MethodPost {
+				assert.NotEmpty(t, body, "the body is sent with every attempt")
+			}
+		}
+		if requests.Add(1) <= failures {
+			fail(w)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &requests
+}
+
+func failWithStatus(status int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(status)
+	}
+}
+
+// resetConnection closes the connection without a response.
+func resetConnection(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+}
+
+func newTestTransport() (*Transport, *[]time.Duration) {
+	var waits []time.Duration
+	transport := NewTransport(http.DefaultTransport, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	transport.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return transport, &waits
+}
+
+func Test_Transport(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		body             string
+		retryMutations   bool
+		failures         int32
+		fail             func(w http.ResponseWriter)
+		expectedStatus   int
+		expectedRequests int32
+	}{
+		{
+			name:             "GET succeeds after server errors",
+			method:           http.MethodGet,
+			path:             "/repos/owner/repo",
+			failures:         2,
+			fail:             failWithStatus(http.StatusBadGateway),
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 3,
+		},
+		{
+			name:             "GET succeeds after a reset connection",
+			method:           http.MethodGet,
+			path:             "/repos/owner/repo",
+			failures:         1,
+			fail:             resetConnection,
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 2,
+		},
+		{
+			name:             "GET gives up after the maximum retries",
+			method:           http.MethodGet,
+			path:             "/repos/owner/repo",
+			failures:         10,
+			fail:             failWithStatus(http.StatusServiceUnavailable),
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedRequests: DefaultMaxRetries + 1,
+		},
+		{
+			name:             "client errors are not retried",
+			method:           http.MethodGet,
+			path:             "/repos/owner/repo",
+			failures:         1,
+			fail:             failWithStatus(http.StatusNotFound),
+			expectedStatus:   http.StatusNotFound,
+			expectedRequests: 1,
+		},
+		{
+			name:             "GraphQL queries are retried",
+			method:           http.MethodPost,
+			path:             "/graphql",
+			body:             `{"query":"query($owner:String!){repository(owner:$owner){id}}"}`,
+			failures:         1,
+			fail:             failWithStatus(http.StatusBadGateway),
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 2,
+		},
+		{
+			name:             "GraphQL mutations are not retried",
+			method:           http.MethodPost,
+			path:             "/graphql",
+			body:             `{"query":"mutation($input:AddCommentInput!){addComment(input:$input){clientMutationId}}"}`,
+			failures:         1,
+			fail:             failWithStatus(http.StatusBadGateway),
+			expectedStatus:   http.StatusBadGateway,
+			expectedRequests: 1,
+		},
+		{
+			name:             "REST mutations are not retried",
+			method:           http.MethodPost,
+			path:             "/repos/owner/repo/issues",
+			body:             `{"title":"bug"}`,
+			failures:         1,
+			fail:             failWithStatus(http.StatusBadGateway),
+			expectedStatus:   http.StatusBadGateway,
+			expectedRequests: 1,
+		},
+		{
+			name:             "REST mutations are retried when opted in",
+			method:           http.MethodPost,
+			path:             "/repos/owner/repo/issues",
+			body:             `{"title":"bug"}`,
+			retryMutations:   true,
+			failures:         1,
+			fail:             failWithStatus(http.StatusBadGateway),
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, requests := flakyServer(t, tc.failures, tc.fail)
+			transport, waits := newTestTransport()
+			transport.RetryMutations = tc.retryMutations
+			var retries int32
+			transport.OnRetry = func() { retries++ }
+
+			var body io.Reader
+			if tc.body != "" {
+				body = strings.NewReader(tc.body)
+			}
+			req, err := http.NewRequest(tc.method, ts.URL+tc.path, body)
+			require.NoError(t, err)
+
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tc.expectedRequests, requests.Load())
+			assert.Equal(t, tc.expectedRequests-1, retries)
+			assert.Len(t, *waits, int(tc.expectedRequests-1))
+		})
+	}
+}
+
+func Test_Transport_Timeout(t *testing.T) {
+	ts, requests := flakyServer(t, 1, func(_ http.ResponseWriter) {
+		time.Sleep(200 * time.Millisecond)
+	})
+
+	transport, _ := newTestTransport()
+	transport.transport = &http.Transport{ResponseHeaderTimeout: 50 * time.Millisecond}
+
+	resp, err := (&http.Client{Transport: transport}).Get(ts.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func Test_Transport_Backoff(t *testing.T) {
+	transport := NewTransport(http.DefaultTransport, slog.New(slog.DiscardHandler))
+
+	for attempt, maxWait := range []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		for range 20 {
+			wait := transport.backoff(attempt)
+			assert.GreaterOrEqual(t, wait, maxWait/2)
+			assert.LessOrEqual(t, wait, maxWait)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package scopes knows which classic OAuth scopes imply others, for the tools to tell whether a token
+// can use them and for the errors to tell whether it lacks the scopes a request needs.
+// See https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/scopes-for-oauth-apps
+package scopes
+
+// implied maps a classic scope to the scopes it grants in addition to itself.
+var implied = map[string][]string{
+	"repo":                      {"public_repo", "repo:status", "repo_deployment", "repo:invite", "security_events", "notifications"},
+	"admin:org":                 {"write:org", "read:org"},
+	"write:org":                 {"read:org"},
+	"project":                   {"read:project"},
+	"user":                      {"read:user", "user:email", "user:follow"},
+	"write:packages":            {"read:packages"},
+	"write:discussion":          {"read:discussion"},
+	"admin:repo_hook":           {"write:repo_hook", "read:repo_hook"},
+	"write:repo_hook":           {"read:repo_hook"},
+	"admin:public_key":          {"write:public_key", "read:public_key"},
+	"write:public_key":          {"read:public_key"},
+	"admin:gpg_key":             {"write:gpg_key", "read:gpg_key"},
+	"write:gpg_key":             {"read:gpg_key"},
+	"admin:ssh_signing_key":     {"write:ssh_signing_key", "read:ssh_signing_key"},
+	"admin:enterprise":          {"manage_runners:enterprise", "manage_billing:enterprise", "read:enterprise"},
+	"manage_billing:enterprise": {"read:enterprise"},
+	"codespace":                 {"codespace:secrets"},
+}
+
+// Expand returns the granted scopes together with all the scopes they imply.
+func Expand(granted []string) map[string]bool {
+	expanded := make(map[string]bool)
+	var add func(scope string)
+	add = func(scope string) {
+		if expanded[scope] {
+			return
+		}
+		expanded[scope] = true
+		for _, scope := range implied[scope] {
+			add(scope)
+		}
+	}
+	for _, scope := range granted {
+		add(scope)
+	}
+	return expanded
+}
@@ -0,0 +1,18 @@
+package scopes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Expand(t *testing.T) {
+	expanded := Expand([]string{"repo", "admin:org"})
+	for _, scope := range []string{"repo", "public_repo", "repo:status", "repo_deployment", "security_events", "notifications", "admin:org", "write:org", "read:org"} {
+		assert.True(t, expanded[scope], "%s should be granted", scope)
+	}
+	assert.False(t, expanded["gist"])
+
+	assert.False(t, Expand([]string{"read:project"})["project"], "narrower scopes don't imply broader ones")
+	assert.Empty(t, Expand(nil))
+}
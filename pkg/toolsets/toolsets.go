@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -35,6 +36,24 @@ func NewToolsetDoesNotExistError(name string) *ToolsetDoesNotExistError {
 type ServerTool struct {
 	Tool         mcp.Tool
 	RegisterFunc func(s *mcp.Server)
+	// Requirements are what a token needs to be able to use the tool
+	Requirements TokenRequirements
+}
+
+// TokenRequirements describe what a GitHub token needs to be able to use a tool.
+type TokenRequirements struct {
+	// Scopes are the classic OAuth scopes that let a token use the tool. Having any one of them,
+	// or a scope that implies it, is enough. Empty if the tool needs no scope.
+	Scopes []string
+	// Permissions are the fine-grained permissions the token needs, all of them, mapped to
+	// the access level ("read" or "write") that is needed.
+	Permissions map[string]string
+}
+
+// WithRequirements declares what a token needs to be able to use the tool.
+func (t ServerTool) WithRequirements(requirements TokenRequirements) ServerTool {
+	t.Requirements = requirements
+	return t
 }
 
 func NewServerTool[In any, Out any](tool mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) ServerTool {
@@ -195,6 +214,10 @@ type ToolsetGroup struct {
 	Toolsets     map[string]*Toolset
 	everythingOn bool
 	readOnly     bool
+	// removedTools maps the names of tools removed by RemoveTools to the reason they were removed
+	removedTools map[string]string
+	// excludedPatterns are the names and glob patterns of the tools that are never registered, see ExcludeTools
+	excludedPatterns []string
 }
 
 func NewToolsetGroup(readOnly bool) *ToolsetGroup {
@@ -202,9 +225,78 @@ func NewToolsetGroup(readOnly bool) *ToolsetGroup {
 		Toolsets:     make(map[string]*Toolset),
 		everythingOn: false,
 		readOnly:     readOnly,
+		removedTools: make(map[string]string),
 	}
 }
 
+// RemoveTools removes every tool for which remove returns true from all toolsets in the group, so that it
+// is never registered, whether through its toolset, by name or dynamically. It returns the removed tool
+// names mapped to the reasons given by remove.
+func (tg *ToolsetGroup) RemoveTools(remove func(tool ServerTool) (bool, string)) map[string]string {
+	removed := make(map[string]string)
+	filter := func(tools []ServerTool) []ServerTool {
+		var kept []ServerTool
+		for _, tool := range tools {
+			if ok, reason := remove(tool); ok {
+				removed[tool.Tool.Name] = reason
+				continue
+			}
+			kept = append(kept, tool)
+		}
+		return kept
+	}
+
+	for _, toolset := range tg.Toolsets {
+		toolset.readTools = filter(toolset.readTools)
+		toolset.writeTools = filter(toolset.writeTools)
+	}
+
+	for name, reason := range removed {
+		tg.removedTools[name] = reason
+	}
+	return removed
+}
+
+// ValidateToolPatterns returns an error if any of the tool name patterns is malformed. Patterns use the
+// syntax of path.Match, where * matches any sequence of characters and ? matches any single character.
+func ValidateToolPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// ExcludeTools makes sure that the tools whose names match any of the patterns, exact names or globs
+// such as delete_*, are never registered: they are removed from all toolsets in the group, and registering
+// through RegisterAll, RegisterSpecificTools or RegisterToolsetTools skips them, including tools added to
+// the group later. It returns the removed tool names mapped to the reasons they were removed.
+func (tg *ToolsetGroup) ExcludeTools(patterns []string) (map[string]string, error) {
+	if err := ValidateToolPatterns(patterns); err != nil {
+		return nil, err
+	}
+	tg.excludedPatterns = append(tg.excludedPatterns, patterns...)
+
+	return tg.RemoveTools(func(tool ServerTool) (bool, string) {
+		return tg.exclusionReason(tool.Tool.Name)
+	}), nil
+}
+
+// exclusionReason tells whether the named tool is excluded, and why.
+func (tg *ToolsetGroup) exclusionReason(name string) (bool, string) {
+	for _, pattern := range tg.excludedPatterns {
+		// The patterns were validated by ExcludeTools
+		if matched, _ := path.Match(pattern, name); matched {
+			if pattern == name {
+				return true, "excluded"
+			}
+			return true, fmt.Sprintf("excluded by pattern %s", pattern)
+		}
+	}
+	return false, ""
+}
+
 func (tg *ToolsetGroup) AddToolset(ts *Toolset) {
 	if tg.readOnly {
 		ts.SetReadOnly()
@@ -281,12 +373,23 @@ func (tg *ToolsetGroup) EnableToolset(name string) error {
 
 func (tg *ToolsetGroup) RegisterAll(s *mcp.Server) {
 	for _, toolset := range tg.Toolsets {
-		toolset.RegisterTools(s)
+		tg.RegisterToolsetTools(s, toolset)
 		toolset.RegisterResourcesTemplates(s)
 		toolset.RegisterPrompts(s)
 	}
 }
 
+// RegisterToolsetTools registers the active tools of the toolset, like Toolset.RegisterTools, except for
+// the tools excluded from the group. The toolset doesn't need to be in the group.
+func (tg *ToolsetGroup) RegisterToolsetTools(s *mcp.Server, toolset *Toolset) {
+	for _, tool := range toolset.GetActiveTools() {
+		if excluded, _ := tg.exclusionReason(tool.Tool.Name); excluded {
+			continue
+		}
+		tool.RegisterFunc(s)
+	}
+}
+
 func (tg *ToolsetGroup) GetToolset(name string) (*Toolset, error) {
 	toolset, exists := tg.Toolsets[name]
 	if !exists {
@@ -332,7 +435,17 @@ func (tg *ToolsetGroup) FindToolByName(toolName string) (*ServerTool, string, er
 // Returns error if any tool is not found.
 func (tg *ToolsetGroup) RegisterSpecificTools(s *mcp.Server, toolNames []string, readOnly bool) error {
 	var skippedTools []string
+	var removedTools []string
 	for _, toolName := range toolNames {
+		if reason, ok := tg.removedTools[toolName]; ok {
+			removedTools = append(removedTools, fmt.Sprintf("%s (%s)", toolName, reason))
+			continue
+		}
+		if excluded, reason := tg.exclusionReason(toolName); excluded {
+			removedTools = append(removedTools, fmt.Sprintf("%s (%s)", toolName, reason))
+			continue
+		}
+
 		tool, _, err := tg.FindToolByName(toolName)
 		if err != nil {
 			return fmt.Errorf("tool %s not found: %w", toolName, err)
@@ -352,6 +465,9 @@ func (tg *ToolsetGroup) RegisterSpecificTools(s *mcp.Server, toolNames []string,
 	if len(skippedTools) > 0 {
 		fmt.Fprintf(os.Stderr, "Write tools skipped due to read-only mode: %s\n", strings.Join(skippedTools, ", "))
 	}
+	if len(removedTools) > 0 {
+		fmt.Fprintf(os.Stderr, "Removed tools skipped: %s\n", strings.Join(removedTools, ", "))
+	}
 
 	return nil
 }
@@ -2,7 +2,10 @@ package toolsets
 
 import (
 	"errors"
+	"slices"
 	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func TestNewToolsetGroupIsEmptyWithoutEverythingOn(t *testing.T) {
@@ -262,3 +265,85 @@ func TestToolsetGroup_GetToolset(t *testing.T) {
 		t.Errorf("expected error to be ToolsetDoesNotExistError, got %v", err)
 	}
 }
+
+func TestToolsetGroup_RemoveTools(t *testing.T) {
+	readTool := ServerTool{Tool: mcp.Tool{Name: "read_tool", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}}
+	writeTool := ServerTool{
+		Tool:         mcp.Tool{Name: "write_tool", Annotations: &mcp.ToolAnnotations{}},
+		Requirements: TokenRequirements{Scopes: []string{"public_repo"}},
+	}
+
+	tsg := NewToolsetGroup(false)
+	toolset := NewToolset("my-toolset", "desc").AddReadTools(readTool).AddWriteTools(writeTool)
+	tsg.AddToolset(toolset)
+
+	removed := tsg.RemoveTools(func(tool ServerTool) (bool, string) {
+		return len(tool.Requirements.Scopes) > 0, "missing scope"
+	})
+	if len(removed) != 1 || removed["write_tool"] != "missing scope" {
+		t.Fatalf("expected write_tool to be removed, got %v", removed)
+	}
+
+	tools := toolset.GetAvailableTools()
+	if len(tools) != 1 || tools[0].Tool.Name != "read_tool" {
+		t.Errorf("expected only read_tool to remain, got %v", tools)
+	}
+
+	// Removed tools are skipped rather than reported as unknown when requested by name
+	if err := tsg.RegisterSpecificTools(mcp.NewServer(&mcp.Implementation{Name: "test"}, nil), []string{"write_tool"}, false); err != nil {
+		t.Errorf("expected removed tool to be skipped, got %v", err)
+	}
+}
+
+func TestToolsetGroup_ExcludeTools(t *testing.T) {
+	var registered []string
+	newTool := func(name string, readOnly bool) ServerTool {
+		return ServerTool{
+			Tool:         mcp.Tool{Name: name, Annotations: &mcp.ToolAnnotations{ReadOnlyHint: readOnly}},
+			RegisterFunc: func(*mcp.Server) { registered = append(registered, name) },
+		}
+	}
+
+	tsg := NewToolsetGroup(false)
+	tsg.AddToolset(NewToolset("files", "desc").
+		AddReadTools(newTool("get_file", true)).
+		AddWriteTools(newTool("create_file", false), newTool("delete_file", false)))
+
+	removed, err := tsg.ExcludeTools([]string{"delete_*", "create_file", "unknown_tool"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(removed) != 2 || removed["delete_file"] != "excluded by pattern delete_*" || removed["create_file"] != "excluded" {
+		t.Fatalf("expected delete_file and create_file to be removed, got %v", removed)
+	}
+
+	// A toolset added after the exclusion, as well as one enabled later outside of the group, still
+	// can't register excluded tools
+	tsg.AddToolset(NewToolset("runs", "desc").
+		AddReadTools(newTool("get_workflow_run", true)).
+		AddWriteTools(newTool("delete_workflow_run_logs", false)))
+	dynamic := NewToolset("dynamic", "desc").AddWriteTools(newTool("delete_everything", false))
+	dynamic.Enabled = true
+
+	s := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	if err := tsg.EnableToolsets([]string{"all"}, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	tsg.RegisterAll(s)
+	tsg.RegisterToolsetTools(s, dynamic)
+	if err := tsg.RegisterSpecificTools(s, []string{"delete_file", "delete_workflow_run_logs"}, false); err != nil {
+		t.Fatalf("expected excluded tools to be skipped, got %v", err)
+	}
+
+	slices.Sort(registered)
+	if want := []string{"get_file", "get_workflow_run"}; !slices.Equal(registered, want) {
+		t.Errorf("expected %v to be registered, got %v", want, registered)
+	}
+}
+
+func TestToolsetGroup_ExcludeToolsInvalidPattern(t *testing.T) {
+	tsg := NewToolsetGroup(false)
+	if _, err := tsg.ExcludeTools([]string{"delete_["}); err == nil {
+		t.Error("expected error for malformed pattern, got nil")
+	}
+}
@@ -0,0 +1,192 @@
+// Package tracing instruments the server with OpenTelemetry spans for MCP methods and the GitHub API
+// requests made while handling them.
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by the server.
+const instrumentationName = "github.com/github/github-mcp-server"
+
+// rateLimitHeaders are recorded on the span of every GitHub API request, by attribute name.
+var rateLimitHeaders = map[string]string{
+	"github.ratelimit.limit":     "X-RateLimit-Limit",
+	"github.ratelimit.remaining": "X-RateLimit-Remaining",
+	"github.ratelimit.used":      "X-RateLimit-Used",
+	"github.ratelimit.reset":     "X-RateLimit-Reset",
+	"github.ratelimit.resource":  "X-RateLimit-Resource",
+}
+
+// graphQLOperationPattern matches the start of a GraphQL document: the operation type and name, if any,
+// followed by the first field selected.
+var graphQLOperationPattern = regexp.MustCompile(`^\s*(query|mutation|subscription)?\s*([_A-Za-z][_0-9A-Za-z]*)?\s*(\([^)]*\))?\s*\{\s*([_A-Za-z][_0-9A-Za-z]*)`)
+
+// NewProvider creates a tracer provider exporting spans over OTLP/HTTP to endpointURL
+// (e.g. http://localhost:4318). Other exporter settings, such as headers, are read from the
+// standard OTEL_EXPORTER_OTLP_* environment variables. The provider must be shut down to flush
+// the remaining spans.
+func NewProvider(ctx context.Context, endpointURL, version string) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpointURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("github-mcp-server"),
+		semconv.ServiceVersion(version),
+	)
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+// Tracer returns the tracer for the server's spans.
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	return provider.Tracer(instrumentationName)
+}
+
+// Middleware returns receiving middleware that opens a span for every MCP method. GitHub API requests
+// made while handling the method get child spans when they are sent through a Transport.
+func Middleware(tracer trace.Tracer) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			name := method
+			attrs := []attribute.KeyValue{attribute.String("mcp.method.name", method)}
+			if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil {
+				name = method + " " + call.Params.Name
+				attrs = append(attrs, attribute.String("gen_ai.tool.name", call.Params.Name))
+			}
+			if session, ok := req.GetSession().(*mcp.ServerSession); ok && session != nil && session.ID() != "" {
+				attrs = append(attrs, attribute.String("mcp.session.id", session.ID()))
+			}
+
+			ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+			defer span.End()
+
+			result, err := next(ctx, method, req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult.IsError {
+				// Tool failures are reported in the result rather than as errors
+				span.SetStatus(codes.Error, "tool call failed")
+			}
+			return result, err
+		}
+	}
+}
+
+// Transport opens a client span for every request it sends, recording the response status, the
+// rate limit reported by GitHub and, for GraphQL requests, the operation.
+type Transport struct {
+	transport http.RoundTripper
+	tracer    trace.Tracer
+}
+
+// NewTransport creates a tracing transport sending requests through transport.
+func NewTransport(transport http.RoundTripper, tracer trace.Tracer) *Transport {
+	return &Transport{transport: transport, tracer: tracer}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := req.Method
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLFull(req.URL.Redacted()),
+		semconv.ServerAddress(req.URL.Hostname()),
+	}
+	if strings.HasSuffix(req.URL.Path, "/graphql") {
+		if operationType, operationName := graphQLOperation(req); operationType != "" {
+			name = operationType + " " + operationName
+			attrs = append(attrs,
+				semconv.GraphQLOperationTypeKey.String(operationType),
+				semconv.GraphQLOperationName(operationName),
+			)
+		}
+	}
+
+	ctx, span := t.tracer.Start(req.Context(), name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	defer span.End()
+
+	resp, err := t.transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	for attr, header := range rateLimitHeaders {
+		value := resp.Header.Get(header)
+		if value == "" {
+			continue
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			span.SetAttributes(attribute.Int64(attr, n))
+		} else {
+			span.SetAttributes(attribute.String(attr, value))
+		}
+	}
+	if requestID := resp.Header.Get("X-GitHub-Request-Id"); requestID != "" {
+		span.SetAttributes(attribute.String("github.request_id", requestID))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// graphQLOperation returns the type and name of the GraphQL operation in the request body. Anonymous
+// operations, which is what githubv4 sends, are named after the first field they select
+// (e.g. "repository").
+func graphQLOperation(req *http.Request) (string, string) {
+	if req.GetBody == nil {
+		return "", ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", ""
+	}
+	defer func() { _ = body.Close() }()
+
+	var payload struct {
+		Query         string `json:"query"`
+		OperationName string `json:"operationName"`
+	}
+	if err := json.NewDecoder(body).Decode(&payload); err != nil {
+		return "", ""
+	}
+
+	match := graphQLOperationPattern.FindStringSubmatch(payload.Query)
+	if match == nil {
+		return "", ""
+	}
+	operationType, operationName, firstField := match[1], match[2], match[4]
+	if operationType == "" {
+		operationType = "query"
+	}
+	if payload.OperationName != "" {
+		operationName = payload.OperationName
+	}
+	if operationName == "" {
+		operationName = firstField
+	}
+	return operationType, operationName
+}
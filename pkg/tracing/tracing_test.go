@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracer(t *testing.T) (trace.Tracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return Tracer(provider), exporter
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func Test_TraceTree(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Resource", "core")
+		w.Header().Set("X-GitHub-Request-Id", "ABCD:1234")
+		if r.URL.Path == "/graphql" {
+			_, _ = w.Write([]byte(`{"data":{}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	tracer, exporter := newTestTracer(t)
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport, tracer)}
+
+	// A tool handler making a REST and a GraphQL request
+	handler := func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/repos/owner/repo", nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, ts.URL+"/graphql",
+			strings.NewReader(`{"query":"query($owner:String!$name:String!){repository(owner:$owner, name:$name){id}}"}`))
+		require.NoError(t, err)
+		resp, err = client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		return &mcp.CallToolResult{IsError: true}, nil
+	}
+
+	_, err := Middleware(tracer)(handler)(context.Background(), "tools/call", &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "get_repository"},
+	})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	rest, graphql, method := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "tools/call get_repository", method.Name)
+	assert.Equal(t, trace.SpanKindServer, method.SpanKind)
+	assert.Equal(t, codes.Error, method.Status.Code)
+	assert.Equal(t, "get_repository", spanAttributes(method)["gen_ai.tool.name"].AsString())
+
+	for _, span := range []tracetest.SpanStub{rest, graphql} {
+		assert.Equal(t, method.SpanContext.TraceID(), span.SpanContext.TraceID())
+		assert.Equal(t, method.SpanContext.SpanID(), span.Parent.SpanID())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+	}
+
+	assert.Equal(t, "GET", rest.Name)
+	assert.Equal(t, codes.Error, rest.Status.Code)
+	attrs := spanAttributes(rest)
+	assert.Equal(t, int64(http.StatusNotFound), attrs["http.response.status_code"].AsInt64())
+	assert.Equal(t, int64(4999), attrs["github.ratelimit.remaining"].AsInt64())
+	assert.Equal(t, "core", attrs["github.ratelimit.resource"].AsString())
+	assert.Equal(t, "ABCD:1234", attrs["github.request_id"].AsString())
+
+	assert.Equal(t, "query repository", graphql.Name)
+	assert.Equal(t, codes.Unset, graphql.Status.Code)
+	attrs = spanAttributes(graphql)
+	assert.Equal(t, "query", attrs["graphql.operation.type"].AsString())
+	assert.Equal(t, "repository", attrs["graphql.operation.name"].AsString())
+}
+
+func Test_GraphQLOperation(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedType string
+		expectedName string
+	}{
+		{
+			name:         "anonymous query with variables",
+			body:         `{"query":"query($owner:String!){repositoryOwner(login:$owner){id}}"}`,
+			expectedType: "query",
+			expectedName: "repositoryOwner",
+		},
+		{
+			name:         "query shorthand",
+			body:         `{"query":"{viewer{login}}"}`,
+			expectedType: "query",
+			expectedName: "viewer",
+		},
+		{
+			name:         "named mutation",
+			body:         `{"query":"mutation AddComment($input:AddCommentInput!){addComment(input:$input){clientMutationId}}"}`,
+			expectedType: "mutation",
+			expectedName: "AddComment",
+		},
+		{
+			name:         "operation name in the payload",
+			body:         `{"query":"query A{viewer{login}} query B{viewer{id}}","operationName":"B"}`,
+			expectedType: "query",
+			expectedName: "B",
+		},
+		{
+			name: "not GraphQL",
+			body: `not json`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "https://api.github.com/graphql", strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			operationType, operationName := graphQLOperation(req)
+			assert.Equal(t, tc.expectedType, operationType)
+			assert.Equal(t, tc.expectedName, operationName)
+		})
+	}
+}
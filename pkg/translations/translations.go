@@ -17,7 +17,18 @@ func NullTranslationHelper(_ string, defaultValue string) string {
 }
 
 func TranslationHelper() (TranslationHelperFunc, func()) {
+	return TranslationHelperWithOverrides(nil)
+}
+
+// TranslationHelperWithOverrides is TranslationHelper with overrides by key, such as the description
+// overrides of the server config file. GITHUB_MCP_ environment variables win over them, and they win over
+// github-mcp-server-config.json.
+func TranslationHelperWithOverrides(overrides map[string]string) (TranslationHelperFunc, func()) {
 	var translationKeyMap = map[string]string{}
+	upperOverrides := make(map[string]string, len(overrides))
+	for key, value := range overrides {
+		upperOverrides[strings.ToUpper(key)] = value
+	}
 	v := viper.New()
 
 	// Load from JSON file
@@ -44,6 +55,10 @@ func TranslationHelper() (TranslationHelperFunc, func()) {
 				translationKeyMap[key] = value
 				return value
 			}
+			if value, exists := upperOverrides[key]; exists {
+				translationKeyMap[key] = value
+				return value
+			}
 
 			v.SetDefault(key, defaultValue)
 			translationKeyMap[key] = v.GetString(key)